@@ -0,0 +1,91 @@
+package background
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"mensajesService/components/logger"
+)
+
+type task struct {
+	name     string
+	interval time.Duration
+	fn       func(ctx context.Context) error
+}
+
+// Scheduler runs periodic work (trend refreshes, ...) on a Tracker, so a
+// shutdown waits for a run in progress instead of cutting it short.
+type Scheduler struct {
+	tracker *Tracker
+	tasks   []task
+	stop    chan struct{}
+
+	mu      sync.Mutex
+	started bool
+	closed  bool
+}
+
+func NewScheduler(tracker *Tracker) *Scheduler {
+	return &Scheduler{
+		tracker: tracker,
+		stop:    make(chan struct{}),
+	}
+}
+
+// Every registers fn to run once at Start and then every interval. A failed
+// run is logged and the next one happens on schedule. Every must be called
+// before Start.
+func (s *Scheduler) Every(name string, interval time.Duration, fn func(ctx context.Context) error) {
+	s.tasks = append(s.tasks, task{name: name, interval: interval, fn: fn})
+}
+
+// Start launches one loop per task on the tracker.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.started || s.closed {
+		return
+	}
+	s.started = true
+
+	for _, t := range s.tasks {
+		t := t
+		s.tracker.Go("scheduled_task", func(ctx context.Context) error {
+			return s.loop(ctx, t)
+		}, "task", t.name)
+	}
+}
+
+// Stop ends every loop after its current run.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	close(s.stop)
+}
+
+func (s *Scheduler) loop(ctx context.Context, t task) error {
+	if t.interval <= 0 {
+		logger.LogError("Scheduled task disabled, interval must be positive", "task", t.name, "interval", t.interval.String())
+		return nil
+	}
+
+	ticker := time.NewTicker(t.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.fn(ctx); err != nil {
+			logger.LogError("Scheduled task failed", "task", t.name, "error", err)
+		}
+
+		select {
+		case <-s.stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package background
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScheduler_RunsAtStartAndEveryInterval(t *testing.T) {
+	tracker := NewTracker()
+	scheduler := NewScheduler(tracker)
+
+	var runs atomic.Int32
+	scheduler.Every("count", 10*time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return errors.New("failures don't stop the schedule")
+	})
+	scheduler.Start()
+
+	assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, 5*time.Millisecond)
+
+	scheduler.Stop()
+	assert.NoError(t, tracker.Shutdown(context.Background()))
+	stopped := runs.Load()
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, stopped, runs.Load())
+}
+
+func TestScheduler_StopBeforeStart(t *testing.T) {
+	tracker := NewTracker()
+	scheduler := NewScheduler(tracker)
+
+	var runs atomic.Int32
+	scheduler.Every("never", time.Millisecond, func(ctx context.Context) error {
+		runs.Add(1)
+		return nil
+	})
+	scheduler.Stop()
+	scheduler.Start()
+
+	assert.NoError(t, tracker.Shutdown(context.Background()))
+	assert.Zero(t, runs.Load())
+}
@@ -0,0 +1,106 @@
+package background
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"mensajesService/components/logger"
+)
+
+type job struct {
+	name    string
+	keyvals []interface{}
+	started time.Time
+}
+
+// Tracker runs fire-and-forget work (timeline fan-out, backfills...) outside
+// the request lifecycle and keeps track of it, so a shutdown can wait for the
+// jobs to finish instead of silently dropping them.
+type Tracker struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	ctx     context.Context
+	cancel  context.CancelFunc
+	closed  bool
+	nextID  uint64
+	running map[uint64]job
+}
+
+func NewTracker() *Tracker {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Tracker{
+		ctx:     ctx,
+		cancel:  cancel,
+		running: map[uint64]job{},
+	}
+}
+
+// Go runs fn in its own goroutine. The context it receives is only cancelled
+// when a shutdown deadline expires. keyvals are logged with failures and with
+// jobs abandoned at shutdown.
+func (t *Tracker) Go(name string, fn func(ctx context.Context) error, keyvals ...interface{}) {
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		logger.LogError("Background job abandoned, shutting down", append([]interface{}{"job", name}, keyvals...)...)
+		return
+	}
+	id := t.nextID
+	t.nextID++
+	t.running[id] = job{name: name, keyvals: keyvals, started: time.Now()}
+	t.wg.Add(1)
+	t.mu.Unlock()
+
+	go func() {
+		defer func() {
+			t.mu.Lock()
+			delete(t.running, id)
+			t.mu.Unlock()
+			t.wg.Done()
+		}()
+
+		if err := fn(t.ctx); err != nil {
+			logger.LogError("Background job failed", append([]interface{}{"job", name, "error", err}, keyvals...)...)
+		}
+	}()
+}
+
+// Shutdown stops accepting jobs and waits for the running ones. When ctx
+// expires first, the remaining jobs are cancelled and logged as abandoned.
+func (t *Tracker) Shutdown(ctx context.Context) error {
+	t.mu.Lock()
+	t.closed = true
+	pending := len(t.running)
+	t.mu.Unlock()
+	logger.LogInfo("Waiting for background jobs", "pending", pending)
+
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.cancel()
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	for _, abandoned := range t.running {
+		logger.LogError("Background job abandoned, shutdown deadline exceeded",
+			append([]interface{}{"job", abandoned.name, "running_ms", time.Since(abandoned.started).Milliseconds()}, abandoned.keyvals...)...)
+	}
+	t.mu.Unlock()
+	t.cancel()
+	return ctx.Err()
+}
+
+// Pending returns the number of jobs currently running.
+func (t *Tracker) Pending() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.running)
+}
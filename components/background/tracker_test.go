@@ -0,0 +1,71 @@
+package background
+
+import (
+	"context"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mensajesService/components/logger"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+func TestTracker_ShutdownWaitsForJobs(t *testing.T) {
+	tracker := NewTracker()
+
+	var finished atomic.Bool
+	tracker.Go("slow", func(ctx context.Context) error {
+		time.Sleep(50 * time.Millisecond)
+		finished.Store(true)
+		return nil
+	})
+
+	err := tracker.Shutdown(context.Background())
+
+	assert.NoError(t, err)
+	assert.True(t, finished.Load())
+	assert.Equal(t, 0, tracker.Pending())
+}
+
+func TestTracker_ShutdownDeadlineCancelsJobs(t *testing.T) {
+	tracker := NewTracker()
+
+	cancelled := make(chan struct{})
+	tracker.Go("stuck", func(ctx context.Context) error {
+		<-ctx.Done()
+		close(cancelled)
+		return ctx.Err()
+	}, "message_id", "msg1")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	err := tracker.Shutdown(ctx)
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("job context was not cancelled")
+	}
+}
+
+func TestTracker_RejectsJobsAfterShutdown(t *testing.T) {
+	tracker := NewTracker()
+	assert.NoError(t, tracker.Shutdown(context.Background()))
+
+	var ran atomic.Bool
+	tracker.Go("late", func(ctx context.Context) error {
+		ran.Store(true)
+		return nil
+	})
+
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, ran.Load())
+}
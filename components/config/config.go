@@ -1,40 +1,227 @@
 package config
 
 import (
+	"errors"
+	"fmt"
 	"os"
 	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	StorageBackendDynamoDB = "dynamodb"
+	StorageBackendMemory   = "memory"
+)
+
+// DefaultMetricsFlushInterval and DefaultMetricsBufferSize replace a
+// METRICS_FLUSH_INTERVAL or METRICS_BUFFER_SIZE that doesn't parse or isn't
+// positive.
+const (
+	DefaultMetricsFlushInterval = 60 * time.Second
+	DefaultMetricsBufferSize    = 10000
+)
+
+// DefaultShutdownTimeout replaces a SHUTDOWN_TIMEOUT that doesn't parse or
+// isn't positive, which would leave no time to drain background jobs.
+const DefaultShutdownTimeout = 30 * time.Second
+
+// DefaultTrendRefreshInterval replaces a TRENDS_REFRESH_INTERVAL that doesn't
+// parse or isn't positive, which would stop trends from ever refreshing.
+const DefaultTrendRefreshInterval = time.Minute
+
+const (
+	AuthModeHeader = "header"
+	AuthModeJWT    = "jwt"
+)
+
+// Reply fan-out policies: which timelines a reply reaches besides the
+// replier's own followers.
+const (
+	ReplyFanoutFollowers = "followers" // only the replier's followers
+	ReplyFanoutMutual    = "mutual"    // only those who also follow the replied-to author
+	ReplyFanoutAll       = "all"       // plus every follower of the replied-to author
+)
+
+const (
+	MetricsSinkCloudWatch = "cloudwatch"
+	MetricsSinkPrometheus = "prometheus"
+	MetricsSinkNone       = "none"
 )
 
 type AppConfig struct {
-	Env                 string
-	Port                string
-	TableMensajesName   string
-	TableSeguidoresName string
-	TableTimelineName   string
-	Region              string
-	BaseURL             string
-	DefaultLimit        int
-	MaxMessageLength    int
+	Env                      string
+	Port                     string
+	TableMensajesName        string
+	TableSeguidoresName      string
+	TableTimelineName        string
+	TableUserStatsName       string
+	TableJobsName            string
+	TableDeadLettersName     string
+	TableHeavyAuthorsName    string
+	TableRepostsName         string
+	TableLikesName           string
+	TableMentionsName        string
+	TableHashtagsName        string
+	TableHashtagCountsName   string
+	TableBlocksName          string
+	TableMutesName           string
+	TableAccountSettingsName string
+	TableFollowRequestsName  string
+	TableUsersName           string
+	TableHandlesName         string
+	Region                   string
+	StorageBackend           string
+	BaseURL                  string
+	DefaultLimit             int
+	MaxLimit                 int
+	MaxMessageLength         int
+	CursorSecret             string
+	AuthMode                 string
+	JWTSecret                string
+	JWTPublicKey             string
+	JWTJWKSFile              string
+	JWTIssuer                string
+	JWTAudience              string
+	MetricsSink              string
+	MetricsFlushInterval     time.Duration
+	MetricsBufferSize        int
+	ShutdownTimeout          time.Duration
+	FanoutWorkers            int
+	FanoutMaxAttempts        int
+	FanoutBackoffBase        time.Duration
+	FanoutBackoffMax         time.Duration
+	FanoutPollInterval       time.Duration
+	FanoutLease              time.Duration
+	BatchWriteConcurrency    int
+	HeavyAuthorThreshold     int
+	BackfillMaxMessages      int
+	BackfillWindow           time.Duration
+	ReplyFanoutPolicy        string
+	ThreadMaxDepth           int
+	TrendWindows             []time.Duration
+	TrendBucket              time.Duration
+	TrendRefreshInterval     time.Duration
+	TrendTopN                int
+
+	// invalid collects the settings that didn't parse and have no safe
+	// default, for Validate to report.
+	invalid []error
 }
 
 func LoadConfig() *AppConfig {
 	defaultLimit, _ := strconv.Atoi(getEnv("DEFAULT_LIMIT", "20"))
+	maxLimit, _ := strconv.Atoi(getEnv("MAX_LIMIT", "100"))
 	maxMessageLength, _ := strconv.Atoi(getEnv("MAX_MESSAGE_LENGTH", "280"))
+	metricsFlushInterval, err := time.ParseDuration(getEnv("METRICS_FLUSH_INTERVAL", "60s"))
+	if err != nil || metricsFlushInterval <= 0 {
+		metricsFlushInterval = DefaultMetricsFlushInterval
+	}
+	metricsBufferSize, err := strconv.Atoi(getEnv("METRICS_BUFFER_SIZE", "10000"))
+	if err != nil || metricsBufferSize <= 0 {
+		metricsBufferSize = DefaultMetricsBufferSize
+	}
+	shutdownTimeout, err := time.ParseDuration(getEnv("SHUTDOWN_TIMEOUT", "30s"))
+	if err != nil || shutdownTimeout <= 0 {
+		shutdownTimeout = DefaultShutdownTimeout
+	}
+	fanoutWorkers, _ := strconv.Atoi(getEnv("FANOUT_WORKERS", "4"))
+	fanoutMaxAttempts, _ := strconv.Atoi(getEnv("FANOUT_MAX_ATTEMPTS", "5"))
+	fanoutBackoffBase, _ := time.ParseDuration(getEnv("FANOUT_BACKOFF_BASE", "1s"))
+	fanoutBackoffMax, _ := time.ParseDuration(getEnv("FANOUT_BACKOFF_MAX", "5m"))
+	fanoutPollInterval, _ := time.ParseDuration(getEnv("FANOUT_POLL_INTERVAL", "1s"))
+	fanoutLease, _ := time.ParseDuration(getEnv("FANOUT_LEASE", "5m"))
+	batchWriteConcurrency, _ := strconv.Atoi(getEnv("BATCH_WRITE_CONCURRENCY", "4"))
+	heavyAuthorThreshold, _ := strconv.Atoi(getEnv("FANOUT_HEAVY_THRESHOLD", "10000"))
+	backfillMaxMessages, _ := strconv.Atoi(getEnv("BACKFILL_MAX_MESSAGES", "1000"))
+	// A typo here must not fall back to 0, which would lift the limit.
+	var invalid []error
+	backfillWindow, err := time.ParseDuration(getEnv("BACKFILL_WINDOW", "0"))
+	if err != nil || backfillWindow < 0 {
+		invalid = append(invalid, fmt.Errorf("BACKFILL_WINDOW %q is not a duration of 0 or more", os.Getenv("BACKFILL_WINDOW")))
+	}
+	threadMaxDepth, _ := strconv.Atoi(getEnv("THREAD_MAX_DEPTH", "10"))
+	trendBucket, _ := time.ParseDuration(getEnv("TRENDS_BUCKET", "5m"))
+	trendRefreshInterval, err := time.ParseDuration(getEnv("TRENDS_REFRESH_INTERVAL", "1m"))
+	if err != nil || trendRefreshInterval <= 0 {
+		trendRefreshInterval = DefaultTrendRefreshInterval
+	}
+	trendTopN, _ := strconv.Atoi(getEnv("TRENDS_TOP_N", "10"))
 
 	cfg := &AppConfig{
-		Env:                 getEnv("ENV", "dev"),
-		Port:                getEnv("PORT", "80"),
-		TableMensajesName:   getEnv("DDB_TABLE_MENSAJES", "messages"),
-		TableSeguidoresName: getEnv("DDB_TABLE_SEGUIDORES", "follows"),
-		TableTimelineName:   getEnv("DDB_TABLE_TIMELINE", "timeline"),
-		Region:              getEnv("AWS_REGION", "us-east-1"),
-		BaseURL:             getEnv("BASE_URL", "http://localhost:8080/"),
-		DefaultLimit:        defaultLimit,
-		MaxMessageLength:    maxMessageLength,
+		Env:                      getEnv("ENV", "dev"),
+		Port:                     getEnv("PORT", "80"),
+		TableMensajesName:        getEnv("DDB_TABLE_MENSAJES", "messages"),
+		TableSeguidoresName:      getEnv("DDB_TABLE_SEGUIDORES", "follows"),
+		TableTimelineName:        getEnv("DDB_TABLE_TIMELINE", "timeline"),
+		TableUserStatsName:       getEnv("DDB_TABLE_USER_STATS", "user_stats"),
+		TableJobsName:            getEnv("DDB_TABLE_JOBS", "fanout_jobs"),
+		TableDeadLettersName:     getEnv("DDB_TABLE_DEAD_LETTERS", "fanout_dead_letters"),
+		TableHeavyAuthorsName:    getEnv("DDB_TABLE_HEAVY_AUTHORS", "heavy_authors"),
+		TableRepostsName:         getEnv("DDB_TABLE_REPOSTS", "reposts"),
+		TableLikesName:           getEnv("DDB_TABLE_LIKES", "likes"),
+		TableMentionsName:        getEnv("DDB_TABLE_MENTIONS", "mentions"),
+		TableHashtagsName:        getEnv("DDB_TABLE_HASHTAGS", "hashtags"),
+		TableHashtagCountsName:   getEnv("DDB_TABLE_HASHTAG_COUNTS", "hashtag_counts"),
+		TableBlocksName:          getEnv("DDB_TABLE_BLOCKS", "blocks"),
+		TableMutesName:           getEnv("DDB_TABLE_MUTES", "mutes"),
+		TableAccountSettingsName: getEnv("DDB_TABLE_ACCOUNT_SETTINGS", "account_settings"),
+		TableFollowRequestsName:  getEnv("DDB_TABLE_FOLLOW_REQUESTS", "follow_requests"),
+		TableUsersName:           getEnv("DDB_TABLE_USERS", "users"),
+		TableHandlesName:         getEnv("DDB_TABLE_HANDLES", "handles"),
+		Region:                   getEnv("AWS_REGION", "us-east-1"),
+		StorageBackend:           getEnv("STORAGE_BACKEND", StorageBackendDynamoDB),
+		BaseURL:                  getEnv("BASE_URL", "http://localhost:8080/"),
+		DefaultLimit:             defaultLimit,
+		MaxLimit:                 maxLimit,
+		MaxMessageLength:         maxMessageLength,
+		CursorSecret:             getEnv("CURSOR_SECRET", ""),
+		AuthMode:                 getEnv("AUTH_MODE", AuthModeHeader),
+		JWTSecret:                getEnv("JWT_SECRET", ""),
+		JWTPublicKey:             getEnv("JWT_PUBLIC_KEY", ""),
+		JWTJWKSFile:              getEnv("JWT_JWKS_FILE", ""),
+		JWTIssuer:                getEnv("JWT_ISSUER", ""),
+		JWTAudience:              getEnv("JWT_AUDIENCE", ""),
+		MetricsSink:              getEnv("METRICS_SINK", MetricsSinkCloudWatch),
+		MetricsFlushInterval:     metricsFlushInterval,
+		MetricsBufferSize:        metricsBufferSize,
+		ShutdownTimeout:          shutdownTimeout,
+		FanoutWorkers:            fanoutWorkers,
+		FanoutMaxAttempts:        fanoutMaxAttempts,
+		FanoutBackoffBase:        fanoutBackoffBase,
+		FanoutBackoffMax:         fanoutBackoffMax,
+		FanoutPollInterval:       fanoutPollInterval,
+		FanoutLease:              fanoutLease,
+		BatchWriteConcurrency:    batchWriteConcurrency,
+		HeavyAuthorThreshold:     heavyAuthorThreshold,
+		BackfillMaxMessages:      backfillMaxMessages,
+		BackfillWindow:           backfillWindow,
+		ReplyFanoutPolicy:        getEnv("REPLY_FANOUT_POLICY", ReplyFanoutAll),
+		ThreadMaxDepth:           threadMaxDepth,
+		TrendWindows:             parseDurations(getEnv("TRENDS_WINDOWS", "1h,24h")),
+		TrendBucket:              trendBucket,
+		TrendRefreshInterval:     trendRefreshInterval,
+		TrendTopN:                trendTopN,
+		invalid:                  invalid,
 	}
 	return cfg
 }
 
+// Validate reports settings that can't be replaced by a default and would
+// leave a feature silently broken.
+func (c *AppConfig) Validate() error {
+	errs := c.invalid
+	if len(c.TrendWindows) == 0 {
+		errs = append(errs, errors.New("TRENDS_WINDOWS has no positive duration"))
+	}
+	// Without a shared secret every instance signs cursors with its own random
+	// key, so they fail on any other instance and after a restart.
+	if c.CursorSecret == "" && c.StorageBackend != StorageBackendMemory {
+		errs = append(errs, errors.New("CURSOR_SECRET is required unless STORAGE_BACKEND is memory"))
+	}
+	return errors.Join(errs...)
+}
+
 func getEnv(key, defaultVal string) string {
 	val := os.Getenv(key)
 	if val == "" {
@@ -42,3 +229,16 @@ func getEnv(key, defaultVal string) string {
 	}
 	return val
 }
+
+// parseDurations reads a comma-separated list of durations, skipping the
+// entries that don't parse or aren't positive.
+func parseDurations(list string) []time.Duration {
+	var durations []time.Duration
+	for _, entry := range strings.Split(list, ",") {
+		duration, err := time.ParseDuration(strings.TrimSpace(entry))
+		if err == nil && duration > 0 {
+			durations = append(durations, duration)
+		}
+	}
+	return durations
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -15,6 +16,7 @@ func TestLoadConfig(t *testing.T) {
 	os.Setenv("DDB_TABLE_TIMELINE", "test-timeline-table")
 	os.Setenv("MAX_MESSAGE_LENGTH", "280")
 	os.Setenv("DEFAULT_LIMIT", "20")
+	os.Setenv("MAX_LIMIT", "50")
 
 	defer func() {
 		os.Unsetenv("AWS_REGION")
@@ -24,6 +26,7 @@ func TestLoadConfig(t *testing.T) {
 		os.Unsetenv("DDB_TABLE_TIMELINE")
 		os.Unsetenv("MAX_MESSAGE_LENGTH")
 		os.Unsetenv("DEFAULT_LIMIT")
+		os.Unsetenv("MAX_LIMIT")
 	}()
 
 	config := LoadConfig()
@@ -36,6 +39,7 @@ func TestLoadConfig(t *testing.T) {
 	assert.Equal(t, "test-timeline-table", config.TableTimelineName)
 	assert.Equal(t, 280, config.MaxMessageLength)
 	assert.Equal(t, 20, config.DefaultLimit)
+	assert.Equal(t, 50, config.MaxLimit)
 }
 
 func TestLoadConfig_Defaults(t *testing.T) {
@@ -46,6 +50,7 @@ func TestLoadConfig_Defaults(t *testing.T) {
 	os.Unsetenv("DDB_TABLE_TIMELINE")
 	os.Unsetenv("MAX_MESSAGE_LENGTH")
 	os.Unsetenv("DEFAULT_LIMIT")
+	os.Unsetenv("MAX_LIMIT")
 
 	config := LoadConfig()
 
@@ -54,4 +59,81 @@ func TestLoadConfig_Defaults(t *testing.T) {
 	assert.Equal(t, "80", config.Port)
 	assert.Equal(t, 280, config.MaxMessageLength)
 	assert.Equal(t, 20, config.DefaultLimit)
+	assert.Equal(t, 100, config.MaxLimit)
+	assert.Equal(t, StorageBackendDynamoDB, config.StorageBackend)
+	assert.Equal(t, "fanout_jobs", config.TableJobsName)
+	assert.Equal(t, 4, config.FanoutWorkers)
+	assert.Equal(t, 5, config.FanoutMaxAttempts)
+	assert.Equal(t, ReplyFanoutAll, config.ReplyFanoutPolicy)
+	assert.Equal(t, 10, config.ThreadMaxDepth)
+	assert.Equal(t, []time.Duration{time.Hour, 24 * time.Hour}, config.TrendWindows)
+	assert.Equal(t, time.Minute, config.TrendRefreshInterval)
+}
+
+func TestLoadConfig_TrendWindows(t *testing.T) {
+	t.Setenv("TRENDS_WINDOWS", "15m, bogus,2h,-1h")
+
+	config := LoadConfig()
+
+	assert.Equal(t, []time.Duration{15 * time.Minute, 2 * time.Hour}, config.TrendWindows)
+}
+
+func TestLoadConfig_MetricsFlushInterval(t *testing.T) {
+	for _, value := range []string{"0", "-5s", "bogus"} {
+		t.Setenv("METRICS_FLUSH_INTERVAL", value)
+		assert.Equal(t, DefaultMetricsFlushInterval, LoadConfig().MetricsFlushInterval, value)
+	}
+
+	t.Setenv("METRICS_FLUSH_INTERVAL", "15s")
+	assert.Equal(t, 15*time.Second, LoadConfig().MetricsFlushInterval)
+
+	t.Setenv("METRICS_BUFFER_SIZE", "-1")
+	assert.Equal(t, DefaultMetricsBufferSize, LoadConfig().MetricsBufferSize)
+}
+
+func TestLoadConfig_ShutdownTimeout(t *testing.T) {
+	for _, value := range []string{"0", "-5s", "bogus"} {
+		t.Setenv("SHUTDOWN_TIMEOUT", value)
+		assert.Equal(t, DefaultShutdownTimeout, LoadConfig().ShutdownTimeout, value)
+	}
+
+	t.Setenv("SHUTDOWN_TIMEOUT", "10s")
+	assert.Equal(t, 10*time.Second, LoadConfig().ShutdownTimeout)
+}
+
+func TestLoadConfig_TrendRefreshInterval(t *testing.T) {
+	for _, value := range []string{"0", "-5s", "bogus"} {
+		t.Setenv("TRENDS_REFRESH_INTERVAL", value)
+		assert.Equal(t, DefaultTrendRefreshInterval, LoadConfig().TrendRefreshInterval, value)
+	}
+
+	t.Setenv("TRENDS_REFRESH_INTERVAL", "30s")
+	assert.Equal(t, 30*time.Second, LoadConfig().TrendRefreshInterval)
+}
+
+func TestValidate_TrendWindows(t *testing.T) {
+	t.Setenv("CURSOR_SECRET", "secret")
+	assert.NoError(t, LoadConfig().Validate())
+
+	t.Setenv("TRENDS_WINDOWS", "bogus,0")
+	assert.Error(t, LoadConfig().Validate())
+}
+
+func TestValidate_BackfillWindow(t *testing.T) {
+	t.Setenv("CURSOR_SECRET", "secret")
+	t.Setenv("BACKFILL_WINDOW", "720h")
+	assert.NoError(t, LoadConfig().Validate())
+
+	for _, value := range []string{"30 days", "-1h"} {
+		t.Setenv("BACKFILL_WINDOW", value)
+		assert.Error(t, LoadConfig().Validate(), value)
+	}
+}
+
+func TestValidate_CursorSecret(t *testing.T) {
+	t.Setenv("CURSOR_SECRET", "")
+	assert.Error(t, LoadConfig().Validate())
+
+	t.Setenv("STORAGE_BACKEND", StorageBackendMemory)
+	assert.NoError(t, LoadConfig().Validate())
 }
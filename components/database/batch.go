@@ -0,0 +1,192 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxBatchWriteItems is the BatchWriteItem limit per request.
+const maxBatchWriteItems = 25
+
+const (
+	maxBatchAttempts    = 8
+	batchRetryBaseDelay = 50 * time.Millisecond
+	batchRetryMaxDelay  = 2 * time.Second
+)
+
+type batchWriteFunc func(ctx context.Context, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error)
+
+// batchWrite splits requests into BatchWriteItem calls of up to 25 items and
+// runs at most concurrency of them at a time. UnprocessedItems are resent with
+// exponential backoff; the first chunk that still fails aborts the rest.
+func batchWrite(ctx context.Context, tableName string, requests []types.WriteRequest, concurrency int, write batchWriteFunc) error {
+	if concurrency <= 0 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+		slots    = make(chan struct{}, concurrency)
+	)
+
+	for start := 0; start < len(requests); start += maxBatchWriteItems {
+		end := start + maxBatchWriteItems
+		if end > len(requests) {
+			end = len(requests)
+		}
+		chunk := requests[start:end]
+
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+
+		wg.Add(1)
+		go func() {
+			defer func() {
+				<-slots
+				wg.Done()
+			}()
+			if err := writeChunk(ctx, tableName, chunk, write); err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+	if firstErr != nil {
+		return firstErr
+	}
+	return ctx.Err()
+}
+
+func writeChunk(ctx context.Context, tableName string, chunk []types.WriteRequest, write batchWriteFunc) error {
+	pending := map[string][]types.WriteRequest{tableName: chunk}
+	delay := batchRetryBaseDelay
+
+	for attempt := 1; ; attempt++ {
+		output, err := write(ctx, &dynamodb.BatchWriteItemInput{RequestItems: pending})
+		if err != nil {
+			return err
+		}
+		if len(output.UnprocessedItems[tableName]) == 0 {
+			return nil
+		}
+		pending = output.UnprocessedItems
+
+		if attempt == maxBatchAttempts {
+			return fmt.Errorf("%d items still unprocessed in %s after %d attempts", len(pending[tableName]), tableName, attempt)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		delay *= 2
+		if delay > batchRetryMaxDelay {
+			delay = batchRetryMaxDelay
+		}
+	}
+}
+
+// maxBatchGetItems is the BatchGetItem limit per request.
+const maxBatchGetItems = 100
+
+type batchGetFunc func(ctx context.Context, input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error)
+
+// batchGet reads keys with BatchGetItem calls of up to 100 keys, one at a
+// time, resending UnprocessedKeys with exponential backoff. Duplicate keys,
+// which DynamoDB rejects, are read once. Items come back in no particular
+// order, and keys without an item are left out.
+func batchGet(ctx context.Context, tableName string, keys []map[string]types.AttributeValue, get batchGetFunc) ([]map[string]types.AttributeValue, error) {
+	unique := make([]map[string]types.AttributeValue, 0, len(keys))
+	seen := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		id := keyID(key)
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, key)
+		}
+	}
+
+	var items []map[string]types.AttributeValue
+	for start := 0; start < len(unique); start += maxBatchGetItems {
+		end := start + maxBatchGetItems
+		if end > len(unique) {
+			end = len(unique)
+		}
+		chunkItems, err := getChunk(ctx, tableName, unique[start:end], get)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, chunkItems...)
+	}
+	return items, nil
+}
+
+func getChunk(ctx context.Context, tableName string, chunk []map[string]types.AttributeValue, get batchGetFunc) ([]map[string]types.AttributeValue, error) {
+	pending := map[string]types.KeysAndAttributes{tableName: {Keys: chunk}}
+	delay := batchRetryBaseDelay
+
+	var items []map[string]types.AttributeValue
+	for attempt := 1; ; attempt++ {
+		output, err := get(ctx, &dynamodb.BatchGetItemInput{RequestItems: pending})
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, output.Responses[tableName]...)
+		if len(output.UnprocessedKeys[tableName].Keys) == 0 {
+			return items, nil
+		}
+		pending = output.UnprocessedKeys
+
+		if attempt == maxBatchAttempts {
+			return nil, fmt.Errorf("%d keys still unprocessed in %s after %d attempts", len(pending[tableName].Keys), tableName, attempt)
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		delay *= 2
+		if delay > batchRetryMaxDelay {
+			delay = batchRetryMaxDelay
+		}
+	}
+}
+
+// keyID identifies a key regardless of the order of its attributes.
+func keyID(key map[string]types.AttributeValue) string {
+	names := make([]string, 0, len(key))
+	for name := range key {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var id strings.Builder
+	for _, name := range names {
+		value, _ := keyString(key[name])
+		id.WriteString(name + "\x00" + value + "\x00")
+	}
+	return id.String()
+}
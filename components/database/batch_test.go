@@ -0,0 +1,177 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func putRequests(n int) []types.WriteRequest {
+	requests := make([]types.WriteRequest, n)
+	for i := range requests {
+		requests[i] = types.WriteRequest{PutRequest: &types.PutRequest{Item: timelineRow("user1", i)}}
+	}
+	return requests
+}
+
+func TestBatchWrite_ChunksByTwentyFive(t *testing.T) {
+	var mu sync.Mutex
+	var sizes []int
+
+	err := batchWrite(context.Background(), "timeline", putRequests(60), 1, func(ctx context.Context, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		mu.Lock()
+		sizes = append(sizes, len(input.RequestItems["timeline"]))
+		mu.Unlock()
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{25, 25, 10}, sizes)
+}
+
+func TestBatchWrite_RetriesUnprocessedItems(t *testing.T) {
+	var calls atomic.Int32
+
+	err := batchWrite(context.Background(), "timeline", putRequests(5), 1, func(ctx context.Context, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		if calls.Add(1) == 1 {
+			return &dynamodb.BatchWriteItemOutput{
+				UnprocessedItems: map[string][]types.WriteRequest{"timeline": input.RequestItems["timeline"][3:]},
+			}, nil
+		}
+		assert.Len(t, input.RequestItems["timeline"], 2)
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestBatchWrite_StopsRetryingWhenCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls atomic.Int32
+
+	err := batchWrite(ctx, "timeline", putRequests(3), 1, func(ctx context.Context, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		if calls.Add(1) == 2 {
+			cancel()
+		}
+		return &dynamodb.BatchWriteItemOutput{UnprocessedItems: input.RequestItems}, nil
+	})
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, int32(2), calls.Load())
+}
+
+func TestBatchWrite_BoundsConcurrencyAndStopsOnError(t *testing.T) {
+	var running, peak atomic.Int32
+	failure := errors.New("throttled")
+
+	err := batchWrite(context.Background(), "timeline", putRequests(250), 3, func(ctx context.Context, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		now := running.Add(1)
+		defer running.Add(-1)
+		for {
+			old := peak.Load()
+			if now <= old || peak.CompareAndSwap(old, now) {
+				break
+			}
+		}
+		if input.RequestItems["timeline"][0].PutRequest.Item["timestamp"].(*types.AttributeValueMemberN).Value == "100" {
+			return nil, failure
+		}
+		return &dynamodb.BatchWriteItemOutput{}, nil
+	})
+
+	assert.ErrorIs(t, err, failure)
+	assert.LessOrEqual(t, peak.Load(), int32(3))
+}
+
+func TestMemoryClient_BatchWriteItem(t *testing.T) {
+	client := newTestMemoryClient()
+	ctx := context.Background()
+
+	require.NoError(t, client.BatchWriteItem(ctx, "timeline", putRequests(30)))
+	require.NoError(t, client.BatchWriteItem(ctx, "timeline", []types.WriteRequest{
+		{DeleteRequest: &types.DeleteRequest{Key: map[string]types.AttributeValue{
+			"user_id":   &types.AttributeValueMemberS{Value: "user1"},
+			"timestamp": &types.AttributeValueMemberN{Value: "0"},
+		}}},
+	}))
+
+	output, err := client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String("timeline"),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: "user1"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Len(t, output.Items, 29)
+}
+
+func TestMemoryClient_BatchWriteItemRejectsDuplicateKeys(t *testing.T) {
+	client := newTestMemoryClient()
+	ctx := context.Background()
+
+	requests := append(putRequests(2), types.WriteRequest{PutRequest: &types.PutRequest{Item: timelineRow("user1", 1)}})
+	assert.Error(t, client.BatchWriteItem(ctx, "timeline", requests))
+
+	output, err := client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String("timeline"),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: "user1"},
+		},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, output.Items, "a rejected batch writes nothing")
+}
+
+func timelineKeys(n int) []map[string]types.AttributeValue {
+	keys := make([]map[string]types.AttributeValue, n)
+	for i := range keys {
+		keys[i] = map[string]types.AttributeValue{
+			"user_id":   &types.AttributeValueMemberS{Value: "user1"},
+			"timestamp": &types.AttributeValueMemberN{Value: fmt.Sprint(i)},
+		}
+	}
+	return keys
+}
+
+func TestBatchGet_ChunksByHundredAndRetriesUnprocessedKeys(t *testing.T) {
+	var sizes []int
+
+	items, err := batchGet(context.Background(), "timeline", timelineKeys(150), func(ctx context.Context, input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+		keys := input.RequestItems["timeline"].Keys
+		sizes = append(sizes, len(keys))
+		output := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]types.AttributeValue{"timeline": keys}}
+		if len(keys) == 50 {
+			output.Responses["timeline"] = keys[:40]
+			output.UnprocessedKeys = map[string]types.KeysAndAttributes{"timeline": {Keys: keys[40:]}}
+		}
+		return output, nil
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, []int{100, 50, 10}, sizes)
+	assert.Len(t, items, 150)
+}
+
+func TestMemoryClient_BatchGetItem(t *testing.T) {
+	client := newTestMemoryClient()
+	ctx := context.Background()
+	require.NoError(t, client.BatchWriteItem(ctx, "timeline", putRequests(120)))
+
+	keys := append(timelineKeys(130), timelineKeys(5)...)
+	items, err := client.BatchGetItem(ctx, "timeline", keys)
+
+	require.NoError(t, err)
+	assert.Len(t, items, 120, "duplicates are read once and missing keys left out")
+}
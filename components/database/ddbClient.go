@@ -2,6 +2,7 @@ package database
 
 import (
 	"context"
+	"strings"
 
 	"mensajesService/components/config"
 
@@ -13,18 +14,62 @@ import (
 
 type DDBClientInterface interface {
 	PutItem(ctx context.Context, tableName string, item map[string]types.AttributeValue) error
+	PutItemWithCondition(ctx context.Context, input *dynamodb.PutItemInput) error
 	GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue) (*dynamodb.GetItemOutput, error)
 	Query(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error)
+	DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error)
+	UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error)
+	BatchWriteItem(ctx context.Context, tableName string, requests []types.WriteRequest) error
+	BatchGetItem(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error)
 	GetMessagesTableName() string
 	GetFollowersTableName() string
 	GetTimelineTableName() string
+	GetUserStatsTableName() string
+	GetJobsTableName() string
+	GetDeadLettersTableName() string
+	GetHeavyAuthorsTableName() string
+	GetRepostsTableName() string
+	GetLikesTableName() string
+	GetMentionsTableName() string
+	GetHashtagsTableName() string
+	GetHashtagCountsTableName() string
+	GetBlocksTableName() string
+	GetMutesTableName() string
+	GetAccountSettingsTableName() string
+	GetFollowRequestsTableName() string
+	GetUsersTableName() string
+	GetHandlesTableName() string
 }
 
 type DDBClient struct {
-	client              *dynamodb.Client
-	tableMensajesName   string
-	tableSeguidoresName string
-	tableTimelineName   string
+	client                   *dynamodb.Client
+	tableMensajesName        string
+	tableSeguidoresName      string
+	tableTimelineName        string
+	tableUserStatsName       string
+	tableJobsName            string
+	tableDeadLettersName     string
+	tableHeavyAuthorsName    string
+	tableRepostsName         string
+	tableLikesName           string
+	tableMentionsName        string
+	tableHashtagsName        string
+	tableHashtagCountsName   string
+	tableBlocksName          string
+	tableMutesName           string
+	tableAccountSettingsName string
+	tableFollowRequestsName  string
+	tableUsersName           string
+	tableHandlesName         string
+	batchConcurrency         int
+}
+
+// NewClient returns the storage backend selected by STORAGE_BACKEND.
+func NewClient(ctx context.Context, cfg *config.AppConfig) (DDBClientInterface, error) {
+	if strings.EqualFold(cfg.StorageBackend, config.StorageBackendMemory) {
+		return NewMemoryClient(cfg), nil
+	}
+	return NewDDBClient(ctx, cfg)
 }
 
 func NewDDBClient(ctx context.Context, cfg *config.AppConfig) (*DDBClient, error) {
@@ -34,10 +79,26 @@ func NewDDBClient(ctx context.Context, cfg *config.AppConfig) (*DDBClient, error
 	}
 
 	return &DDBClient{
-		client:              dynamodb.NewFromConfig(awsCfg),
-		tableMensajesName:   cfg.TableMensajesName,
-		tableSeguidoresName: cfg.TableSeguidoresName,
-		tableTimelineName:   cfg.TableTimelineName,
+		client:                   dynamodb.NewFromConfig(awsCfg),
+		tableMensajesName:        cfg.TableMensajesName,
+		tableSeguidoresName:      cfg.TableSeguidoresName,
+		tableTimelineName:        cfg.TableTimelineName,
+		tableUserStatsName:       cfg.TableUserStatsName,
+		tableJobsName:            cfg.TableJobsName,
+		tableDeadLettersName:     cfg.TableDeadLettersName,
+		tableHeavyAuthorsName:    cfg.TableHeavyAuthorsName,
+		tableRepostsName:         cfg.TableRepostsName,
+		tableLikesName:           cfg.TableLikesName,
+		tableMentionsName:        cfg.TableMentionsName,
+		tableHashtagsName:        cfg.TableHashtagsName,
+		tableHashtagCountsName:   cfg.TableHashtagCountsName,
+		tableBlocksName:          cfg.TableBlocksName,
+		tableMutesName:           cfg.TableMutesName,
+		tableAccountSettingsName: cfg.TableAccountSettingsName,
+		tableFollowRequestsName:  cfg.TableFollowRequestsName,
+		tableUsersName:           cfg.TableUsersName,
+		tableHandlesName:         cfg.TableHandlesName,
+		batchConcurrency:         cfg.BatchWriteConcurrency,
 	}, nil
 }
 
@@ -49,6 +110,13 @@ func (d *DDBClient) PutItem(ctx context.Context, tableName string, item map[stri
 	return err
 }
 
+// PutItemWithCondition returns *types.ConditionalCheckFailedException when the
+// input's ConditionExpression does not hold.
+func (d *DDBClient) PutItemWithCondition(ctx context.Context, input *dynamodb.PutItemInput) error {
+	_, err := d.client.PutItem(ctx, input)
+	return err
+}
+
 func (d *DDBClient) GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue) (*dynamodb.GetItemOutput, error) {
 	return d.client.GetItem(ctx, &dynamodb.GetItemInput{
 		TableName: aws.String(tableName),
@@ -60,6 +128,30 @@ func (d *DDBClient) Query(ctx context.Context, input *dynamodb.QueryInput) (*dyn
 	return d.client.Query(ctx, input)
 }
 
+func (d *DDBClient) DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	return d.client.DeleteItem(ctx, input)
+}
+
+func (d *DDBClient) UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	return d.client.UpdateItem(ctx, input)
+}
+
+// BatchWriteItem writes requests in batches of 25, BATCH_WRITE_CONCURRENCY at
+// a time, retrying UnprocessedItems with backoff.
+func (d *DDBClient) BatchWriteItem(ctx context.Context, tableName string, requests []types.WriteRequest) error {
+	return batchWrite(ctx, tableName, requests, d.batchConcurrency, func(ctx context.Context, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+		return d.client.BatchWriteItem(ctx, input)
+	})
+}
+
+// BatchGetItem reads keys in batches of 100, retrying UnprocessedKeys with
+// backoff. Items come back in no particular order; missing ones are left out.
+func (d *DDBClient) BatchGetItem(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	return batchGet(ctx, tableName, keys, func(ctx context.Context, input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+		return d.client.BatchGetItem(ctx, input)
+	})
+}
+
 func (d *DDBClient) GetMessagesTableName() string {
 	return d.tableMensajesName
 }
@@ -71,3 +163,63 @@ func (d *DDBClient) GetFollowersTableName() string {
 func (d *DDBClient) GetTimelineTableName() string {
 	return d.tableTimelineName
 }
+
+func (d *DDBClient) GetUserStatsTableName() string {
+	return d.tableUserStatsName
+}
+
+func (d *DDBClient) GetJobsTableName() string {
+	return d.tableJobsName
+}
+
+func (d *DDBClient) GetDeadLettersTableName() string {
+	return d.tableDeadLettersName
+}
+
+func (d *DDBClient) GetHeavyAuthorsTableName() string {
+	return d.tableHeavyAuthorsName
+}
+
+func (d *DDBClient) GetRepostsTableName() string {
+	return d.tableRepostsName
+}
+
+func (d *DDBClient) GetLikesTableName() string {
+	return d.tableLikesName
+}
+
+func (d *DDBClient) GetMentionsTableName() string {
+	return d.tableMentionsName
+}
+
+func (d *DDBClient) GetHashtagsTableName() string {
+	return d.tableHashtagsName
+}
+
+func (d *DDBClient) GetHashtagCountsTableName() string {
+	return d.tableHashtagCountsName
+}
+
+func (d *DDBClient) GetBlocksTableName() string {
+	return d.tableBlocksName
+}
+
+func (d *DDBClient) GetMutesTableName() string {
+	return d.tableMutesName
+}
+
+func (d *DDBClient) GetAccountSettingsTableName() string {
+	return d.tableAccountSettingsName
+}
+
+func (d *DDBClient) GetFollowRequestsTableName() string {
+	return d.tableFollowRequestsName
+}
+
+func (d *DDBClient) GetUsersTableName() string {
+	return d.tableUsersName
+}
+
+func (d *DDBClient) GetHandlesTableName() string {
+	return d.tableHandlesName
+}
@@ -0,0 +1,614 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"mensajesService/components/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// keySchema describes the primary key of a table or one of its indexes.
+type keySchema struct {
+	hashKey  string
+	rangeKey string
+}
+
+type memoryTable struct {
+	schema  keySchema
+	indexes map[string]keySchema
+	items   map[string]map[string]types.AttributeValue
+}
+
+// MemoryClient is an in-process DDBClientInterface for local development and tests.
+// It mirrors the key schemas of the real tables and implements the subset of the
+// DynamoDB expression language used by the services.
+type MemoryClient struct {
+	mu                       sync.RWMutex
+	tables                   map[string]*memoryTable
+	tableMensajesName        string
+	tableSeguidoresName      string
+	tableTimelineName        string
+	tableUserStatsName       string
+	tableJobsName            string
+	tableDeadLettersName     string
+	tableHeavyAuthorsName    string
+	tableRepostsName         string
+	tableLikesName           string
+	tableMentionsName        string
+	tableHashtagsName        string
+	tableHashtagCountsName   string
+	tableBlocksName          string
+	tableMutesName           string
+	tableAccountSettingsName string
+	tableFollowRequestsName  string
+	tableUsersName           string
+	tableHandlesName         string
+	batchConcurrency         int
+}
+
+func NewMemoryClient(cfg *config.AppConfig) *MemoryClient {
+	c := &MemoryClient{
+		tables:                   map[string]*memoryTable{},
+		tableMensajesName:        cfg.TableMensajesName,
+		tableSeguidoresName:      cfg.TableSeguidoresName,
+		tableTimelineName:        cfg.TableTimelineName,
+		tableUserStatsName:       cfg.TableUserStatsName,
+		tableJobsName:            cfg.TableJobsName,
+		tableDeadLettersName:     cfg.TableDeadLettersName,
+		tableHeavyAuthorsName:    cfg.TableHeavyAuthorsName,
+		tableRepostsName:         cfg.TableRepostsName,
+		tableLikesName:           cfg.TableLikesName,
+		tableMentionsName:        cfg.TableMentionsName,
+		tableHashtagsName:        cfg.TableHashtagsName,
+		tableHashtagCountsName:   cfg.TableHashtagCountsName,
+		tableBlocksName:          cfg.TableBlocksName,
+		tableMutesName:           cfg.TableMutesName,
+		tableAccountSettingsName: cfg.TableAccountSettingsName,
+		tableFollowRequestsName:  cfg.TableFollowRequestsName,
+		tableUsersName:           cfg.TableUsersName,
+		tableHandlesName:         cfg.TableHandlesName,
+		batchConcurrency:         cfg.BatchWriteConcurrency,
+	}
+
+	c.createTable(cfg.TableMensajesName, keySchema{hashKey: "user_id", rangeKey: "created_at"}, map[string]keySchema{
+		"MessageIdIndex":    {hashKey: "message_id"},
+		"ConversationIndex": {hashKey: "conversation_id", rangeKey: "created_at"},
+	})
+	c.createTable(cfg.TableSeguidoresName, keySchema{hashKey: "follower_id", rangeKey: "following_id"}, map[string]keySchema{
+		"FollowingIndex": {hashKey: "following_id", rangeKey: "follower_id"},
+	})
+	c.createTable(cfg.TableTimelineName, keySchema{hashKey: "user_id", rangeKey: "timestamp"}, nil)
+	c.createTable(cfg.TableUserStatsName, keySchema{hashKey: "user_id"}, nil)
+	c.createTable(cfg.TableJobsName, keySchema{hashKey: "queue", rangeKey: "job_id"}, map[string]keySchema{
+		"QueueDueIndex": {hashKey: "queue", rangeKey: "next_attempt_at"},
+	})
+	c.createTable(cfg.TableDeadLettersName, keySchema{hashKey: "queue", rangeKey: "job_id"}, nil)
+	c.createTable(cfg.TableHeavyAuthorsName, keySchema{hashKey: "shard", rangeKey: "user_id"}, nil)
+	c.createTable(cfg.TableRepostsName, keySchema{hashKey: "message_id", rangeKey: "user_id"}, nil)
+	c.createTable(cfg.TableLikesName, keySchema{hashKey: "message_id", rangeKey: "user_id"}, map[string]keySchema{
+		"UserLikesIndex": {hashKey: "user_id", rangeKey: "liked_at"},
+	})
+	c.createTable(cfg.TableMentionsName, keySchema{hashKey: "user_id", rangeKey: "sort_key"}, nil)
+	c.createTable(cfg.TableHashtagsName, keySchema{hashKey: "tag", rangeKey: "sort_key"}, nil)
+	c.createTable(cfg.TableHashtagCountsName, keySchema{hashKey: "bucket", rangeKey: "tag"}, nil)
+	c.createTable(cfg.TableBlocksName, keySchema{hashKey: "user_id", rangeKey: "target_id"}, map[string]keySchema{
+		"BlockedByIndex": {hashKey: "target_id", rangeKey: "user_id"},
+	})
+	c.createTable(cfg.TableMutesName, keySchema{hashKey: "user_id", rangeKey: "target_id"}, nil)
+	c.createTable(cfg.TableAccountSettingsName, keySchema{hashKey: "user_id"}, nil)
+	c.createTable(cfg.TableFollowRequestsName, keySchema{hashKey: "user_id", rangeKey: "requester_id"}, nil)
+	c.createTable(cfg.TableUsersName, keySchema{hashKey: "user_id"}, nil)
+	c.createTable(cfg.TableHandlesName, keySchema{hashKey: "handle"}, nil)
+
+	return c
+}
+
+func (c *MemoryClient) createTable(name string, schema keySchema, indexes map[string]keySchema) {
+	c.tables[name] = &memoryTable{
+		schema:  schema,
+		indexes: indexes,
+		items:   map[string]map[string]types.AttributeValue{},
+	}
+}
+
+func (c *MemoryClient) table(name string) (*memoryTable, error) {
+	t, ok := c.tables[name]
+	if !ok {
+		return nil, &types.ResourceNotFoundException{Message: aws.String("table not found: " + name)}
+	}
+	return t, nil
+}
+
+func (c *MemoryClient) PutItem(ctx context.Context, tableName string, item map[string]types.AttributeValue) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(tableName)
+	if err != nil {
+		return err
+	}
+	id, err := t.schema.itemID(item)
+	if err != nil {
+		return err
+	}
+	t.items[id] = copyItem(item)
+	return nil
+}
+
+func (c *MemoryClient) PutItemWithCondition(ctx context.Context, input *dynamodb.PutItemInput) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(aws.ToString(input.TableName))
+	if err != nil {
+		return err
+	}
+	id, err := t.schema.itemID(input.Item)
+	if err != nil {
+		return err
+	}
+	if err := checkCondition(input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues, t.items[id]); err != nil {
+		return err
+	}
+	t.items[id] = copyItem(input.Item)
+	return nil
+}
+
+func (c *MemoryClient) GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue) (*dynamodb.GetItemOutput, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, err := c.table(tableName)
+	if err != nil {
+		return nil, err
+	}
+	id, err := t.schema.itemID(key)
+	if err != nil {
+		return nil, err
+	}
+	return &dynamodb.GetItemOutput{Item: copyItem(t.items[id])}, nil
+}
+
+func (c *MemoryClient) Query(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	t, err := c.table(aws.ToString(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+
+	schema := t.schema
+	if input.IndexName != nil {
+		index, ok := t.indexes[*input.IndexName]
+		if !ok {
+			return nil, fmt.Errorf("index %s not found on table %s", *input.IndexName, aws.ToString(input.TableName))
+		}
+		schema = index
+	}
+
+	keyCondition, err := parseExpression(aws.ToString(input.KeyConditionExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+	if !hasEquality(keyCondition, schema.hashKey) {
+		return nil, errors.New("key condition must include an equality on " + schema.hashKey)
+	}
+	var filter expression
+	if input.FilterExpression != nil {
+		filter, err = parseExpression(*input.FilterExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var matches []map[string]types.AttributeValue
+	for _, item := range t.items {
+		if item[schema.hashKey] == nil || (schema.rangeKey != "" && item[schema.rangeKey] == nil) {
+			continue
+		}
+		if keyCondition.matches(item) {
+			matches = append(matches, item)
+		}
+	}
+
+	forward := input.ScanIndexForward == nil || *input.ScanIndexForward
+	sort.Slice(matches, func(i, j int) bool {
+		cmp := compareItems(matches[i], matches[j], schema, t.schema)
+		if forward {
+			return cmp < 0
+		}
+		return cmp > 0
+	})
+
+	start := 0
+	if len(input.ExclusiveStartKey) > 0 {
+		start = len(matches)
+		for i, item := range matches {
+			cmp := compareItems(item, input.ExclusiveStartKey, schema, t.schema)
+			if (forward && cmp > 0) || (!forward && cmp < 0) {
+				start = i
+				break
+			}
+		}
+	}
+	matches = matches[start:]
+
+	output := &dynamodb.QueryOutput{}
+	if input.Limit != nil && int(*input.Limit) < len(matches) {
+		matches = matches[:*input.Limit]
+		output.LastEvaluatedKey = lastEvaluatedKey(matches[len(matches)-1], schema, t.schema)
+	}
+
+	for _, item := range matches {
+		if filter != nil && !filter.matches(item) {
+			continue
+		}
+		output.Items = append(output.Items, copyItem(item))
+	}
+	output.Count = int32(len(output.Items))
+	output.ScannedCount = int32(len(matches))
+	if input.Select == types.SelectCount {
+		output.Items = nil
+	}
+
+	return output, nil
+}
+
+func (c *MemoryClient) DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(aws.ToString(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+	id, err := t.schema.itemID(input.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := t.items[id]
+	if err := checkCondition(input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues, existing); err != nil {
+		return nil, err
+	}
+	delete(t.items, id)
+
+	output := &dynamodb.DeleteItemOutput{}
+	if input.ReturnValues == types.ReturnValueAllOld {
+		output.Attributes = existing
+	}
+	return output, nil
+}
+
+// BatchWriteItem goes through the same chunking as the DynamoDB client; the
+// in-memory store never leaves items unprocessed.
+func (c *MemoryClient) BatchWriteItem(ctx context.Context, tableName string, requests []types.WriteRequest) error {
+	return batchWrite(ctx, tableName, requests, c.batchConcurrency, c.batchWriteItem)
+}
+
+func (c *MemoryClient) batchWriteItem(ctx context.Context, input *dynamodb.BatchWriteItemInput) (*dynamodb.BatchWriteItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for tableName, requests := range input.RequestItems {
+		if len(requests) > maxBatchWriteItems {
+			return nil, fmt.Errorf("batch of %d items exceeds the limit of %d", len(requests), maxBatchWriteItems)
+		}
+		t, err := c.table(tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		// Like DynamoDB, reject the whole batch before writing anything when
+		// two requests target the same key.
+		ids := make([]string, len(requests))
+		seen := make(map[string]bool, len(requests))
+		for i, request := range requests {
+			var key map[string]types.AttributeValue
+			switch {
+			case request.PutRequest != nil:
+				key = request.PutRequest.Item
+			case request.DeleteRequest != nil:
+				key = request.DeleteRequest.Key
+			default:
+				return nil, fmt.Errorf("batch request for table %s has neither a put nor a delete", tableName)
+			}
+			id, err := t.schema.itemID(key)
+			if err != nil {
+				return nil, err
+			}
+			if seen[id] {
+				return nil, fmt.Errorf("batch for table %s contains duplicate keys", tableName)
+			}
+			seen[id] = true
+			ids[i] = id
+		}
+
+		for i, request := range requests {
+			if request.PutRequest != nil {
+				t.items[ids[i]] = copyItem(request.PutRequest.Item)
+			} else {
+				delete(t.items, ids[i])
+			}
+		}
+	}
+	return &dynamodb.BatchWriteItemOutput{}, nil
+}
+
+// BatchGetItem goes through the same chunking as the DynamoDB client; the
+// in-memory store never leaves keys unprocessed.
+func (c *MemoryClient) BatchGetItem(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	return batchGet(ctx, tableName, keys, c.batchGetItem)
+}
+
+func (c *MemoryClient) batchGetItem(ctx context.Context, input *dynamodb.BatchGetItemInput) (*dynamodb.BatchGetItemOutput, error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	output := &dynamodb.BatchGetItemOutput{Responses: map[string][]map[string]types.AttributeValue{}}
+	for tableName, request := range input.RequestItems {
+		if len(request.Keys) > maxBatchGetItems {
+			return nil, fmt.Errorf("batch of %d keys exceeds the limit of %d", len(request.Keys), maxBatchGetItems)
+		}
+		t, err := c.table(tableName)
+		if err != nil {
+			return nil, err
+		}
+
+		seen := make(map[string]bool, len(request.Keys))
+		for _, key := range request.Keys {
+			id, err := t.schema.itemID(key)
+			if err != nil {
+				return nil, err
+			}
+			if seen[id] {
+				return nil, fmt.Errorf("batch for table %s contains duplicate keys", tableName)
+			}
+			seen[id] = true
+			if item, ok := t.items[id]; ok {
+				output.Responses[tableName] = append(output.Responses[tableName], copyItem(item))
+			}
+		}
+	}
+	return output, nil
+}
+
+// UpdateItem upserts like DynamoDB: a missing item is created from the key
+// before the update expression is applied.
+func (c *MemoryClient) UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	t, err := c.table(aws.ToString(input.TableName))
+	if err != nil {
+		return nil, err
+	}
+	id, err := t.schema.itemID(input.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	existing := t.items[id]
+	if err := checkCondition(input.ConditionExpression, input.ExpressionAttributeNames, input.ExpressionAttributeValues, existing); err != nil {
+		return nil, err
+	}
+	actions, err := parseUpdateExpression(aws.ToString(input.UpdateExpression), input.ExpressionAttributeNames, input.ExpressionAttributeValues)
+	if err != nil {
+		return nil, err
+	}
+
+	item := copyItem(existing)
+	if item == nil {
+		item = copyItem(input.Key)
+	}
+	updatedNames, err := applyUpdate(item, actions)
+	if err != nil {
+		return nil, err
+	}
+	t.items[id] = item
+
+	output := &dynamodb.UpdateItemOutput{}
+	switch input.ReturnValues {
+	case types.ReturnValueAllOld:
+		output.Attributes = copyItem(existing)
+	case types.ReturnValueAllNew:
+		output.Attributes = copyItem(item)
+	case types.ReturnValueUpdatedNew:
+		output.Attributes = map[string]types.AttributeValue{}
+		for _, name := range updatedNames {
+			if value, ok := item[name]; ok {
+				output.Attributes[name] = copyValue(value)
+			}
+		}
+	}
+	return output, nil
+}
+
+func (c *MemoryClient) GetMessagesTableName() string {
+	return c.tableMensajesName
+}
+
+func (c *MemoryClient) GetFollowersTableName() string {
+	return c.tableSeguidoresName
+}
+
+func (c *MemoryClient) GetTimelineTableName() string {
+	return c.tableTimelineName
+}
+
+func (c *MemoryClient) GetUserStatsTableName() string {
+	return c.tableUserStatsName
+}
+
+// checkCondition evaluates a ConditionExpression against the current item and
+// fails the same way DynamoDB does.
+func checkCondition(condition *string, names map[string]string, values map[string]types.AttributeValue, existing map[string]types.AttributeValue) error {
+	if condition == nil {
+		return nil
+	}
+	expr, err := parseExpression(*condition, names, values)
+	if err != nil {
+		return err
+	}
+	if existing == nil {
+		existing = map[string]types.AttributeValue{}
+	}
+	if !expr.matches(existing) {
+		return &types.ConditionalCheckFailedException{Message: aws.String("The conditional request failed")}
+	}
+	return nil
+}
+
+func (k keySchema) itemID(item map[string]types.AttributeValue) (string, error) {
+	hash, ok := keyString(item[k.hashKey])
+	if !ok {
+		return "", errors.New("missing key attribute " + k.hashKey)
+	}
+	if k.rangeKey == "" {
+		return hash, nil
+	}
+	rng, ok := keyString(item[k.rangeKey])
+	if !ok {
+		return "", errors.New("missing key attribute " + k.rangeKey)
+	}
+	return hash + "\x00" + rng, nil
+}
+
+// compareItems orders items by the index range key and breaks ties with the
+// table primary key, which keeps pagination over non-unique index keys stable.
+func compareItems(a, b map[string]types.AttributeValue, index, table keySchema) int {
+	for _, name := range []string{index.rangeKey, table.hashKey, table.rangeKey} {
+		if name == "" {
+			continue
+		}
+		if cmp := compareValues(a[name], b[name]); cmp != 0 {
+			return cmp
+		}
+	}
+	return 0
+}
+
+func lastEvaluatedKey(item map[string]types.AttributeValue, index, table keySchema) map[string]types.AttributeValue {
+	key := map[string]types.AttributeValue{}
+	for _, name := range []string{index.hashKey, index.rangeKey, table.hashKey, table.rangeKey} {
+		if name != "" {
+			key[name] = item[name]
+		}
+	}
+	return copyItem(key)
+}
+
+func keyString(value types.AttributeValue) (string, bool) {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return "S" + v.Value, true
+	case *types.AttributeValueMemberN:
+		return "N" + v.Value, true
+	case *types.AttributeValueMemberB:
+		return "B" + string(v.Value), true
+	}
+	return "", false
+}
+
+func copyItem(item map[string]types.AttributeValue) map[string]types.AttributeValue {
+	if item == nil {
+		return nil
+	}
+	copied := make(map[string]types.AttributeValue, len(item))
+	for name, value := range item {
+		copied[name] = copyValue(value)
+	}
+	return copied
+}
+
+func copyValue(value types.AttributeValue) types.AttributeValue {
+	switch v := value.(type) {
+	case *types.AttributeValueMemberS:
+		return &types.AttributeValueMemberS{Value: v.Value}
+	case *types.AttributeValueMemberN:
+		return &types.AttributeValueMemberN{Value: v.Value}
+	case *types.AttributeValueMemberBOOL:
+		return &types.AttributeValueMemberBOOL{Value: v.Value}
+	case *types.AttributeValueMemberNULL:
+		return &types.AttributeValueMemberNULL{Value: v.Value}
+	case *types.AttributeValueMemberB:
+		return &types.AttributeValueMemberB{Value: append([]byte(nil), v.Value...)}
+	case *types.AttributeValueMemberSS:
+		return &types.AttributeValueMemberSS{Value: append([]string(nil), v.Value...)}
+	case *types.AttributeValueMemberNS:
+		return &types.AttributeValueMemberNS{Value: append([]string(nil), v.Value...)}
+	case *types.AttributeValueMemberL:
+		list := make([]types.AttributeValue, len(v.Value))
+		for i, element := range v.Value {
+			list[i] = copyValue(element)
+		}
+		return &types.AttributeValueMemberL{Value: list}
+	case *types.AttributeValueMemberM:
+		return &types.AttributeValueMemberM{Value: copyItem(v.Value)}
+	}
+	return value
+}
+
+func (c *MemoryClient) GetJobsTableName() string {
+	return c.tableJobsName
+}
+
+func (c *MemoryClient) GetDeadLettersTableName() string {
+	return c.tableDeadLettersName
+}
+
+func (c *MemoryClient) GetHeavyAuthorsTableName() string {
+	return c.tableHeavyAuthorsName
+}
+
+func (c *MemoryClient) GetRepostsTableName() string {
+	return c.tableRepostsName
+}
+
+func (c *MemoryClient) GetLikesTableName() string {
+	return c.tableLikesName
+}
+
+func (c *MemoryClient) GetMentionsTableName() string {
+	return c.tableMentionsName
+}
+
+func (c *MemoryClient) GetHashtagsTableName() string {
+	return c.tableHashtagsName
+}
+
+func (c *MemoryClient) GetHashtagCountsTableName() string {
+	return c.tableHashtagCountsName
+}
+
+func (c *MemoryClient) GetBlocksTableName() string {
+	return c.tableBlocksName
+}
+
+func (c *MemoryClient) GetMutesTableName() string {
+	return c.tableMutesName
+}
+
+func (c *MemoryClient) GetAccountSettingsTableName() string {
+	return c.tableAccountSettingsName
+}
+
+func (c *MemoryClient) GetFollowRequestsTableName() string {
+	return c.tableFollowRequestsName
+}
+
+func (c *MemoryClient) GetUsersTableName() string {
+	return c.tableUsersName
+}
+
+func (c *MemoryClient) GetHandlesTableName() string {
+	return c.tableHandlesName
+}
@@ -0,0 +1,232 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"mensajesService/components/config"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestMemoryClient() *MemoryClient {
+	return NewMemoryClient(&config.AppConfig{
+		TableMensajesName:   "messages",
+		TableSeguidoresName: "follows",
+		TableTimelineName:   "timeline",
+	})
+}
+
+func timelineRow(userID string, timestamp int) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"user_id":    &types.AttributeValueMemberS{Value: userID},
+		"timestamp":  &types.AttributeValueMemberN{Value: fmt.Sprint(timestamp)},
+		"message_id": &types.AttributeValueMemberS{Value: fmt.Sprintf("msg-%d", timestamp)},
+		"author_id":  &types.AttributeValueMemberS{Value: "author"},
+	}
+}
+
+func TestMemoryClient_PutAndGetItem(t *testing.T) {
+	client := newTestMemoryClient()
+	ctx := context.Background()
+
+	require.NoError(t, client.PutItem(ctx, "timeline", timelineRow("user1", 10)))
+
+	output, err := client.GetItem(ctx, "timeline", map[string]types.AttributeValue{
+		"user_id":   &types.AttributeValueMemberS{Value: "user1"},
+		"timestamp": &types.AttributeValueMemberN{Value: "10"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "msg-10", output.Item["message_id"].(*types.AttributeValueMemberS).Value)
+
+	output, err = client.GetItem(ctx, "timeline", map[string]types.AttributeValue{
+		"user_id":   &types.AttributeValueMemberS{Value: "user1"},
+		"timestamp": &types.AttributeValueMemberN{Value: "11"},
+	})
+	require.NoError(t, err)
+	assert.Nil(t, output.Item)
+
+	err = client.PutItem(ctx, "timeline", map[string]types.AttributeValue{
+		"user_id": &types.AttributeValueMemberS{Value: "user1"},
+	})
+	assert.Error(t, err)
+
+	err = client.PutItem(ctx, "unknown", timelineRow("user1", 10))
+	assert.Error(t, err)
+}
+
+func TestMemoryClient_QueryOrderAndPagination(t *testing.T) {
+	client := newTestMemoryClient()
+	ctx := context.Background()
+
+	// 9 < 10 < 100 numerically, but not lexicographically.
+	for _, ts := range []int{100, 9, 10} {
+		require.NoError(t, client.PutItem(ctx, "timeline", timelineRow("user1", ts)))
+	}
+	require.NoError(t, client.PutItem(ctx, "timeline", timelineRow("user2", 50)))
+
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String("timeline"),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: "user1"},
+		},
+		ScanIndexForward: aws.Bool(false),
+		Limit:            aws.Int32(2),
+	}
+
+	first, err := client.Query(ctx, input)
+	require.NoError(t, err)
+	require.Len(t, first.Items, 2)
+	assert.Equal(t, "100", first.Items[0]["timestamp"].(*types.AttributeValueMemberN).Value)
+	assert.Equal(t, "10", first.Items[1]["timestamp"].(*types.AttributeValueMemberN).Value)
+	require.NotNil(t, first.LastEvaluatedKey)
+
+	input.ExclusiveStartKey = first.LastEvaluatedKey
+	second, err := client.Query(ctx, input)
+	require.NoError(t, err)
+	require.Len(t, second.Items, 1)
+	assert.Equal(t, "9", second.Items[0]["timestamp"].(*types.AttributeValueMemberN).Value)
+	assert.Nil(t, second.LastEvaluatedKey)
+}
+
+func TestMemoryClient_QueryIndexAndConditions(t *testing.T) {
+	client := newTestMemoryClient()
+	ctx := context.Background()
+
+	for _, follow := range [][2]string{{"alice", "carol"}, {"bob", "carol"}, {"carol", "alice"}} {
+		require.NoError(t, client.PutItem(ctx, "follows", map[string]types.AttributeValue{
+			"follower_id":  &types.AttributeValueMemberS{Value: follow[0]},
+			"following_id": &types.AttributeValueMemberS{Value: follow[1]},
+		}))
+	}
+
+	output, err := client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String("follows"),
+		IndexName:              aws.String("FollowingIndex"),
+		KeyConditionExpression: aws.String("following_id = :following_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":following_id": &types.AttributeValueMemberS{Value: "carol"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Items, 2)
+	assert.Equal(t, "alice", output.Items[0]["follower_id"].(*types.AttributeValueMemberS).Value)
+
+	output, err = client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String("follows"),
+		IndexName:              aws.String("FollowingIndex"),
+		KeyConditionExpression: aws.String("#f = :following_id AND begins_with(follower_id, :prefix)"),
+		FilterExpression:       aws.String("NOT (follower_id = :excluded)"),
+		ExpressionAttributeNames: map[string]string{
+			"#f": "following_id",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":following_id": &types.AttributeValueMemberS{Value: "carol"},
+			":prefix":       &types.AttributeValueMemberS{Value: "b"},
+			":excluded":     &types.AttributeValueMemberS{Value: "alice"},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, output.Items, 1)
+	assert.Equal(t, "bob", output.Items[0]["follower_id"].(*types.AttributeValueMemberS).Value)
+
+	_, err = client.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String("follows"),
+		KeyConditionExpression: aws.String("following_id = :following_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":following_id": &types.AttributeValueMemberS{Value: "carol"},
+		},
+	})
+	assert.Error(t, err)
+}
+
+func TestMemoryClient_DeleteItem(t *testing.T) {
+	client := newTestMemoryClient()
+	ctx := context.Background()
+	require.NoError(t, client.PutItem(ctx, "timeline", timelineRow("user1", 10)))
+
+	key := map[string]types.AttributeValue{
+		"user_id":   &types.AttributeValueMemberS{Value: "user1"},
+		"timestamp": &types.AttributeValueMemberN{Value: "10"},
+	}
+
+	_, err := client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String("timeline"),
+		Key:                 key,
+		ConditionExpression: aws.String("message_id = :message_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":message_id": &types.AttributeValueMemberS{Value: "other"},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	assert.ErrorAs(t, err, &conditionFailed)
+
+	output, err := client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String("timeline"),
+		Key:          key,
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "msg-10", output.Attributes["message_id"].(*types.AttributeValueMemberS).Value)
+
+	output, err = client.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String("timeline"),
+		Key:          key,
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	require.NoError(t, err)
+	assert.Nil(t, output.Attributes)
+}
+
+func TestMemoryClient_UpdateItemAndConditionalPut(t *testing.T) {
+	client := NewMemoryClient(&config.AppConfig{TableUserStatsName: "user_stats"})
+	ctx := context.Background()
+	key := map[string]types.AttributeValue{
+		"user_id": &types.AttributeValueMemberS{Value: "user1"},
+	}
+
+	for _, delta := range []string{"1", "1", "-1"} {
+		_, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName:        aws.String("user_stats"),
+			Key:              key,
+			UpdateExpression: aws.String("ADD followers_count :delta SET updated = :now"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":delta": &types.AttributeValueMemberN{Value: delta},
+				":now":   &types.AttributeValueMemberS{Value: "now"},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	output, err := client.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:        aws.String("user_stats"),
+		Key:              key,
+		UpdateExpression: aws.String("SET following_count = :one REMOVE updated"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":one": &types.AttributeValueMemberN{Value: "1"},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "1", output.Attributes["followers_count"].(*types.AttributeValueMemberN).Value)
+	assert.Equal(t, "1", output.Attributes["following_count"].(*types.AttributeValueMemberN).Value)
+	assert.NotContains(t, output.Attributes, "updated")
+
+	item := map[string]types.AttributeValue{
+		"user_id": &types.AttributeValueMemberS{Value: "user2"},
+	}
+	putIfAbsent := &dynamodb.PutItemInput{
+		TableName:           aws.String("user_stats"),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(user_id)"),
+	}
+	require.NoError(t, client.PutItemWithCondition(ctx, putIfAbsent))
+	var conditionFailed *types.ConditionalCheckFailedException
+	assert.ErrorAs(t, client.PutItemWithCondition(ctx, putIfAbsent), &conditionFailed)
+}
@@ -0,0 +1,413 @@
+package database
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"unicode"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// expression is a parsed key condition, filter or condition expression
+// evaluated against items of the in-memory store.
+type expression interface {
+	matches(item map[string]types.AttributeValue) bool
+}
+
+type andExpression struct{ left, right expression }
+type orExpression struct{ left, right expression }
+type notExpression struct{ inner expression }
+
+type comparison struct {
+	operator    string
+	left, right operand
+}
+
+type between struct {
+	value, low, high operand
+}
+
+type function struct {
+	name string
+	args []operand
+}
+
+// operand is either an attribute path or a literal from ExpressionAttributeValues.
+type operand struct {
+	attribute string
+	value     types.AttributeValue
+}
+
+func (o operand) resolve(item map[string]types.AttributeValue) types.AttributeValue {
+	if o.attribute != "" {
+		return item[o.attribute]
+	}
+	return o.value
+}
+
+func (e andExpression) matches(item map[string]types.AttributeValue) bool {
+	return e.left.matches(item) && e.right.matches(item)
+}
+
+func (e orExpression) matches(item map[string]types.AttributeValue) bool {
+	return e.left.matches(item) || e.right.matches(item)
+}
+
+func (e notExpression) matches(item map[string]types.AttributeValue) bool {
+	return !e.inner.matches(item)
+}
+
+func (e comparison) matches(item map[string]types.AttributeValue) bool {
+	left, right := e.left.resolve(item), e.right.resolve(item)
+	if left == nil || right == nil {
+		return e.operator == "<>" && (left != nil || right != nil)
+	}
+	switch e.operator {
+	case "=":
+		return equalValues(left, right)
+	case "<>":
+		return !equalValues(left, right)
+	}
+	if !comparableValues(left, right) {
+		return false
+	}
+	cmp := compareValues(left, right)
+	switch e.operator {
+	case "<":
+		return cmp < 0
+	case "<=":
+		return cmp <= 0
+	case ">":
+		return cmp > 0
+	case ">=":
+		return cmp >= 0
+	}
+	return false
+}
+
+func (e between) matches(item map[string]types.AttributeValue) bool {
+	value, low, high := e.value.resolve(item), e.low.resolve(item), e.high.resolve(item)
+	if !comparableValues(value, low) || !comparableValues(value, high) {
+		return false
+	}
+	return compareValues(value, low) >= 0 && compareValues(value, high) <= 0
+}
+
+func (e function) matches(item map[string]types.AttributeValue) bool {
+	switch e.name {
+	case "attribute_exists":
+		return e.args[0].resolve(item) != nil
+	case "attribute_not_exists":
+		return e.args[0].resolve(item) == nil
+	case "begins_with":
+		value, ok := e.args[0].resolve(item).(*types.AttributeValueMemberS)
+		prefix, prefixOK := e.args[1].resolve(item).(*types.AttributeValueMemberS)
+		return ok && prefixOK && strings.HasPrefix(value.Value, prefix.Value)
+	case "contains":
+		switch value := e.args[0].resolve(item).(type) {
+		case *types.AttributeValueMemberS:
+			needle, ok := e.args[1].resolve(item).(*types.AttributeValueMemberS)
+			return ok && strings.Contains(value.Value, needle.Value)
+		case *types.AttributeValueMemberSS:
+			needle, ok := e.args[1].resolve(item).(*types.AttributeValueMemberS)
+			if !ok {
+				return false
+			}
+			for _, element := range value.Value {
+				if element == needle.Value {
+					return true
+				}
+			}
+		case *types.AttributeValueMemberL:
+			needle := e.args[1].resolve(item)
+			for _, element := range value.Value {
+				if equalValues(element, needle) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hasEquality reports whether the top level conjunction of the expression
+// pins the given attribute with "=", as DynamoDB requires for hash keys.
+func hasEquality(e expression, attribute string) bool {
+	switch expr := e.(type) {
+	case andExpression:
+		return hasEquality(expr.left, attribute) || hasEquality(expr.right, attribute)
+	case comparison:
+		return expr.operator == "=" && (expr.left.attribute == attribute || expr.right.attribute == attribute)
+	}
+	return false
+}
+
+func equalValues(a, b types.AttributeValue) bool {
+	if comparableValues(a, b) {
+		return compareValues(a, b) == 0
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+func comparableValues(a, b types.AttributeValue) bool {
+	switch a.(type) {
+	case *types.AttributeValueMemberS:
+		_, ok := b.(*types.AttributeValueMemberS)
+		return ok
+	case *types.AttributeValueMemberN:
+		_, ok := b.(*types.AttributeValueMemberN)
+		return ok
+	case *types.AttributeValueMemberB:
+		_, ok := b.(*types.AttributeValueMemberB)
+		return ok
+	}
+	return false
+}
+
+// compareValues orders scalar attribute values the way DynamoDB sorts range keys.
+func compareValues(a, b types.AttributeValue) int {
+	switch av := a.(type) {
+	case *types.AttributeValueMemberS:
+		if bv, ok := b.(*types.AttributeValueMemberS); ok {
+			return strings.Compare(av.Value, bv.Value)
+		}
+	case *types.AttributeValueMemberN:
+		if bv, ok := b.(*types.AttributeValueMemberN); ok {
+			x, _, errX := big.ParseFloat(av.Value, 10, 128, big.ToNearestEven)
+			y, _, errY := big.ParseFloat(bv.Value, 10, 128, big.ToNearestEven)
+			if errX != nil || errY != nil {
+				return strings.Compare(av.Value, bv.Value)
+			}
+			return x.Cmp(y)
+		}
+	case *types.AttributeValueMemberB:
+		if bv, ok := b.(*types.AttributeValueMemberB); ok {
+			return strings.Compare(string(av.Value), string(bv.Value))
+		}
+	}
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	return 0
+}
+
+type expressionParser struct {
+	tokens []string
+	pos    int
+	names  map[string]string
+	values map[string]types.AttributeValue
+}
+
+func parseExpression(input string, names map[string]string, values map[string]types.AttributeValue) (expression, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty expression")
+	}
+	p := &expressionParser{tokens: tokens, names: names, values: values}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected token %q in expression %q", p.tokens[p.pos], input)
+	}
+	return expr, nil
+}
+
+func tokenize(input string) ([]string, error) {
+	var tokens []string
+	runes := []rune(input)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case strings.ContainsRune("(),=+-", r):
+			tokens = append(tokens, string(r))
+			i++
+		case r == '<' || r == '>':
+			if i+1 < len(runes) && (runes[i+1] == '=' || (r == '<' && runes[i+1] == '>')) {
+				tokens = append(tokens, string(runes[i:i+2]))
+				i += 2
+			} else {
+				tokens = append(tokens, string(r))
+				i++
+			}
+		case r == '#' || r == ':' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			start := i
+			for i < len(runes) && (runes[i] == '#' || runes[i] == ':' || runes[i] == '_' || runes[i] == '.' || runes[i] == '-' || unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i])) {
+				i++
+			}
+			tokens = append(tokens, string(runes[start:i]))
+		default:
+			return nil, fmt.Errorf("unexpected character %q in expression %q", r, input)
+		}
+	}
+	return tokens, nil
+}
+
+func (p *expressionParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *expressionParser) next() string {
+	token := p.peek()
+	p.pos++
+	return token
+}
+
+func (p *expressionParser) expect(token string) error {
+	if got := p.next(); !strings.EqualFold(got, token) {
+		return fmt.Errorf("expected %q, got %q", token, got)
+	}
+	return nil
+}
+
+func (p *expressionParser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "OR") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseAnd() (expression, error) {
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	for strings.EqualFold(p.peek(), "AND") {
+		p.next()
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpression{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *expressionParser) parseTerm() (expression, error) {
+	token := p.peek()
+	switch {
+	case token == "(":
+		p.next()
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return expr, p.expect(")")
+	case strings.EqualFold(token, "NOT"):
+		p.next()
+		inner, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{inner: inner}, nil
+	case p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == "(":
+		return p.parseFunction()
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operator := p.next()
+	if strings.EqualFold(operator, "BETWEEN") {
+		low, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect("AND"); err != nil {
+			return nil, err
+		}
+		high, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return between{value: left, low: low, high: high}, nil
+	}
+	switch operator {
+	case "=", "<>", "<", "<=", ">", ">=":
+	default:
+		return nil, fmt.Errorf("unsupported operator %q", operator)
+	}
+	right, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	return comparison{operator: operator, left: left, right: right}, nil
+}
+
+func (p *expressionParser) parseFunction() (expression, error) {
+	name := strings.ToLower(p.next())
+	arity := map[string]int{"attribute_exists": 1, "attribute_not_exists": 1, "begins_with": 2, "contains": 2}
+	expected, ok := arity[name]
+	if !ok {
+		return nil, fmt.Errorf("unsupported function %q", name)
+	}
+	if err := p.expect("("); err != nil {
+		return nil, err
+	}
+	var args []operand
+	for {
+		arg, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+		if p.peek() != "," {
+			break
+		}
+		p.next()
+	}
+	if err := p.expect(")"); err != nil {
+		return nil, err
+	}
+	if len(args) != expected {
+		return nil, fmt.Errorf("%s expects %d arguments", name, expected)
+	}
+	return function{name: name, args: args}, nil
+}
+
+func (p *expressionParser) parseOperand() (operand, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return operand{}, fmt.Errorf("unexpected end of expression")
+	case strings.HasPrefix(token, ":"):
+		value, ok := p.values[token]
+		if !ok {
+			return operand{}, fmt.Errorf("missing expression attribute value %s", token)
+		}
+		return operand{value: value}, nil
+	case strings.HasPrefix(token, "#"):
+		name, ok := p.names[token]
+		if !ok {
+			return operand{}, fmt.Errorf("missing expression attribute name %s", token)
+		}
+		return operand{attribute: name}, nil
+	}
+	return operand{attribute: token}, nil
+}
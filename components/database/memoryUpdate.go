@@ -0,0 +1,194 @@
+package database
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// updateAction is one SET/ADD/REMOVE clause of an UpdateExpression.
+type updateAction struct {
+	kind      string
+	attribute string
+	value     updateValue
+}
+
+// updateValue is the right hand side of a SET action: an operand, an
+// arithmetic expression or if_not_exists(path, operand).
+type updateValue struct {
+	left     operand
+	operator string
+	right    operand
+	fallback bool
+}
+
+func (v updateValue) resolve(item map[string]types.AttributeValue) (types.AttributeValue, error) {
+	if v.fallback {
+		if existing := v.left.resolve(item); existing != nil {
+			return existing, nil
+		}
+		return v.right.resolve(item), nil
+	}
+	left := v.left.resolve(item)
+	if v.operator == "" {
+		return left, nil
+	}
+	return addNumbers(left, v.right.resolve(item), v.operator == "-")
+}
+
+func parseUpdateExpression(input string, names map[string]string, values map[string]types.AttributeValue) ([]updateAction, error) {
+	tokens, err := tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+	p := &expressionParser{tokens: tokens, names: names, values: values}
+
+	var actions []updateAction
+	kind := ""
+	for p.peek() != "" {
+		switch keyword := strings.ToUpper(p.peek()); keyword {
+		case "SET", "ADD", "REMOVE":
+			kind = keyword
+			p.next()
+			continue
+		}
+		if kind == "" {
+			return nil, fmt.Errorf("update expression must start with SET, ADD or REMOVE: %q", input)
+		}
+
+		path, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		if path.attribute == "" {
+			return nil, fmt.Errorf("expected attribute name in update expression %q", input)
+		}
+		action := updateAction{kind: kind, attribute: path.attribute}
+
+		switch kind {
+		case "SET":
+			if err := p.expect("="); err != nil {
+				return nil, err
+			}
+			if action.value, err = p.parseUpdateValue(); err != nil {
+				return nil, err
+			}
+		case "ADD":
+			if action.value.left, err = p.parseOperand(); err != nil {
+				return nil, err
+			}
+		}
+		actions = append(actions, action)
+
+		if p.peek() == "," {
+			p.next()
+		}
+	}
+	return actions, nil
+}
+
+func (p *expressionParser) parseUpdateValue() (updateValue, error) {
+	if strings.EqualFold(p.peek(), "if_not_exists") {
+		p.next()
+		if err := p.expect("("); err != nil {
+			return updateValue{}, err
+		}
+		path, err := p.parseOperand()
+		if err != nil {
+			return updateValue{}, err
+		}
+		if err := p.expect(","); err != nil {
+			return updateValue{}, err
+		}
+		fallback, err := p.parseOperand()
+		if err != nil {
+			return updateValue{}, err
+		}
+		return updateValue{left: path, right: fallback, fallback: true}, p.expect(")")
+	}
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return updateValue{}, err
+	}
+	value := updateValue{left: left}
+	if operator := p.peek(); operator == "+" || operator == "-" {
+		p.next()
+		value.operator = operator
+		if value.right, err = p.parseOperand(); err != nil {
+			return updateValue{}, err
+		}
+	}
+	return value, nil
+}
+
+// applyUpdate mutates item in place and returns the names it touched.
+func applyUpdate(item map[string]types.AttributeValue, actions []updateAction) ([]string, error) {
+	var updated []string
+	for _, action := range actions {
+		switch action.kind {
+		case "SET":
+			value, err := action.value.resolve(item)
+			if err != nil {
+				return nil, err
+			}
+			if value == nil {
+				return nil, fmt.Errorf("SET %s references a missing attribute", action.attribute)
+			}
+			item[action.attribute] = copyValue(value)
+		case "ADD":
+			value := action.value.left.resolve(item)
+			switch existing := item[action.attribute].(type) {
+			case nil:
+				item[action.attribute] = copyValue(value)
+			case *types.AttributeValueMemberSS:
+				addition, ok := value.(*types.AttributeValueMemberSS)
+				if !ok {
+					return nil, fmt.Errorf("ADD %s expects a string set", action.attribute)
+				}
+				item[action.attribute] = &types.AttributeValueMemberSS{Value: unionStrings(existing.Value, addition.Value)}
+			default:
+				sum, err := addNumbers(existing, value, false)
+				if err != nil {
+					return nil, err
+				}
+				item[action.attribute] = sum
+			}
+		case "REMOVE":
+			delete(item, action.attribute)
+		}
+		updated = append(updated, action.attribute)
+	}
+	return updated, nil
+}
+
+func addNumbers(a, b types.AttributeValue, subtract bool) (types.AttributeValue, error) {
+	x, okX := a.(*types.AttributeValueMemberN)
+	y, okY := b.(*types.AttributeValueMemberN)
+	if !okX || !okY {
+		return nil, fmt.Errorf("arithmetic requires number attributes")
+	}
+	left, _, errX := big.ParseFloat(x.Value, 10, 128, big.ToNearestEven)
+	right, _, errY := big.ParseFloat(y.Value, 10, 128, big.ToNearestEven)
+	if errX != nil || errY != nil {
+		return nil, fmt.Errorf("invalid number attribute")
+	}
+	if subtract {
+		right.Neg(right)
+	}
+	return &types.AttributeValueMemberN{Value: new(big.Float).Add(left, right).Text('f', -1)}, nil
+}
+
+func unionStrings(a, b []string) []string {
+	seen := map[string]bool{}
+	var union []string
+	for _, value := range append(append([]string(nil), a...), b...) {
+		if !seen[value] {
+			seen[value] = true
+			union = append(union, value)
+		}
+	}
+	return union
+}
@@ -0,0 +1,42 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Job is a unit of background work persisted until it succeeds or is moved to
+// the dead-letter store.
+type Job struct {
+	Queue         string    `json:"queue" dynamodbav:"queue"`
+	ID            string    `json:"job_id" dynamodbav:"job_id"`
+	Type          string    `json:"job_type" dynamodbav:"job_type"`
+	Payload       string    `json:"payload" dynamodbav:"payload"`
+	Attempts      int       `json:"attempts" dynamodbav:"attempts"`
+	LastError     string    `json:"last_error,omitempty" dynamodbav:"last_error,omitempty"`
+	CreatedAt     time.Time `json:"created_at" dynamodbav:"created_at"`
+	NextAttemptAt int64     `json:"next_attempt_at" dynamodbav:"next_attempt_at"`
+	LeaseOwner    string    `json:"lease_owner,omitempty" dynamodbav:"lease_owner,omitempty"`
+	LeaseUntil    int64     `json:"lease_until,omitempty" dynamodbav:"lease_until,omitempty"`
+}
+
+// Handler processes the JSON payload of a job. Handlers must be idempotent:
+// a job is retried after a failure and may run again after a crash.
+type Handler func(ctx context.Context, payload json.RawMessage) error
+
+// Enqueuer is what request handlers need to hand work to the queue.
+type Enqueuer interface {
+	Enqueue(ctx context.Context, jobType string, payload interface{}) error
+}
+
+// Store persists jobs. Claims are leases, so several instances can share a
+// store and a crashed worker's jobs become due again once its lease expires.
+type Store interface {
+	Save(ctx context.Context, job *Job) error
+	Claim(ctx context.Context, job *Job, owner string, leaseUntil, now time.Time) (bool, error)
+	Due(ctx context.Context, queue string, now time.Time, limit int) ([]*Job, error)
+	Reschedule(ctx context.Context, job *Job) error
+	Complete(ctx context.Context, job *Job) error
+	DeadLetter(ctx context.Context, job *Job) error
+}
@@ -0,0 +1,280 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"sync"
+	"time"
+
+	"mensajesService/components/background"
+	"mensajesService/components/logger"
+	"mensajesService/components/metrics"
+
+	"github.com/google/uuid"
+)
+
+var ErrQueueClosed = errors.New("job queue is shutting down")
+
+// releaseTimeout bounds the write that hands an interrupted job back at
+// shutdown, when the workers' own context is already cancelled.
+const releaseTimeout = 5 * time.Second
+
+type Options struct {
+	Name         string
+	Workers      int
+	MaxAttempts  int
+	BaseBackoff  time.Duration
+	MaxBackoff   time.Duration
+	PollInterval time.Duration
+	Lease        time.Duration
+}
+
+// Queue persists jobs before running them on a pool of workers. A failed job
+// is retried with exponential backoff and moved to the dead-letter store once
+// it has failed MaxAttempts times. Jobs interrupted by a shutdown are handed
+// back without counting the attempt; those interrupted by a crash are picked
+// up again by the poller when their lease expires.
+type Queue struct {
+	store    Store
+	opts     Options
+	owner    string
+	tracker  *background.Tracker
+	handlers map[string]Handler
+	dispatch chan *Job
+	stop     chan struct{}
+
+	mu       sync.Mutex
+	started  bool
+	closed   bool
+	inflight map[string]bool
+}
+
+func NewQueue(store Store, tracker *background.Tracker, opts Options) *Queue {
+	if opts.Workers <= 0 {
+		opts.Workers = 1
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 1
+	}
+	if opts.PollInterval <= 0 {
+		opts.PollInterval = time.Second
+	}
+	if opts.Lease <= 0 {
+		opts.Lease = 5 * time.Minute
+	}
+
+	return &Queue{
+		store:    store,
+		opts:     opts,
+		owner:    uuid.New().String(),
+		tracker:  tracker,
+		handlers: map[string]Handler{},
+		dispatch: make(chan *Job, opts.Workers*16),
+		stop:     make(chan struct{}),
+		inflight: map[string]bool{},
+	}
+}
+
+// Register must be called before Start.
+func (q *Queue) Register(jobType string, handler Handler) {
+	q.handlers[jobType] = handler
+}
+
+// Start launches the workers and the poller on the tracker, which is what
+// waits for them on shutdown.
+func (q *Queue) Start() {
+	q.mu.Lock()
+	q.started = true
+	q.mu.Unlock()
+
+	for i := 0; i < q.opts.Workers; i++ {
+		q.tracker.Go("job_worker", q.work, "queue", q.opts.Name, "worker", i)
+	}
+	q.tracker.Go("job_poller", q.poll, "queue", q.opts.Name)
+}
+
+// Stop tells workers to exit after their current job. Jobs that are not
+// finished stay in the store and are retried on the next start.
+func (q *Queue) Stop() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.closed {
+		return
+	}
+	q.closed = true
+	close(q.stop)
+}
+
+// Enqueue persists the job and hands it straight to a worker when one is free;
+// otherwise the poller picks it up.
+func (q *Queue) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	if _, ok := q.handlers[jobType]; !ok {
+		return fmt.Errorf("no handler registered for job type %q", jobType)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	job := &Job{
+		Queue:         q.opts.Name,
+		ID:            uuid.New().String(),
+		Type:          jobType,
+		Payload:       string(data),
+		CreatedAt:     now,
+		NextAttemptAt: now.UnixMilli(),
+	}
+	if err := q.store.Save(ctx, job); err != nil {
+		return err
+	}
+
+	q.offer(job)
+	return nil
+}
+
+func (q *Queue) offer(job *Job) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.started || q.closed || q.inflight[job.ID] {
+		return
+	}
+
+	select {
+	case q.dispatch <- job:
+		q.inflight[job.ID] = true
+	default:
+	}
+}
+
+func (q *Queue) done(job *Job) {
+	q.mu.Lock()
+	delete(q.inflight, job.ID)
+	q.mu.Unlock()
+}
+
+func (q *Queue) poll(ctx context.Context) error {
+	ticker := time.NewTicker(q.opts.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		jobs, err := q.store.Due(ctx, q.opts.Name, time.Now(), cap(q.dispatch))
+		if err != nil {
+			logger.LogError("Error polling job queue", "error", err, "queue", q.opts.Name)
+		}
+		for _, job := range jobs {
+			q.offer(job)
+		}
+
+		select {
+		case <-q.stop:
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+func (q *Queue) work(ctx context.Context) error {
+	for {
+		select {
+		case <-q.stop:
+			return nil
+		case job := <-q.dispatch:
+			q.process(ctx, job)
+			q.done(job)
+		}
+	}
+}
+
+func (q *Queue) process(ctx context.Context, job *Job) {
+	now := time.Now()
+	claimed, err := q.store.Claim(ctx, job, q.owner, now.Add(q.opts.Lease), now)
+	if err != nil {
+		logger.LogError("Error claiming job", "error", err, "job_id", job.ID, "job_type", job.Type)
+		return
+	}
+	if !claimed {
+		return
+	}
+
+	err = q.run(ctx, job)
+	if err == nil {
+		metrics.PutCountMetric(metrics.MetricJobSuccess, 1)
+		if err := q.store.Complete(ctx, job); err != nil {
+			logger.LogError("Error completing job", "error", err, "job_id", job.ID, "job_type", job.Type)
+		}
+		return
+	}
+	if ctx.Err() != nil {
+		q.release(job, err)
+		return
+	}
+
+	job.Attempts++
+	job.LastError = err.Error()
+
+	if job.Attempts >= q.opts.MaxAttempts {
+		metrics.PutCountMetric(metrics.MetricJobDeadLetter, 1)
+		logger.LogError("Job moved to dead letters", "error", err, "job_id", job.ID, "job_type", job.Type, "attempts", job.Attempts)
+		if err := q.store.DeadLetter(ctx, job); err != nil {
+			logger.LogError("Error moving job to dead letters", "error", err, "job_id", job.ID, "job_type", job.Type)
+		}
+		return
+	}
+
+	backoff := q.backoff(job.Attempts)
+	job.NextAttemptAt = time.Now().Add(backoff).UnixMilli()
+	metrics.PutCountMetric(metrics.MetricJobRetry, 1)
+	logger.LogError("Job failed, retrying", "error", err, "job_id", job.ID, "job_type", job.Type, "attempts", job.Attempts, "backoff_ms", backoff.Milliseconds())
+	if err := q.store.Reschedule(ctx, job); err != nil {
+		logger.LogError("Error rescheduling job", "error", err, "job_id", job.ID, "job_type", job.Type)
+	}
+}
+
+// release hands back a job whose handler was cut short by shutdown. The
+// attempt isn't counted, and the job is due again right away instead of after
+// its lease expires.
+func (q *Queue) release(job *Job, cause error) {
+	ctx, cancel := context.WithTimeout(context.Background(), releaseTimeout)
+	defer cancel()
+
+	job.NextAttemptAt = time.Now().UnixMilli()
+	logger.LogInfo("Job interrupted by shutdown, releasing it", "error", cause, "job_id", job.ID, "job_type", job.Type)
+	if err := q.store.Reschedule(ctx, job); err != nil {
+		logger.LogError("Error releasing job", "error", err, "job_id", job.ID, "job_type", job.Type)
+	}
+}
+
+func (q *Queue) run(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	handler, ok := q.handlers[job.Type]
+	if !ok {
+		return fmt.Errorf("no handler registered for job type %q", job.Type)
+	}
+	return handler(ctx, json.RawMessage(job.Payload))
+}
+
+// backoff doubles from BaseBackoff up to MaxBackoff, with up to 20% jitter so
+// jobs that failed together don't retry together.
+func (q *Queue) backoff(attempts int) time.Duration {
+	delay := q.opts.BaseBackoff
+	for i := 1; i < attempts && delay < q.opts.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if q.opts.MaxBackoff > 0 && delay > q.opts.MaxBackoff {
+		delay = q.opts.MaxBackoff
+	}
+	if delay > 0 {
+		delay += time.Duration(rand.Int63n(int64(delay)/5 + 1))
+	}
+	return delay
+}
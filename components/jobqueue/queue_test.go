@@ -0,0 +1,213 @@
+package jobqueue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"mensajesService/components/background"
+	"mensajesService/components/config"
+	"mensajesService/components/database"
+	"mensajesService/components/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+func newTestQueue(t *testing.T, opts Options) (*Queue, *database.MemoryClient) {
+	cfg := &config.AppConfig{TableJobsName: "jobs", TableDeadLettersName: "dead_letters"}
+	dbClient := database.NewMemoryClient(cfg)
+	tracker := background.NewTracker()
+
+	opts.Name = "test"
+	if opts.PollInterval == 0 {
+		opts.PollInterval = 10 * time.Millisecond
+	}
+	queue := NewQueue(NewDynamoStore(dbClient), tracker, opts)
+	t.Cleanup(func() {
+		queue.Stop()
+		_ = tracker.Shutdown(context.Background())
+	})
+	return queue, dbClient
+}
+
+func countRows(t *testing.T, dbClient *database.MemoryClient, table string) int {
+	result, err := dbClient.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String(table),
+		KeyConditionExpression: aws.String("queue = :queue"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":queue": &types.AttributeValueMemberS{Value: "test"},
+		},
+	})
+	require.NoError(t, err)
+	return len(result.Items)
+}
+
+func TestQueue_RunsJobAndRemovesIt(t *testing.T) {
+	queue, dbClient := newTestQueue(t, Options{Workers: 2, MaxAttempts: 3})
+
+	received := make(chan string, 1)
+	queue.Register("greet", func(ctx context.Context, payload json.RawMessage) error {
+		var name string
+		require.NoError(t, json.Unmarshal(payload, &name))
+		received <- name
+		return nil
+	})
+	queue.Start()
+
+	require.NoError(t, queue.Enqueue(context.Background(), "greet", "ana"))
+
+	select {
+	case name := <-received:
+		assert.Equal(t, "ana", name)
+	case <-time.After(time.Second):
+		t.Fatal("job did not run")
+	}
+	assert.Eventually(t, func() bool { return countRows(t, dbClient, "jobs") == 0 }, time.Second, 5*time.Millisecond)
+}
+
+func TestQueue_RetriesWithBackoff(t *testing.T) {
+	queue, dbClient := newTestQueue(t, Options{Workers: 1, MaxAttempts: 5, BaseBackoff: 5 * time.Millisecond, MaxBackoff: 20 * time.Millisecond})
+
+	var attempts atomic.Int32
+	queue.Register("flaky", func(ctx context.Context, payload json.RawMessage) error {
+		if attempts.Add(1) < 3 {
+			return errors.New("temporary failure")
+		}
+		return nil
+	})
+	queue.Start()
+
+	require.NoError(t, queue.Enqueue(context.Background(), "flaky", nil))
+
+	assert.Eventually(t, func() bool {
+		return attempts.Load() == 3 && countRows(t, dbClient, "jobs") == 0
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, 0, countRows(t, dbClient, "dead_letters"))
+}
+
+func TestQueue_MovesToDeadLettersAfterMaxAttempts(t *testing.T) {
+	queue, dbClient := newTestQueue(t, Options{Workers: 1, MaxAttempts: 2, BaseBackoff: time.Millisecond, MaxBackoff: time.Millisecond})
+
+	var attempts atomic.Int32
+	queue.Register("broken", func(ctx context.Context, payload json.RawMessage) error {
+		attempts.Add(1)
+		panic("boom")
+	})
+	queue.Start()
+
+	require.NoError(t, queue.Enqueue(context.Background(), "broken", nil))
+
+	assert.Eventually(t, func() bool {
+		return countRows(t, dbClient, "dead_letters") == 1 && countRows(t, dbClient, "jobs") == 0
+	}, 2*time.Second, 5*time.Millisecond)
+	assert.Equal(t, int32(2), attempts.Load())
+}
+
+func TestQueue_RecoversPersistedJobsOnStart(t *testing.T) {
+	queue, dbClient := newTestQueue(t, Options{Workers: 1, MaxAttempts: 1})
+
+	ran := make(chan struct{}, 1)
+	queue.Register("pending", func(ctx context.Context, payload json.RawMessage) error {
+		ran <- struct{}{}
+		return nil
+	})
+
+	// Enqueued before the workers exist, as if left over from a previous run.
+	require.NoError(t, queue.Enqueue(context.Background(), "pending", nil))
+	assert.Equal(t, 1, countRows(t, dbClient, "jobs"))
+
+	queue.Start()
+
+	select {
+	case <-ran:
+	case <-time.After(time.Second):
+		t.Fatal("persisted job was not recovered")
+	}
+}
+
+func TestQueue_ReleasesJobInterruptedByShutdown(t *testing.T) {
+	cfg := &config.AppConfig{TableJobsName: "jobs", TableDeadLettersName: "dead_letters"}
+	dbClient := database.NewMemoryClient(cfg)
+	tracker := background.NewTracker()
+	queue := NewQueue(NewDynamoStore(dbClient), tracker, Options{Name: "test", Workers: 1, MaxAttempts: 3, Lease: time.Hour, PollInterval: time.Hour})
+
+	started := make(chan struct{})
+	queue.Register("slow", func(ctx context.Context, payload json.RawMessage) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	queue.Start()
+	require.NoError(t, queue.Enqueue(context.Background(), "slow", nil))
+	<-started
+
+	queue.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Error(t, tracker.Shutdown(ctx))
+
+	store := NewDynamoStore(dbClient)
+	assert.Eventually(t, func() bool {
+		jobs, err := store.Due(context.Background(), "test", time.Now(), 10)
+		return err == nil && len(jobs) == 1 && jobs[0].Attempts == 0 && jobs[0].LeaseOwner == ""
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestQueue_EnqueueUnknownType(t *testing.T) {
+	queue, _ := newTestQueue(t, Options{})
+
+	err := queue.Enqueue(context.Background(), "unknown", nil)
+
+	assert.Error(t, err)
+}
+
+func TestQueue_Backoff(t *testing.T) {
+	queue := NewQueue(nil, background.NewTracker(), Options{BaseBackoff: time.Second, MaxBackoff: 10 * time.Second})
+
+	assert.InDelta(t, float64(time.Second), float64(queue.backoff(1)), float64(200*time.Millisecond))
+	assert.InDelta(t, float64(4*time.Second), float64(queue.backoff(3)), float64(800*time.Millisecond))
+	assert.InDelta(t, float64(10*time.Second), float64(queue.backoff(10)), float64(2*time.Second))
+}
+
+func TestDynamoStore_DueSkipsLeasedAndFutureJobs(t *testing.T) {
+	cfg := &config.AppConfig{TableJobsName: "jobs", TableDeadLettersName: "dead_letters"}
+	store := NewDynamoStore(database.NewMemoryClient(cfg))
+	ctx := context.Background()
+	now := time.Now()
+
+	jobs := []*Job{
+		{Queue: "test", ID: "later", NextAttemptAt: now.Add(time.Minute).UnixMilli()},
+		{Queue: "test", ID: "leased", NextAttemptAt: now.Add(-2 * time.Second).UnixMilli()},
+		{Queue: "test", ID: "due", NextAttemptAt: now.Add(-time.Second).UnixMilli()},
+		{Queue: "other", ID: "elsewhere", NextAttemptAt: now.Add(-time.Second).UnixMilli()},
+	}
+	for _, job := range jobs {
+		require.NoError(t, store.Save(ctx, job))
+	}
+	claimed, err := store.Claim(ctx, jobs[1], "worker", now.Add(time.Minute), now)
+	require.NoError(t, err)
+	require.True(t, claimed)
+
+	due, err := store.Due(ctx, "test", now, 10)
+	require.NoError(t, err)
+	require.Len(t, due, 1)
+	assert.Equal(t, "due", due[0].ID)
+
+	// Once the lease runs out the job is due again.
+	due, err = store.Due(ctx, "test", now.Add(2*time.Minute), 10)
+	require.NoError(t, err)
+	assert.Len(t, due, 3)
+}
@@ -0,0 +1,154 @@
+package jobqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"mensajesService/components/database"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// DynamoStore keeps jobs in the jobs table (hash key queue, range key job_id)
+// through DDBClientInterface, so it works on DynamoDB and on the in-memory backend.
+// Due jobs are read from QueueDueIndex (hash key queue, range key
+// next_attempt_at), so a poll only reads jobs whose time has come.
+type DynamoStore struct {
+	dbClient database.DDBClientInterface
+}
+
+func NewDynamoStore(dbClient database.DDBClientInterface) *DynamoStore {
+	return &DynamoStore{dbClient: dbClient}
+}
+
+func (s *DynamoStore) Save(ctx context.Context, job *Job) error {
+	item, err := attributevalue.MarshalMap(job)
+	if err != nil {
+		return err
+	}
+	return s.dbClient.PutItem(ctx, s.dbClient.GetJobsTableName(), item)
+}
+
+func (s *DynamoStore) Claim(ctx context.Context, job *Job, owner string, leaseUntil, now time.Time) (bool, error) {
+	_, err := s.dbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.dbClient.GetJobsTableName()),
+		Key:                 jobKey(job),
+		UpdateExpression:    aws.String("SET lease_owner = :owner, lease_until = :lease_until, next_attempt_at = :lease_until"),
+		ConditionExpression: aws.String("attribute_exists(job_id) AND (attribute_not_exists(lease_until) OR lease_until < :now)"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":owner":       &types.AttributeValueMemberS{Value: owner},
+			":lease_until": millis(leaseUntil),
+			":now":         millis(now),
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	job.LeaseOwner = owner
+	job.LeaseUntil = leaseUntil.UnixMilli()
+	job.NextAttemptAt = job.LeaseUntil
+	return true, nil
+}
+
+// Due reads jobs up to now from QueueDueIndex, oldest first. Claim moves
+// next_attempt_at to the end of the lease, so leased jobs stay out of the key
+// range until their lease expires; the filter only skips rows leased before
+// that was the case.
+func (s *DynamoStore) Due(ctx context.Context, queue string, now time.Time, limit int) ([]*Job, error) {
+	input := &dynamodb.QueryInput{
+		TableName:                aws.String(s.dbClient.GetJobsTableName()),
+		IndexName:                aws.String("QueueDueIndex"),
+		KeyConditionExpression:   aws.String("#queue = :queue AND next_attempt_at <= :now"),
+		FilterExpression:         aws.String("attribute_not_exists(lease_until) OR lease_until < :now"),
+		ExpressionAttributeNames: map[string]string{"#queue": "queue"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":queue": &types.AttributeValueMemberS{Value: queue},
+			":now":   millis(now),
+		},
+		Limit: aws.Int32(int32(limit)),
+	}
+
+	var jobs []*Job
+	for len(jobs) < limit {
+		result, err := s.dbClient.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+
+		var page []*Job
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, err
+		}
+		jobs = append(jobs, page...)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	if len(jobs) > limit {
+		jobs = jobs[:limit]
+	}
+	return jobs, nil
+}
+
+// Reschedule records the failed attempt and releases the lease.
+func (s *DynamoStore) Reschedule(ctx context.Context, job *Job) error {
+	_, err := s.dbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.dbClient.GetJobsTableName()),
+		Key:                 jobKey(job),
+		UpdateExpression:    aws.String("SET attempts = :attempts, last_error = :last_error, next_attempt_at = :next_attempt_at REMOVE lease_owner, lease_until"),
+		ConditionExpression: aws.String("lease_owner = :owner"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":attempts":        &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", job.Attempts)},
+			":last_error":      &types.AttributeValueMemberS{Value: job.LastError},
+			":next_attempt_at": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", job.NextAttemptAt)},
+			":owner":           &types.AttributeValueMemberS{Value: job.LeaseOwner},
+		},
+	})
+	return err
+}
+
+func (s *DynamoStore) Complete(ctx context.Context, job *Job) error {
+	_, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.dbClient.GetJobsTableName()),
+		Key:       jobKey(job),
+	})
+	return err
+}
+
+func (s *DynamoStore) DeadLetter(ctx context.Context, job *Job) error {
+	job.LeaseOwner = ""
+	job.LeaseUntil = 0
+	item, err := attributevalue.MarshalMap(job)
+	if err != nil {
+		return err
+	}
+	item["failed_at"] = millis(time.Now())
+
+	if err := s.dbClient.PutItem(ctx, s.dbClient.GetDeadLettersTableName(), item); err != nil {
+		return err
+	}
+	return s.Complete(ctx, job)
+}
+
+func jobKey(job *Job) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"queue":  &types.AttributeValueMemberS{Value: job.Queue},
+		"job_id": &types.AttributeValueMemberS{Value: job.ID},
+	}
+}
+
+func millis(t time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", t.UnixMilli())}
+}
@@ -2,6 +2,9 @@ package metrics
 
 import (
 	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -9,58 +12,190 @@ import (
 	cloudwatch "github.com/aws/aws-sdk-go-v2/service/cloudwatch"
 	cloudwatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
 
+	appconfig "mensajesService/components/config"
 	"mensajesService/components/logger"
 )
 
-var cwClient *cloudwatch.Client
+const (
+	cloudWatchNamespace = "MensajesService"
+	// maxDatumsPerRequest is the PutMetricData limit of distinct metrics per call.
+	maxDatumsPerRequest = 1000
+	publishTimeout      = 10 * time.Second
+)
+
+type metricDataPublisher interface {
+	PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error)
+}
 
-func Init(region string) {
+type datapoint struct {
+	name  string
+	unit  cloudwatchTypes.StandardUnit
+	value float64
+}
+
+type aggregateKey struct {
+	name string
+	unit cloudwatchTypes.StandardUnit
+}
+
+// CloudWatchSink never calls CloudWatch on the request path. Datapoints go
+// through a bounded buffer to a background aggregator that folds them into one
+// StatisticSet per metric and interval, then publishes them in batches.
+// When the buffer is full datapoints are dropped rather than blocking.
+type CloudWatchSink struct {
+	publisher  metricDataPublisher
+	interval   time.Duration
+	datapoints chan datapoint
+	stop       chan struct{}
+	done       chan struct{}
+	stopOnce   sync.Once
+	dropped    atomic.Int64
+}
+
+func NewCloudWatchSink(region string, interval time.Duration, bufferSize int) (*CloudWatchSink, error) {
 	awsCfg, err := config.LoadDefaultConfig(context.TODO(), config.WithRegion(region))
 	if err != nil {
-		logger.LogError("Failed to init metrics", "error", err)
-		return
+		return nil, err
 	}
-	cwClient = cloudwatch.NewFromConfig(awsCfg)
+	return newCloudWatchSink(cloudwatch.NewFromConfig(awsCfg), interval, bufferSize), nil
 }
 
-func PutCountMetric(metricName string, value float64) {
-	if cwClient == nil {
-		return
+func newCloudWatchSink(publisher metricDataPublisher, interval time.Duration, bufferSize int) *CloudWatchSink {
+	// Non-positive values would make the ticker or the buffer panic.
+	if interval <= 0 {
+		interval = appconfig.DefaultMetricsFlushInterval
 	}
-	_, err := cwClient.PutMetricData(context.TODO(), &cloudwatch.PutMetricDataInput{
-		Namespace: aws.String("MensajesService"),
-		MetricData: []cloudwatchTypes.MetricDatum{
-			{
-				MetricName: aws.String(metricName),
-				Timestamp:  aws.Time(time.Now()),
-				Value:      aws.Float64(value),
-				Unit:       cloudwatchTypes.StandardUnitCount,
-			},
-		},
-	})
+	if bufferSize <= 0 {
+		bufferSize = appconfig.DefaultMetricsBufferSize
+	}
+	s := &CloudWatchSink{
+		publisher:  publisher,
+		interval:   interval,
+		datapoints: make(chan datapoint, bufferSize),
+		stop:       make(chan struct{}),
+		done:       make(chan struct{}),
+	}
+	go s.run()
+	return s
+}
 
-	if err != nil {
-		logger.LogError("Failed to put metric", "metric", metricName, "error", err)
+func (s *CloudWatchSink) PutCount(metricName string, value float64) {
+	s.enqueue(datapoint{name: metricName, unit: cloudwatchTypes.StandardUnitCount, value: value})
+}
+
+func (s *CloudWatchSink) PutDuration(metricName string, durationMs float64) {
+	s.enqueue(datapoint{name: metricName, unit: cloudwatchTypes.StandardUnitMilliseconds, value: durationMs})
+}
+
+func (s *CloudWatchSink) Handler() http.Handler {
+	return nil
+}
+
+// Shutdown stops the aggregator after draining the buffer and publishing what
+// is left, or gives up when ctx expires.
+func (s *CloudWatchSink) Shutdown(ctx context.Context) error {
+	s.stopOnce.Do(func() { close(s.stop) })
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
 	}
 }
 
-func PutDurationMetric(metricName string, durationMs float64) {
-	if cwClient == nil {
+func (s *CloudWatchSink) enqueue(point datapoint) {
+	select {
+	case s.datapoints <- point:
+	default:
+		if s.dropped.Add(1)%1000 == 1 {
+			logger.LogError("Metrics buffer full, dropping datapoints", "metric", point.name, "dropped_total", s.dropped.Load())
+		}
+	}
+}
+
+func (s *CloudWatchSink) run() {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	aggregates := map[aggregateKey]*cloudwatchTypes.StatisticSet{}
+	periodStart := time.Now()
+
+	for {
+		select {
+		case point := <-s.datapoints:
+			aggregate(aggregates, point)
+		case <-ticker.C:
+			s.flush(aggregates, periodStart)
+			aggregates = map[aggregateKey]*cloudwatchTypes.StatisticSet{}
+			periodStart = time.Now()
+		case <-s.stop:
+			for {
+				select {
+				case point := <-s.datapoints:
+					aggregate(aggregates, point)
+				default:
+					s.flush(aggregates, periodStart)
+					return
+				}
+			}
+		}
+	}
+}
+
+func aggregate(aggregates map[aggregateKey]*cloudwatchTypes.StatisticSet, point datapoint) {
+	key := aggregateKey{name: point.name, unit: point.unit}
+	stats, ok := aggregates[key]
+	if !ok {
+		aggregates[key] = &cloudwatchTypes.StatisticSet{
+			SampleCount: aws.Float64(1),
+			Sum:         aws.Float64(point.value),
+			Minimum:     aws.Float64(point.value),
+			Maximum:     aws.Float64(point.value),
+		}
+		return
+	}
+	*stats.SampleCount++
+	*stats.Sum += point.value
+	if point.value < *stats.Minimum {
+		*stats.Minimum = point.value
+	}
+	if point.value > *stats.Maximum {
+		*stats.Maximum = point.value
+	}
+}
+
+func (s *CloudWatchSink) flush(aggregates map[aggregateKey]*cloudwatchTypes.StatisticSet, timestamp time.Time) {
+	if len(aggregates) == 0 {
 		return
 	}
-	_, err := cwClient.PutMetricData(context.TODO(), &cloudwatch.PutMetricDataInput{
-		Namespace: aws.String("MensajesService"),
-		MetricData: []cloudwatchTypes.MetricDatum{
-			{
-				MetricName: aws.String(metricName),
-				Timestamp:  aws.Time(time.Now()),
-				Value:      aws.Float64(durationMs),
-				Unit:       cloudwatchTypes.StandardUnitMilliseconds,
-			},
-		},
-	})
 
-	if err != nil {
-		logger.LogError("Failed to put duration metric", "metric", metricName, "error", err)
+	data := make([]cloudwatchTypes.MetricDatum, 0, len(aggregates))
+	for key, stats := range aggregates {
+		data = append(data, cloudwatchTypes.MetricDatum{
+			MetricName:      aws.String(key.name),
+			Timestamp:       aws.Time(timestamp),
+			StatisticValues: stats,
+			Unit:            key.unit,
+		})
+	}
+
+	for start := 0; start < len(data); start += maxDatumsPerRequest {
+		end := start + maxDatumsPerRequest
+		if end > len(data) {
+			end = len(data)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), publishTimeout)
+		_, err := s.publisher.PutMetricData(ctx, &cloudwatch.PutMetricDataInput{
+			Namespace:  aws.String(cloudWatchNamespace),
+			MetricData: data[start:end],
+		})
+		cancel()
+
+		if err != nil {
+			logger.LogError("Failed to put metrics", "error", err, "datums", end-start)
+		}
 	}
 }
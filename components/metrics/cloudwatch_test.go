@@ -0,0 +1,116 @@
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"mensajesService/components/config"
+	"mensajesService/components/logger"
+
+	cloudwatch "github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cloudwatchTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePublisher struct {
+	mu     sync.Mutex
+	inputs []*cloudwatch.PutMetricDataInput
+}
+
+func (p *fakePublisher) PutMetricData(ctx context.Context, params *cloudwatch.PutMetricDataInput, optFns ...func(*cloudwatch.Options)) (*cloudwatch.PutMetricDataOutput, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.inputs = append(p.inputs, params)
+	return &cloudwatch.PutMetricDataOutput{}, nil
+}
+
+func (p *fakePublisher) calls() []*cloudwatch.PutMetricDataInput {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return append([]*cloudwatch.PutMetricDataInput(nil), p.inputs...)
+}
+
+func TestCloudWatchSink_AggregatesIntoStatisticSets(t *testing.T) {
+	publisher := &fakePublisher{}
+	sink := newCloudWatchSink(publisher, time.Hour, 100)
+
+	sink.PutCount(MetricMessageSuccess, 1)
+	sink.PutCount(MetricMessageSuccess, 1)
+	sink.PutDuration(MetricMessageDuration, 10)
+	sink.PutDuration(MetricMessageDuration, 30)
+
+	require.NoError(t, sink.Shutdown(context.Background()))
+
+	calls := publisher.calls()
+	require.Len(t, calls, 1)
+	require.Len(t, calls[0].MetricData, 2)
+
+	byName := map[string]cloudwatchTypes.MetricDatum{}
+	for _, datum := range calls[0].MetricData {
+		byName[*datum.MetricName] = datum
+	}
+
+	success := byName[MetricMessageSuccess]
+	assert.Equal(t, cloudwatchTypes.StandardUnitCount, success.Unit)
+	assert.Equal(t, 2.0, *success.StatisticValues.SampleCount)
+	assert.Equal(t, 2.0, *success.StatisticValues.Sum)
+
+	duration := byName[MetricMessageDuration]
+	assert.Equal(t, cloudwatchTypes.StandardUnitMilliseconds, duration.Unit)
+	assert.Equal(t, 40.0, *duration.StatisticValues.Sum)
+	assert.Equal(t, 10.0, *duration.StatisticValues.Minimum)
+	assert.Equal(t, 30.0, *duration.StatisticValues.Maximum)
+}
+
+func TestCloudWatchSink_FlushesInBatches(t *testing.T) {
+	publisher := &fakePublisher{}
+	sink := newCloudWatchSink(publisher, time.Hour, 5000)
+
+	for i := 0; i < 2500; i++ {
+		sink.PutCount(fmt.Sprintf("Metric_%d", i), 1)
+	}
+	require.NoError(t, sink.Shutdown(context.Background()))
+
+	calls := publisher.calls()
+	require.Len(t, calls, 3)
+	assert.Len(t, calls[0].MetricData, maxDatumsPerRequest)
+	assert.Len(t, calls[2].MetricData, 500)
+}
+
+func TestCloudWatchSink_FlushesOnInterval(t *testing.T) {
+	publisher := &fakePublisher{}
+	sink := newCloudWatchSink(publisher, 20*time.Millisecond, 100)
+	defer sink.Shutdown(context.Background())
+
+	sink.PutCount(MetricFollowSuccess, 1)
+
+	assert.Eventually(t, func() bool {
+		return len(publisher.calls()) == 1
+	}, time.Second, 5*time.Millisecond)
+}
+
+func TestCloudWatchSink_DefaultsNonPositiveSettings(t *testing.T) {
+	sink := newCloudWatchSink(&fakePublisher{}, 0, -1)
+	defer sink.Shutdown(context.Background())
+
+	assert.Equal(t, config.DefaultMetricsFlushInterval, sink.interval)
+	assert.Equal(t, config.DefaultMetricsBufferSize, cap(sink.datapoints))
+}
+
+func TestCloudWatchSink_DropsWhenBufferIsFull(t *testing.T) {
+	logger.Init()
+	publisher := &fakePublisher{}
+	sink := &CloudWatchSink{
+		publisher:  publisher,
+		datapoints: make(chan datapoint, 1),
+	}
+
+	sink.PutCount(MetricFollowSuccess, 1)
+	sink.PutCount(MetricFollowSuccess, 1)
+
+	assert.Equal(t, int64(1), sink.dropped.Load())
+}
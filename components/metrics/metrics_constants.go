@@ -6,11 +6,44 @@ const (
 	MetricMessageError    = "Message_Error"
 	MetricMessageDuration = "Message_Duration"
 
+	MetricMessageGetSuccess = "MessageGet_Success"
+	MetricMessageGetError   = "MessageGet_Error"
+
+	MetricThreadSuccess = "Thread_Success"
+	MetricThreadError   = "Thread_Error"
+
+	MetricRepostSuccess = "Repost_Success"
+	MetricRepostError   = "Repost_Error"
+
+	MetricUnrepostSuccess = "Unrepost_Success"
+	MetricUnrepostError   = "Unrepost_Error"
+
+	MetricLikeSuccess = "Like_Success"
+	MetricLikeError   = "Like_Error"
+
+	MetricUnlikeSuccess = "Unlike_Success"
+	MetricUnlikeError   = "Unlike_Error"
+
+	MetricUserLikesSuccess = "UserLikes_Success"
+	MetricUserLikesError   = "UserLikes_Error"
+
+	MetricHashtagSuccess = "Hashtag_Success"
+	MetricHashtagError   = "Hashtag_Error"
+
+	MetricTrendsSuccess = "Trends_Success"
+	MetricTrendsError   = "Trends_Error"
+
+	MetricMessageDeleteSuccess = "MessageDelete_Success"
+	MetricMessageDeleteError   = "MessageDelete_Error"
+
 	MetricTimelineSuccess  = "Timeline_Success"
 	MetricTimelineError    = "Timeline_Error"
 	MetricTimelineDuration = "Timeline_Duration"
 	MetricTimelineCount    = "Timeline_Count"
 
+	MetricMentionsSuccess = "Mentions_Success"
+	MetricMentionsError   = "Mentions_Error"
+
 	MetricUserMessagesSuccess  = "UserMessages_Success"
 	MetricUserMessagesError    = "UserMessages_Error"
 	MetricUserMessagesDuration = "UserMessages_Duration"
@@ -19,4 +52,32 @@ const (
 	MetricFollowSuccess  = "Follow_Success"
 	MetricFollowError    = "Follow_Error"
 	MetricFollowDuration = "Follow_Duration"
+
+	MetricUnfollowSuccess = "Unfollow_Success"
+	MetricUnfollowError   = "Unfollow_Error"
+
+	MetricBlockSuccess = "Block_Success"
+	MetricBlockError   = "Block_Error"
+
+	MetricMuteSuccess = "Mute_Success"
+	MetricMuteError   = "Mute_Error"
+
+	MetricFollowRequestSuccess = "FollowRequest_Success"
+	MetricFollowRequestError   = "FollowRequest_Error"
+
+	MetricSettingsSuccess = "Settings_Success"
+	MetricSettingsError   = "Settings_Error"
+
+	MetricUserSuccess = "User_Success"
+	MetricUserError   = "User_Error"
+
+	MetricFollowListSuccess = "FollowList_Success"
+	MetricFollowListError   = "FollowList_Error"
+
+	MetricFanoutEnqueueError   = "FanoutEnqueue_Error"
+	MetricFanoutFollowersCount = "FanoutFollowers_Count"
+
+	MetricJobSuccess    = "Job_Success"
+	MetricJobRetry      = "Job_Retry"
+	MetricJobDeadLetter = "Job_DeadLetter"
 )
@@ -0,0 +1,106 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const prometheusNamespace = "mensajes"
+
+// PrometheusSink keeps the metrics in process for scraping on /metrics.
+// "*_Duration" metrics become latency histograms in seconds, "*_Count" metrics
+// (items per response) become histograms and everything else a counter.
+type PrometheusSink struct {
+	registry   *prometheus.Registry
+	mu         sync.Mutex
+	counters   map[string]prometheus.Counter
+	histograms map[string]prometheus.Histogram
+}
+
+func NewPrometheusSink() *PrometheusSink {
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+
+	return &PrometheusSink{
+		registry:   registry,
+		counters:   map[string]prometheus.Counter{},
+		histograms: map[string]prometheus.Histogram{},
+	}
+}
+
+func (s *PrometheusSink) PutCount(metricName string, value float64) {
+	if strings.HasSuffix(metricName, "_Count") {
+		s.histogram(metricName, "", prometheus.ExponentialBuckets(1, 2, 10)).Observe(value)
+		return
+	}
+	s.counter(metricName).Add(value)
+}
+
+func (s *PrometheusSink) PutDuration(metricName string, durationMs float64) {
+	s.histogram(metricName, "_seconds", prometheus.DefBuckets).Observe(durationMs / 1000)
+}
+
+func (s *PrometheusSink) Handler() http.Handler {
+	return promhttp.HandlerFor(s.registry, promhttp.HandlerOpts{})
+}
+
+func (s *PrometheusSink) Shutdown(ctx context.Context) error {
+	return nil
+}
+
+func (s *PrometheusSink) counter(metricName string) prometheus.Counter {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	counter, ok := s.counters[metricName]
+	if !ok {
+		counter = prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: prometheusNamespace,
+			Name:      prometheusName(metricName) + "_total",
+			Help:      metricName + " count.",
+		})
+		s.registry.MustRegister(counter)
+		s.counters[metricName] = counter
+	}
+	return counter
+}
+
+func (s *PrometheusSink) histogram(metricName, suffix string, buckets []float64) prometheus.Histogram {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	histogram, ok := s.histograms[metricName]
+	if !ok {
+		histogram = prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: prometheusNamespace,
+			Name:      prometheusName(metricName) + suffix,
+			Help:      metricName + " distribution.",
+			Buckets:   buckets,
+		})
+		s.registry.MustRegister(histogram)
+		s.histograms[metricName] = histogram
+	}
+	return histogram
+}
+
+// prometheusName turns "UserMessages_Duration" into "user_messages_duration".
+func prometheusName(metricName string) string {
+	var b strings.Builder
+	runes := []rune(metricName)
+	for i, r := range runes {
+		if r >= 'A' && r <= 'Z' {
+			if i > 0 && runes[i-1] != '_' && !(runes[i-1] >= 'A' && runes[i-1] <= 'Z') {
+				b.WriteByte('_')
+			}
+			r += 'a' - 'A'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
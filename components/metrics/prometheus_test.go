@@ -0,0 +1,31 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrometheusName(t *testing.T) {
+	assert.Equal(t, "user_messages_duration", prometheusName(MetricUserMessagesDuration))
+	assert.Equal(t, "message_created", prometheusName(MetricMessageCreated))
+}
+
+func TestPrometheusSink_Exposition(t *testing.T) {
+	sink := NewPrometheusSink()
+
+	sink.PutCount(MetricMessageSuccess, 1)
+	sink.PutCount(MetricMessageSuccess, 2)
+	sink.PutCount(MetricTimelineCount, 5)
+	sink.PutDuration(MetricTimelineDuration, 250)
+
+	response := httptest.NewRecorder()
+	sink.Handler().ServeHTTP(response, httptest.NewRequest("GET", "/metrics", nil))
+	body := response.Body.String()
+
+	assert.Contains(t, body, "mensajes_message_success_total 3")
+	assert.Contains(t, body, "mensajes_timeline_count_count 1")
+	assert.Contains(t, body, "mensajes_timeline_count_sum 5")
+	assert.Contains(t, body, "mensajes_timeline_duration_seconds_sum 0.25")
+}
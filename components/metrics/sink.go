@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+
+	"mensajesService/components/config"
+	"mensajesService/components/logger"
+)
+
+// MetricsSink is where PutCountMetric and PutDurationMetric end up.
+type MetricsSink interface {
+	PutCount(metricName string, value float64)
+	PutDuration(metricName string, durationMs float64)
+	// Handler exposes the metrics over HTTP, or returns nil for push based sinks.
+	Handler() http.Handler
+	// Shutdown publishes anything still buffered.
+	Shutdown(ctx context.Context) error
+}
+
+var sink MetricsSink = noopSink{}
+
+func Init(cfg *config.AppConfig) {
+	switch cfg.MetricsSink {
+	case config.MetricsSinkPrometheus:
+		sink = NewPrometheusSink()
+	case config.MetricsSinkNone:
+		sink = noopSink{}
+	default:
+		cloudWatch, err := NewCloudWatchSink(cfg.Region, cfg.MetricsFlushInterval, cfg.MetricsBufferSize)
+		if err != nil {
+			logger.LogError("Failed to init metrics", "error", err)
+			sink = noopSink{}
+			return
+		}
+		sink = cloudWatch
+	}
+}
+
+func SetSink(s MetricsSink) {
+	sink = s
+}
+
+func PutCountMetric(metricName string, value float64) {
+	sink.PutCount(metricName, value)
+}
+
+func PutDurationMetric(metricName string, durationMs float64) {
+	sink.PutDuration(metricName, durationMs)
+}
+
+func Handler() http.Handler {
+	return sink.Handler()
+}
+
+func Shutdown(ctx context.Context) error {
+	return sink.Shutdown(ctx)
+}
+
+type noopSink struct{}
+
+func (noopSink) PutCount(string, float64)    {}
+func (noopSink) PutDuration(string, float64) {}
+func (noopSink) Handler() http.Handler       { return nil }
+func (noopSink) Shutdown(context.Context) error {
+	return nil
+}
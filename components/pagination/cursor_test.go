@@ -0,0 +1,56 @@
+package pagination
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCursorCodec_RoundTrip(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	key := map[string]types.AttributeValue{
+		"user_id":   &types.AttributeValueMemberS{Value: "user123"},
+		"timestamp": &types.AttributeValueMemberN{Value: "1700000000"},
+	}
+
+	cursor, err := codec.EncodeKey("timeline:user123", key)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, cursor)
+
+	decoded, err := codec.DecodeKey("timeline:user123", cursor)
+	assert.NoError(t, err)
+	assert.Equal(t, key, decoded)
+}
+
+func TestCursorCodec_EmptyKey(t *testing.T) {
+	codec := NewCursorCodec("secret")
+
+	cursor, err := codec.EncodeKey("timeline:user123", nil)
+	assert.NoError(t, err)
+	assert.Empty(t, cursor)
+
+	decoded, err := codec.DecodeKey("timeline:user123", "")
+	assert.NoError(t, err)
+	assert.Nil(t, decoded)
+}
+
+func TestCursorCodec_Tampered(t *testing.T) {
+	codec := NewCursorCodec("secret")
+	key := map[string]types.AttributeValue{
+		"user_id": &types.AttributeValueMemberS{Value: "user123"},
+	}
+	cursor, _ := codec.EncodeKey("messages:user123", key)
+
+	_, err := codec.DecodeKey("messages:user123", "x"+cursor)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = codec.DecodeKey("messages:user456", cursor)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = NewCursorCodec("other").DecodeKey("messages:user123", cursor)
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+
+	_, err = codec.DecodeKey("messages:user123", "garbage")
+	assert.ErrorIs(t, err, ErrInvalidCursor)
+}
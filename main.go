@@ -2,46 +2,167 @@ package main
 
 import (
 	"context"
+	"errors"
+	"mensajesService/components/background"
 	"mensajesService/components/config"
 	"mensajesService/components/database"
+	"mensajesService/components/jobqueue"
 	"mensajesService/components/logger"
 	"mensajesService/components/metrics"
+	"mensajesService/components/pagination"
 	"mensajesService/message-api/controller"
 	"mensajesService/message-api/service"
 	"mensajesService/message-api/web"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/go-chi/chi/v5"
 )
 
 func main() {
 	logger.Init()
 	ctx := context.Background()
 	cfg := config.LoadConfig()
-	metrics.Init(cfg.Region)
+	if err := cfg.Validate(); err != nil {
+		logger.LogError("Invalid configuration", "error", err)
+		os.Exit(1)
+	}
+	metrics.Init(cfg)
 
-	dbClient, err := database.NewDDBClient(ctx, cfg)
+	dbClient, err := database.NewClient(ctx, cfg)
 	if err != nil {
 		logger.LogError("Error initializing database", "error", err)
 		os.Exit(1)
 	}
+	logger.LogInfo("Storage backend initialized", "backend", cfg.StorageBackend)
+
+	if cfg.CursorSecret == "" {
+		// Only allowed with the memory backend, whose data doesn't survive a
+		// restart either.
+		logger.LogInfo("CURSOR_SECRET not set, pagination cursors will not survive a restart")
+	}
+
+	authenticator, err := web.NewAuthenticator(cfg)
+	if err != nil {
+		logger.LogError("Error initializing authentication", "error", err)
+		os.Exit(1)
+	}
+	logger.LogInfo("Authentication initialized", "mode", cfg.AuthMode)
+
+	tracker := background.NewTracker()
+	queue := newFanoutQueue(cfg, dbClient, tracker)
+	scheduler := background.NewScheduler(tracker)
+	router := newRouter(cfg, dbClient, authenticator, queue, scheduler)
+	queue.Start()
+	scheduler.Start()
+
+	server := &http.Server{
+		Addr:    ":" + cfg.Port,
+		Handler: router,
+	}
+
+	serverErrors := make(chan error, 1)
+	go func() {
+		logger.LogInfo("Service started on port: " + cfg.Port)
+		serverErrors <- server.ListenAndServe()
+	}()
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	select {
+	case err := <-serverErrors:
+		if !errors.Is(err, http.ErrServerClosed) {
+			logger.LogError("Error starting service: ", "error", err)
+		}
+	case sig := <-signals:
+		logger.LogInfo("Shutdown signal received", "signal", sig.String())
+	}
 
-	messageService := service.NewMessageService(dbClient)
-	timelineService := service.NewTimelineService(dbClient)
-	followService := service.NewFollowService(dbClient, messageService, timelineService)
+	shutdown(server, queue, scheduler, tracker, cfg)
+}
+
+// shutdown stops accepting requests, lets in-flight ones finish, waits for
+// fan-out jobs and scheduled tasks and flushes metrics, all within
+// SHUTDOWN_TIMEOUT. Jobs that don't finish stay in the queue and are retried
+// after the next start.
+func shutdown(server *http.Server, queue *jobqueue.Queue, scheduler *background.Scheduler, tracker *background.Tracker, cfg *config.AppConfig) {
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		logger.LogError("Error shutting down HTTP server", "error", err)
+	}
+
+	queue.Stop()
+	scheduler.Stop()
+	if err := tracker.Shutdown(ctx); err != nil {
+		logger.LogError("Background jobs did not finish before the shutdown deadline", "error", err)
+	}
+
+	if err := metrics.Shutdown(ctx); err != nil {
+		logger.LogError("Error flushing metrics", "error", err)
+	}
 
-	messageController := controller.NewMessageController(messageService, timelineService, cfg)
+	logger.LogInfo("Service stopped")
+}
+
+func newFanoutQueue(cfg *config.AppConfig, dbClient database.DDBClientInterface, tracker *background.Tracker) *jobqueue.Queue {
+	return jobqueue.NewQueue(jobqueue.NewDynamoStore(dbClient), tracker, jobqueue.Options{
+		Name:         "fanout",
+		Workers:      cfg.FanoutWorkers,
+		MaxAttempts:  cfg.FanoutMaxAttempts,
+		BaseBackoff:  cfg.FanoutBackoffBase,
+		MaxBackoff:   cfg.FanoutBackoffMax,
+		PollInterval: cfg.FanoutPollInterval,
+		Lease:        cfg.FanoutLease,
+	})
+}
+
+// newRouter wires services and controllers and registers the fan-out job
+// handlers on queue and the periodic tasks on scheduler, which must both be
+// started afterwards.
+func newRouter(cfg *config.AppConfig, dbClient database.DDBClientInterface, authenticator *web.Authenticator, queue *jobqueue.Queue, scheduler *background.Scheduler) chi.Router {
+	cursors := pagination.NewCursorCodec(cfg.CursorSecret)
+
+	messageService := service.NewMessageService(dbClient, cursors)
+	heavyAuthors := service.NewHeavyAuthors(dbClient, cfg.HeavyAuthorThreshold)
+	timelineService := service.NewTimelineService(dbClient, cursors, heavyAuthors, cfg.ReplyFanoutPolicy)
+	followService := service.NewFollowService(dbClient, messageService, timelineService, cursors, queue, heavyAuthors, service.BackfillDepth{
+		MaxMessages: cfg.BackfillMaxMessages,
+		Window:      cfg.BackfillWindow,
+	})
+	userService := service.NewUserService(dbClient)
+	trendsService := service.NewTrendsService(dbClient, service.TrendOptions{
+		Windows:         cfg.TrendWindows,
+		Bucket:          cfg.TrendBucket,
+		RefreshInterval: cfg.TrendRefreshInterval,
+		TopN:            cfg.TrendTopN,
+	})
+	messageService.RegisterJobs(queue)
+	timelineService.RegisterJobs(queue)
+	followService.RegisterJobs(queue)
+	trendsService.RegisterJobs(queue)
+	trendsService.RegisterTasks(scheduler)
+
+	messageController := controller.NewMessageController(messageService, queue, cfg)
 	followController := controller.NewFollowController(followService, cfg)
 	timelineController := controller.NewTimelineController(timelineService, cfg)
+	trendsController := controller.NewTrendsController(trendsService, cfg)
+	userController := controller.NewUserController(userService, cfg)
 
-	router := web.NewHttpHandler("v1")
+	router := web.NewHttpHandler("v1", authenticator)
+	if handler := metrics.Handler(); handler != nil {
+		router.Handle("/metrics", handler)
+	}
 
 	messageController.MountIn(router)
 	followController.MountIn(router)
 	timelineController.MountIn(router)
+	trendsController.MountIn(router)
+	userController.MountIn(router)
 
-	port := cfg.Port
-	logger.LogInfo("Service started on port: " + port)
-	if err := http.ListenAndServe(":"+port, router); err != nil {
-		logger.LogError("Error starting service: ", "error", err)
-	}
+	return router
 }
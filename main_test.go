@@ -0,0 +1,534 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"mensajesService/components/background"
+	"mensajesService/components/config"
+	"mensajesService/components/database"
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+	"mensajesService/message-api/web"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMain(m *testing.M) {
+	logger.Init()
+	os.Exit(m.Run())
+}
+
+func newTestServer(t *testing.T) chi.Router {
+	t.Setenv("STORAGE_BACKEND", config.StorageBackendMemory)
+	cfg := config.LoadConfig()
+	dbClient := database.NewMemoryClient(cfg)
+	tracker := background.NewTracker()
+	queue := newFanoutQueue(cfg, dbClient, tracker)
+	scheduler := background.NewScheduler(tracker)
+	router := newRouter(cfg, dbClient, web.NewHeaderAuthenticator(), queue, scheduler)
+	queue.Start()
+	scheduler.Start()
+	t.Cleanup(func() {
+		queue.Stop()
+		scheduler.Stop()
+		_ = tracker.Shutdown(context.Background())
+	})
+	return router
+}
+
+func doRequest(router chi.Router, method, path, userID string, body interface{}) *httptest.ResponseRecorder {
+	var payload bytes.Buffer
+	if body != nil {
+		_ = json.NewEncoder(&payload).Encode(body)
+	}
+	req := httptest.NewRequest(method, path, &payload)
+	if userID != "" {
+		req.Header.Set("X-User-ID", userID)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	response := httptest.NewRecorder()
+	router.ServeHTTP(response, req)
+	return response
+}
+
+func TestEndToEnd_FollowPostAndReadTimeline(t *testing.T) {
+	router := newTestServer(t)
+
+	response := doRequest(router, "POST", "/follow", "alice", model.FollowRequest{FollowingID: "bob"})
+	require.Equal(t, http.StatusCreated, response.Code)
+
+	response = doRequest(router, "POST", "/message", "bob", map[string]string{"content": "hello from bob"})
+	require.Equal(t, http.StatusCreated, response.Code)
+
+	var created model.Message
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &created))
+
+	assert.Eventually(t, func() bool {
+		return doRequest(router, "GET", "/timeline", "alice", nil).Code == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	response = doRequest(router, "GET", "/timeline", "alice", nil)
+	var timeline model.Page[*model.TimelineItem]
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &timeline))
+	require.Len(t, timeline.Items, 1)
+	assert.Equal(t, created.ID, timeline.Items[0].MessageID)
+	assert.Equal(t, "bob", timeline.Items[0].AuthorID)
+
+	response = doRequest(router, "GET", "/message/"+created.ID, "", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	var permalink model.Message
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &permalink))
+	assert.Equal(t, "bob", permalink.UserID)
+
+	response = doRequest(router, "GET", "/message", "bob", nil)
+	var messages model.Page[*model.Message]
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &messages))
+	require.Len(t, messages.Items, 1)
+	assert.Equal(t, "hello from bob", messages.Items[0].Content)
+}
+
+func TestEndToEnd_PaginateMessages(t *testing.T) {
+	router := newTestServer(t)
+
+	for i := 0; i < 5; i++ {
+		response := doRequest(router, "POST", "/message", "bob", map[string]string{"content": "message"})
+		require.Equal(t, http.StatusCreated, response.Code)
+	}
+
+	seen := map[string]bool{}
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		response := doRequest(router, "GET", "/message?limit=2&cursor="+cursor, "bob", nil)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var page model.Page[*model.Message]
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+		for _, message := range page.Items {
+			seen[message.ID] = true
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Len(t, seen, 5)
+}
+
+func TestEndToEnd_DeleteMessageRetractsFromTimelines(t *testing.T) {
+	router := newTestServer(t)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", "alice", model.FollowRequest{FollowingID: "bob"}).Code)
+
+	response := doRequest(router, "POST", "/message", "bob", map[string]string{"content": "soon gone"})
+	require.Equal(t, http.StatusCreated, response.Code)
+	var created model.Message
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &created))
+
+	require.Eventually(t, func() bool {
+		return doRequest(router, "GET", "/timeline", "alice", nil).Code == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(router, "DELETE", "/message/"+created.ID, "alice", nil).Code)
+	require.Equal(t, http.StatusOK, doRequest(router, "DELETE", "/message/"+created.ID, "bob", nil).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, "GET", "/message/"+created.ID, "", nil).Code)
+
+	assert.Eventually(t, func() bool {
+		return doRequest(router, "GET", "/timeline", "alice", nil).Code == http.StatusNotFound
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", "carol", model.FollowRequest{FollowingID: "bob"}).Code)
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, "GET", "/timeline", "carol", nil).Code)
+}
+
+func TestEndToEnd_UnfollowPurgesTimeline(t *testing.T) {
+	router := newTestServer(t)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", "alice", model.FollowRequest{FollowingID: "bob"}).Code)
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/message", "bob", map[string]string{"content": "hi"}).Code)
+	require.Eventually(t, func() bool {
+		return doRequest(router, "GET", "/timeline", "alice", nil).Code == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	response := doRequest(router, "DELETE", "/follow/bob", "alice", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"existed":true`)
+
+	assert.Eventually(t, func() bool {
+		return doRequest(router, "GET", "/timeline", "alice", nil).Code == http.StatusNotFound
+	}, time.Second, 10*time.Millisecond)
+
+	response = doRequest(router, "DELETE", "/follow/bob", "alice", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"existed":false`)
+}
+
+func TestEndToEnd_FollowListsAndCounts(t *testing.T) {
+	router := newTestServer(t)
+
+	for _, follower := range []string{"alice", "bob", "carol"} {
+		require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", follower, model.FollowRequest{FollowingID: "dave"}).Code)
+	}
+	// Following twice must not count twice.
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", "alice", model.FollowRequest{FollowingID: "dave"}).Code)
+	require.Equal(t, http.StatusOK, doRequest(router, "DELETE", "/follow/dave", "bob", nil).Code)
+
+	var counts model.FollowCounts
+	require.NoError(t, json.Unmarshal(doRequest(router, "GET", "/users/dave/follow-counts", "", nil).Body.Bytes(), &counts))
+	assert.Equal(t, int64(2), counts.FollowersCount)
+	assert.Equal(t, int64(0), counts.FollowingCount)
+
+	require.NoError(t, json.Unmarshal(doRequest(router, "GET", "/users/alice/follow-counts", "", nil).Body.Bytes(), &counts))
+	assert.Equal(t, int64(1), counts.FollowingCount)
+
+	var followers model.Page[*model.Follow]
+	require.NoError(t, json.Unmarshal(doRequest(router, "GET", "/users/dave/followers?limit=1", "", nil).Body.Bytes(), &followers))
+	require.Len(t, followers.Items, 1)
+	assert.Equal(t, "alice", followers.Items[0].FollowerID)
+	require.NotEmpty(t, followers.NextCursor)
+
+	require.NoError(t, json.Unmarshal(doRequest(router, "GET", "/users/dave/followers?limit=1&cursor="+followers.NextCursor, "", nil).Body.Bytes(), &followers))
+	require.Len(t, followers.Items, 1)
+	assert.Equal(t, "carol", followers.Items[0].FollowerID)
+
+	var following model.Page[*model.Follow]
+	require.NoError(t, json.Unmarshal(doRequest(router, "GET", "/users/carol/following", "", nil).Body.Bytes(), &following))
+	require.Len(t, following.Items, 1)
+	assert.Equal(t, "dave", following.Items[0].FollowingID)
+}
+
+func TestEndToEnd_HeavyAuthorMergedAtRead(t *testing.T) {
+	t.Setenv("FANOUT_HEAVY_THRESHOLD", "2")
+	router := newTestServer(t)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", "bob", model.FollowRequest{FollowingID: "alice"}).Code)
+
+	// Posted while alice is still below the threshold, so it is fanned out.
+	response := doRequest(router, "POST", "/message", "alice", map[string]string{"content": "before"})
+	require.Equal(t, http.StatusCreated, response.Code)
+	assert.Eventually(t, func() bool {
+		return doRequest(router, "GET", "/timeline", "bob", nil).Code == http.StatusOK
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", "carol", model.FollowRequest{FollowingID: "alice"}).Code)
+
+	var posted []string
+	for i := 0; i < 3; i++ {
+		response := doRequest(router, "POST", "/message", "alice", map[string]string{"content": "after"})
+		require.Equal(t, http.StatusCreated, response.Code)
+		var created model.Message
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &created))
+		posted = append(posted, created.ID)
+	}
+
+	var contents []string
+	var ids []string
+	cursor := ""
+	for pages := 0; pages < 10; pages++ {
+		response := doRequest(router, "GET", "/timeline?limit=2&cursor="+cursor, "bob", nil)
+		require.Equal(t, http.StatusOK, response.Code)
+
+		var page model.Page[*model.TimelineItem]
+		require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+		for _, item := range page.Items {
+			contents = append(contents, item.Content)
+			ids = append(ids, item.MessageID)
+		}
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+
+	assert.Equal(t, []string{"after", "after", "after", "before"}, contents)
+	assert.Equal(t, []string{posted[2], posted[1], posted[0]}, ids[:3])
+
+	// Heavy authors are only merged into their followers' timelines.
+	assert.Equal(t, http.StatusNotFound, doRequest(router, "GET", "/timeline", "dave", nil).Code)
+}
+
+func TestEndToEnd_ReadAnotherUsersMessages(t *testing.T) {
+	router := newTestServer(t)
+
+	for i := 0; i < 3; i++ {
+		require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/message", "bob", map[string]string{"content": "message"}).Code)
+	}
+
+	response := doRequest(router, "GET", "/users/bob/messages?limit=2", "carol", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	var page model.Page[*model.Message]
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	require.Len(t, page.Items, 2)
+	assert.NotEmpty(t, page.NextCursor)
+
+	// Everything older than the newest message.
+	before := url.QueryEscape(page.Items[0].CreatedAt.Format(time.RFC3339Nano))
+	response = doRequest(router, "GET", "/users/bob/messages?before="+before, "carol", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 2)
+}
+
+func TestEndToEnd_RepliesAndThread(t *testing.T) {
+	router := newTestServer(t)
+
+	response := doRequest(router, "POST", "/message", "alice", map[string]string{"content": "root"})
+	require.Equal(t, http.StatusCreated, response.Code)
+	var root model.Message
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &root))
+
+	response = doRequest(router, "POST", "/message", "bob", model.CreateMessageRequest{Content: "reply", InReplyTo: root.ID})
+	require.Equal(t, http.StatusCreated, response.Code)
+	var reply model.Message
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &reply))
+	assert.Equal(t, root.ID, reply.ConversationID)
+	assert.Equal(t, "alice", reply.InReplyToUserID)
+
+	response = doRequest(router, "POST", "/message", "bob", model.CreateMessageRequest{Content: "reply", InReplyTo: "missing"})
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+
+	response = doRequest(router, "GET", "/message/"+reply.ID+"/thread", "", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	var thread model.Thread
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &thread))
+	assert.Equal(t, root.ID, thread.ConversationID)
+	require.Len(t, thread.Ancestors, 1)
+	assert.Equal(t, root.ID, thread.Ancestors[0].ID)
+
+	response = doRequest(router, "GET", "/message/"+root.ID+"/thread?depth=0", "", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &thread))
+	assert.Empty(t, thread.Root.Replies)
+	assert.True(t, thread.Root.MoreReplies)
+}
+
+func TestEndToEnd_RepostEmbedsOriginal(t *testing.T) {
+	router := newTestServer(t)
+
+	response := doRequest(router, "POST", "/message", "alice", map[string]string{"content": "original"})
+	require.Equal(t, http.StatusCreated, response.Code)
+	var original model.Message
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &original))
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", "carol", model.FollowRequest{FollowingID: "bob"}).Code)
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/message/"+original.ID+"/repost", "bob", nil).Code)
+	assert.Equal(t, http.StatusConflict, doRequest(router, "POST", "/message/"+original.ID+"/repost", "bob", nil).Code)
+
+	var timeline model.Page[*model.TimelineItem]
+	require.Eventually(t, func() bool {
+		response := doRequest(router, "GET", "/timeline", "carol", nil)
+		return json.Unmarshal(response.Body.Bytes(), &timeline) == nil && len(timeline.Items) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, original.ID, timeline.Items[0].RepostOf)
+	require.NotNil(t, timeline.Items[0].Original)
+	assert.Equal(t, "original", timeline.Items[0].Original.Content)
+
+	require.Equal(t, http.StatusOK, doRequest(router, "DELETE", "/message/"+original.ID+"/repost", "bob", nil).Code)
+	assert.Eventually(t, func() bool {
+		return doRequest(router, "GET", "/timeline", "carol", nil).Code == http.StatusNotFound
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestEndToEnd_LikeCounter(t *testing.T) {
+	router := newTestServer(t)
+
+	response := doRequest(router, "POST", "/message", "alice", map[string]string{"content": "like me"})
+	require.Equal(t, http.StatusCreated, response.Code)
+	var message model.Message
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &message))
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/message/"+message.ID+"/like", "bob", nil).Code)
+	require.Equal(t, http.StatusOK, doRequest(router, "POST", "/message/"+message.ID+"/like", "bob", nil).Code)
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/message/"+message.ID+"/like", "carol", nil).Code)
+
+	assert.Eventually(t, func() bool {
+		response := doRequest(router, "GET", "/message/"+message.ID, "", nil)
+		var current model.Message
+		return json.Unmarshal(response.Body.Bytes(), &current) == nil && current.LikeCount == 2 && current.LikedByMe == nil
+	}, time.Second, 10*time.Millisecond)
+
+	response = doRequest(router, "GET", "/message/"+message.ID, "bob", nil)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &message))
+	require.NotNil(t, message.LikedByMe)
+	assert.True(t, *message.LikedByMe)
+
+	response = doRequest(router, "GET", "/users/bob/likes", "alice", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	var likes model.Page[*model.Message]
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &likes))
+	require.Len(t, likes.Items, 1)
+	assert.Equal(t, message.ID, likes.Items[0].ID)
+
+	require.Equal(t, http.StatusOK, doRequest(router, "DELETE", "/message/"+message.ID+"/like", "bob", nil).Code)
+	assert.Eventually(t, func() bool {
+		response := doRequest(router, "GET", "/message/"+message.ID, "", nil)
+		var current model.Message
+		return json.Unmarshal(response.Body.Bytes(), &current) == nil && current.LikeCount == 1
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestEndToEnd_MentionsFeed(t *testing.T) {
+	router := newTestServer(t)
+
+	response := doRequest(router, "POST", "/message", "alice", map[string]string{"content": "hi @bob"})
+	require.Equal(t, http.StatusCreated, response.Code)
+	var message model.Message
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &message))
+	require.NotNil(t, message.Entities)
+	assert.Equal(t, []model.Mention{{Username: "bob", Start: 3, End: 7}}, message.Entities.Mentions)
+
+	var mentions model.Page[*model.TimelineItem]
+	assert.Eventually(t, func() bool {
+		response := doRequest(router, "GET", "/mentions", "bob", nil)
+		return json.Unmarshal(response.Body.Bytes(), &mentions) == nil && len(mentions.Items) == 1
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, message.ID, mentions.Items[0].MessageID)
+	assert.Equal(t, message.Entities, mentions.Items[0].Entities)
+
+	require.Equal(t, http.StatusOK, doRequest(router, "DELETE", "/message/"+message.ID, "alice", nil).Code)
+	assert.Eventually(t, func() bool {
+		response := doRequest(router, "GET", "/mentions", "bob", nil)
+		return json.Unmarshal(response.Body.Bytes(), &mentions) == nil && len(mentions.Items) == 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestEndToEnd_HashtagFeed(t *testing.T) {
+	router := newTestServer(t)
+
+	response := doRequest(router, "POST", "/message", "alice", map[string]string{"content": "learning #Go"})
+	require.Equal(t, http.StatusCreated, response.Code)
+	var message model.Message
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &message))
+	require.NotNil(t, message.Entities)
+	assert.Equal(t, []model.Hashtag{{Tag: "Go", Start: 9, End: 12}}, message.Entities.Hashtags)
+
+	response = doRequest(router, "GET", "/hashtags/go/messages", "", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	var page model.Page[*model.Message]
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, message.ID, page.Items[0].ID)
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(router, "GET", "/hashtags/123/messages", "", nil).Code)
+}
+
+func TestEndToEnd_Trends(t *testing.T) {
+	t.Setenv("TRENDS_REFRESH_INTERVAL", "10ms")
+	router := newTestServer(t)
+
+	for _, content := range []string{"#go is fun", "more #Go", "#rust"} {
+		require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/message", "alice", map[string]string{"content": content}).Code)
+	}
+
+	var trends model.Trends
+	assert.Eventually(t, func() bool {
+		response := doRequest(router, "GET", "/trends?window=1h", "", nil)
+		return json.Unmarshal(response.Body.Bytes(), &trends) == nil && len(trends.Items) == 2 && trends.Items[0].Count == 2
+	}, time.Second, 10*time.Millisecond)
+	assert.Equal(t, "go", trends.Items[0].Tag)
+	assert.Equal(t, "rust", trends.Items[1].Tag)
+
+	assert.Equal(t, http.StatusBadRequest, doRequest(router, "GET", "/trends?window=2h", "", nil).Code)
+}
+
+func TestEndToEnd_BlockAndMute(t *testing.T) {
+	router := newTestServer(t)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", "alice", model.FollowRequest{FollowingID: "bob"}).Code)
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/follow", "alice", model.FollowRequest{FollowingID: "carol"}).Code)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/blocks/alice", "bob", nil).Code)
+	assert.Equal(t, http.StatusForbidden, doRequest(router, "POST", "/follow", "alice", model.FollowRequest{FollowingID: "bob"}).Code)
+
+	response := doRequest(router, "GET", "/users/bob/follow-counts", "", nil)
+	var counts model.FollowCounts
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &counts))
+	assert.Zero(t, counts.FollowersCount)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/message", "carol", map[string]string{"content": "from carol"}).Code)
+	var timeline model.Page[*model.TimelineItem]
+	assert.Eventually(t, func() bool {
+		response := doRequest(router, "GET", "/timeline", "alice", nil)
+		return json.Unmarshal(response.Body.Bytes(), &timeline) == nil && len(timeline.Items) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/mutes/carol", "alice", nil).Code)
+	assert.Equal(t, http.StatusNotFound, doRequest(router, "GET", "/timeline", "alice", nil).Code)
+
+	require.Equal(t, http.StatusOK, doRequest(router, "DELETE", "/mutes/carol", "alice", nil).Code)
+	assert.Equal(t, http.StatusOK, doRequest(router, "GET", "/timeline", "alice", nil).Code)
+}
+
+func TestEndToEnd_PrivateAccount(t *testing.T) {
+	router := newTestServer(t)
+
+	require.Equal(t, http.StatusOK, doRequest(router, "PUT", "/settings", "alice", map[string]bool{"private": true}).Code)
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/message", "alice", map[string]string{"content": "only for friends"}).Code)
+
+	assert.Equal(t, http.StatusAccepted, doRequest(router, "POST", "/follow", "bob", model.FollowRequest{FollowingID: "alice"}).Code)
+	assert.Equal(t, http.StatusForbidden, doRequest(router, "GET", "/users/alice/messages", "bob", nil).Code)
+
+	var requests model.Page[*model.PendingFollow]
+	require.NoError(t, json.Unmarshal(doRequest(router, "GET", "/follow-requests", "alice", nil).Body.Bytes(), &requests))
+	require.Len(t, requests.Items, 1)
+	assert.Equal(t, "bob", requests.Items[0].RequesterID)
+
+	require.Equal(t, http.StatusOK, doRequest(router, "POST", "/follow-requests/bob/approve", "alice", nil).Code)
+
+	var messages model.Page[*model.Message]
+	response := doRequest(router, "GET", "/users/alice/messages", "bob", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &messages))
+	assert.Len(t, messages.Items, 1)
+
+	// The backfill that follows the approval fills bob's timeline.
+	var timeline model.Page[*model.TimelineItem]
+	assert.Eventually(t, func() bool {
+		response := doRequest(router, "GET", "/timeline", "bob", nil)
+		return json.Unmarshal(response.Body.Bytes(), &timeline) == nil && len(timeline.Items) == 1
+	}, time.Second, 10*time.Millisecond)
+
+	assert.Equal(t, http.StatusForbidden, doRequest(router, "GET", "/users/alice/messages", "carol", nil).Code)
+}
+
+func TestEndToEnd_UserProfiles(t *testing.T) {
+	router := newTestServer(t)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "PUT", "/users/alice", "alice", map[string]string{"handle": "Alice", "display_name": "Alice A."}).Code)
+	assert.Equal(t, http.StatusConflict, doRequest(router, "PUT", "/users/bob", "bob", map[string]string{"handle": "alice"}).Code)
+	assert.Equal(t, http.StatusForbidden, doRequest(router, "PUT", "/users/alice", "bob", map[string]string{"bio": "hacked"}).Code)
+	require.Equal(t, http.StatusOK, doRequest(router, "PUT", "/users/alice", "alice", map[string]string{"bio": "hola"}).Code)
+
+	var user model.User
+	response := doRequest(router, "GET", "/users/by-handle/ALICE", "", nil)
+	require.Equal(t, http.StatusOK, response.Code)
+	require.NoError(t, json.Unmarshal(response.Body.Bytes(), &user))
+	assert.Equal(t, "alice", user.UserID)
+	assert.Equal(t, "hola", user.Bio)
+	assert.Equal(t, "Alice A.", user.DisplayName)
+
+	assert.Equal(t, http.StatusNotFound, doRequest(router, "GET", "/users/bob", "", nil).Code)
+
+	require.Equal(t, http.StatusCreated, doRequest(router, "POST", "/message", "alice", map[string]string{"content": "hello"}).Code)
+	var messages model.Page[*model.Message]
+	require.NoError(t, json.Unmarshal(doRequest(router, "GET", "/users/alice/messages?expand=author", "bob", nil).Body.Bytes(), &messages))
+	require.Len(t, messages.Items, 1)
+	require.NotNil(t, messages.Items[0].Author)
+	assert.Equal(t, "Alice", messages.Items[0].Author.Handle)
+
+	var plain model.Page[*model.Message]
+	require.NoError(t, json.Unmarshal(doRequest(router, "GET", "/users/alice/messages", "bob", nil).Body.Bytes(), &plain))
+	require.Len(t, plain.Items, 1)
+	assert.Nil(t, plain.Items[0].Author)
+}
@@ -1,14 +1,18 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"mensajesService/components/config"
 	"mensajesService/components/logger"
 	"mensajesService/components/metrics"
+	"mensajesService/components/pagination"
 	"mensajesService/message-api/model"
 	"mensajesService/message-api/service"
+	"mensajesService/message-api/web"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -28,15 +32,36 @@ func NewFollowController(followService service.FollowServiceInterface, cfg *conf
 func (c *FollowController) MountIn(r chi.Router) {
 	r.Route("/follow", func(r chi.Router) {
 		r.Post("/", c.FollowUser)
+		r.Delete("/{followingId}", c.UnfollowUser)
+	})
+	r.Get("/users/{id}/followers", c.GetFollowers)
+	r.Get("/users/{id}/following", c.GetFollowing)
+	r.Get("/users/{id}/follow-counts", c.GetFollowCounts)
+	r.Route("/blocks", func(r chi.Router) {
+		r.Post("/{id}", c.BlockUser)
+		r.Delete("/{id}", c.UnblockUser)
+	})
+	r.Route("/mutes", func(r chi.Router) {
+		r.Post("/{id}", c.MuteUser)
+		r.Delete("/{id}", c.UnmuteUser)
+	})
+	r.Route("/follow-requests", func(r chi.Router) {
+		r.Get("/", c.GetFollowRequests)
+		r.Post("/{id}/approve", c.ApproveFollowRequest)
+		r.Post("/{id}/reject", c.RejectFollowRequest)
+	})
+	r.Route("/settings", func(r chi.Router) {
+		r.Get("/", c.GetSettings)
+		r.Put("/", c.UpdateSettings)
 	})
 }
 
 func (c *FollowController) FollowUser(w http.ResponseWriter, r *http.Request) {
-	userID := r.Header.Get("X-User-ID")
+	userID := web.UserIDFromContext(r.Context())
 	if userID == "" {
 		metrics.PutCountMetric(metrics.MetricFollowError, 1)
-		logger.LogError("FollowUser error", "error", "User ID required in X-User-ID header", "user_id", userID)
-		http.Error(w, "User ID required in X-User-ID header", http.StatusBadRequest)
+		logger.LogError("FollowUser error", "error", "User ID required", "user_id", userID)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
 		return
 	}
 
@@ -62,7 +87,12 @@ func (c *FollowController) FollowUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err := c.followService.FollowUser(r.Context(), userID, followRequest.FollowingID)
+	status, err := c.followService.FollowUser(r.Context(), userID, followRequest.FollowingID)
+	if errors.Is(err, service.ErrFollowBlocked) {
+		metrics.PutCountMetric(metrics.MetricFollowError, 1)
+		http.Error(w, "Cannot follow this user", http.StatusForbidden)
+		return
+	}
 	if err != nil {
 		metrics.PutCountMetric(metrics.MetricFollowError, 1)
 		logger.LogError("FollowUser error", "error", err, "user_id", userID, "following_id", followRequest.FollowingID)
@@ -70,11 +100,348 @@ func (c *FollowController) FollowUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// A private account answers with a pending request, which isn't a follow
+	// yet.
+	code, message := http.StatusCreated, "User followed successfully"
+	if status == model.FollowStatusPending {
+		code, message = http.StatusAccepted, "Follow request sent"
+	}
+
 	metrics.PutCountMetric(metrics.MetricFollowSuccess, 1)
-	logger.LogInfo("FollowUser success", "user_id", userID, "following_id", followRequest.FollowingID)
+	logger.LogInfo("FollowUser success", "user_id", userID, "following_id", followRequest.FollowingID, "status", status)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": message,
+		"status":  status,
+	})
+}
+
+func (c *FollowController) UnfollowUser(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricUnfollowError, 1)
+		logger.LogError("UnfollowUser error", "error", "User ID required", "user_id", userID)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	followingID := chi.URLParam(r, "followingId")
+	if userID == followingID {
+		metrics.PutCountMetric(metrics.MetricUnfollowError, 1)
+		logger.LogError("UnfollowUser error", "error", "Cannot unfollow yourself", "user_id", userID)
+		http.Error(w, "Cannot unfollow yourself", http.StatusBadRequest)
+		return
+	}
+
+	existed, err := c.followService.UnfollowUser(r.Context(), userID, followingID)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUnfollowError, 1)
+		logger.LogError("UnfollowUser error", "error", err, "user_id", userID, "following_id", followingID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	message := "User unfollowed successfully"
+	if !existed {
+		message = "User was not followed"
+	}
+
+	metrics.PutCountMetric(metrics.MetricUnfollowSuccess, 1)
+	logger.LogInfo("UnfollowUser success", "user_id", userID, "following_id", followingID, "existed", existed)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": message,
+		"existed": existed,
+	})
+}
+
+func (c *FollowController) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	c.listFollows(w, r, "GetFollowers", c.followService.GetFollowers)
+}
+
+func (c *FollowController) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	c.listFollows(w, r, "GetFollowing", c.followService.GetFollowing)
+}
+
+func (c *FollowController) GetFollowCounts(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+
+	counts, err := c.followService.GetFollowCounts(r.Context(), userID)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricFollowListError, 1)
+		logger.LogError("GetFollowCounts error", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricFollowListSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(counts)
+}
+
+// relationAction describes one of the block and mute endpoints.
+type relationAction struct {
+	operation     string
+	selfError     string
+	doneMessage   string
+	noopMessage   string
+	successMetric string
+	errorMetric   string
+}
+
+var (
+	blockAction = relationAction{
+		operation:     "BlockUser",
+		selfError:     "Cannot block yourself",
+		doneMessage:   "User blocked successfully",
+		noopMessage:   "User was already blocked",
+		successMetric: metrics.MetricBlockSuccess,
+		errorMetric:   metrics.MetricBlockError,
+	}
+	unblockAction = relationAction{
+		operation:     "UnblockUser",
+		selfError:     "Cannot unblock yourself",
+		doneMessage:   "User unblocked successfully",
+		noopMessage:   "User was not blocked",
+		successMetric: metrics.MetricBlockSuccess,
+		errorMetric:   metrics.MetricBlockError,
+	}
+	muteAction = relationAction{
+		operation:     "MuteUser",
+		selfError:     "Cannot mute yourself",
+		doneMessage:   "User muted successfully",
+		noopMessage:   "User was already muted",
+		successMetric: metrics.MetricMuteSuccess,
+		errorMetric:   metrics.MetricMuteError,
+	}
+	unmuteAction = relationAction{
+		operation:     "UnmuteUser",
+		selfError:     "Cannot unmute yourself",
+		doneMessage:   "User unmuted successfully",
+		noopMessage:   "User was not muted",
+		successMetric: metrics.MetricMuteSuccess,
+		errorMetric:   metrics.MetricMuteError,
+	}
+)
+
+// BlockUser responds 201 when the block is new and 200 when it already
+// existed.
+func (c *FollowController) BlockUser(w http.ResponseWriter, r *http.Request) {
+	c.changeRelation(w, r, blockAction, c.followService.BlockUser)
+}
+
+func (c *FollowController) UnblockUser(w http.ResponseWriter, r *http.Request) {
+	c.changeRelation(w, r, unblockAction, c.followService.UnblockUser)
+}
+
+// MuteUser responds 201 when the mute is new and 200 when it already existed.
+func (c *FollowController) MuteUser(w http.ResponseWriter, r *http.Request) {
+	c.changeRelation(w, r, muteAction, c.followService.MuteUser)
+}
+
+func (c *FollowController) UnmuteUser(w http.ResponseWriter, r *http.Request) {
+	c.changeRelation(w, r, unmuteAction, c.followService.UnmuteUser)
+}
+
+// changeRelation runs a block or mute change of the caller towards the user
+// in the path. change reports whether it created or removed something: a new
+// relation is answered with 201, a removal with "existed" as in UnfollowUser.
+func (c *FollowController) changeRelation(w http.ResponseWriter, r *http.Request, action relationAction, change func(ctx context.Context, userID, targetID string) (bool, error)) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(action.errorMetric, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	targetID := chi.URLParam(r, "id")
+	if userID == targetID {
+		metrics.PutCountMetric(action.errorMetric, 1)
+		http.Error(w, action.selfError, http.StatusBadRequest)
+		return
+	}
+
+	changed, err := change(r.Context(), userID, targetID)
+	if err != nil {
+		metrics.PutCountMetric(action.errorMetric, 1)
+		logger.LogError(action.operation+" error", "error", err, "user_id", userID, "target_id", targetID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	message := action.doneMessage
+	if !changed {
+		message = action.noopMessage
+	}
+
+	metrics.PutCountMetric(action.successMetric, 1)
+	logger.LogInfo(action.operation+" success", "user_id", userID, "target_id", targetID, "changed", changed)
 	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(http.StatusCreated)
+	if r.Method == http.MethodDelete {
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"message": message,
+			"existed": changed,
+		})
+		return
+	}
+	if changed {
+		w.WriteHeader(http.StatusCreated)
+	}
 	json.NewEncoder(w).Encode(map[string]string{
-		"message": "User followed successfully",
+		"message": message,
 	})
 }
+
+func (c *FollowController) GetFollowRequests(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricFollowRequestError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r, c.config)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricFollowRequestError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	requests, err := c.followService.GetFollowRequests(r.Context(), userID, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		metrics.PutCountMetric(metrics.MetricFollowRequestError, 1)
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricFollowRequestError, 1)
+		logger.LogError("GetFollowRequests error", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricFollowRequestSuccess, 1)
+	logger.LogInfo("GetFollowRequests success", "user_id", userID, "count", len(requests.Items))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(requests)
+}
+
+func (c *FollowController) ApproveFollowRequest(w http.ResponseWriter, r *http.Request) {
+	c.answerFollowRequest(w, r, "ApproveFollowRequest", "Follow request approved", c.followService.ApproveFollowRequest)
+}
+
+func (c *FollowController) RejectFollowRequest(w http.ResponseWriter, r *http.Request) {
+	c.answerFollowRequest(w, r, "RejectFollowRequest", "Follow request rejected", c.followService.RejectFollowRequest)
+}
+
+// answerFollowRequest approves or rejects the request of the user in the path
+// to follow the caller.
+func (c *FollowController) answerFollowRequest(w http.ResponseWriter, r *http.Request, operation, doneMessage string, answer func(ctx context.Context, userID, requesterID string) error) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricFollowRequestError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	requesterID := chi.URLParam(r, "id")
+	err := answer(r.Context(), userID, requesterID)
+	if errors.Is(err, service.ErrFollowRequestNotFound) {
+		metrics.PutCountMetric(metrics.MetricFollowRequestError, 1)
+		http.Error(w, "Follow request not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricFollowRequestError, 1)
+		logger.LogError(operation+" error", "error", err, "user_id", userID, "requester_id", requesterID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricFollowRequestSuccess, 1)
+	logger.LogInfo(operation+" success", "user_id", userID, "requester_id", requesterID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": doneMessage,
+	})
+}
+
+func (c *FollowController) GetSettings(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricSettingsError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	settings, err := c.followService.GetSettings(r.Context(), userID)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricSettingsError, 1)
+		logger.LogError("GetSettings error", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricSettingsSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+func (c *FollowController) UpdateSettings(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricSettingsError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	var request model.UpdateSettingsRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		metrics.PutCountMetric(metrics.MetricSettingsError, 1)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	settings, err := c.followService.UpdateSettings(r.Context(), userID, &request)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricSettingsError, 1)
+		logger.LogError("UpdateSettings error", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricSettingsSuccess, 1)
+	logger.LogInfo("UpdateSettings success", "user_id", userID, "private", settings.Private)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settings)
+}
+
+func (c *FollowController) listFollows(w http.ResponseWriter, r *http.Request, operation string, list func(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Follow], error)) {
+	userID := chi.URLParam(r, "id")
+
+	page, err := parsePageRequest(r, c.config)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricFollowListError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	follows, err := list(r.Context(), userID, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		metrics.PutCountMetric(metrics.MetricFollowListError, 1)
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricFollowListError, 1)
+		logger.LogError(operation+" error", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricFollowListSuccess, 1)
+	logger.LogInfo(operation+" success", "user_id", userID, "count", len(follows.Items))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(follows)
+}
@@ -13,7 +13,6 @@ import (
 	"mensajesService/message-api/model"
 	"mensajesService/message-api/service"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,18 +23,101 @@ type MockFollowService struct {
 
 var _ service.FollowServiceInterface = (*MockFollowService)(nil)
 
-func (m *MockFollowService) FollowUser(ctx context.Context, userID, followingID string) error {
+func (m *MockFollowService) FollowUser(ctx context.Context, userID, followingID string) (string, error) {
 	args := m.Called(ctx, userID, followingID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockFollowService) BlockUser(ctx context.Context, userID, targetID string) (bool, error) {
+	args := m.Called(ctx, userID, targetID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowService) UnblockUser(ctx context.Context, userID, targetID string) (bool, error) {
+	args := m.Called(ctx, userID, targetID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowService) MuteUser(ctx context.Context, userID, targetID string) (bool, error) {
+	args := m.Called(ctx, userID, targetID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowService) UnmuteUser(ctx context.Context, userID, targetID string) (bool, error) {
+	args := m.Called(ctx, userID, targetID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowService) UnfollowUser(ctx context.Context, userID, followingID string) (bool, error) {
+	args := m.Called(ctx, userID, followingID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockFollowService) GetFollowers(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Follow], error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Page[*model.Follow]), args.Error(1)
+}
+
+func (m *MockFollowService) GetFollowing(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Follow], error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Page[*model.Follow]), args.Error(1)
+}
+
+func (m *MockFollowService) GetFollowRequests(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.PendingFollow], error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Page[*model.PendingFollow]), args.Error(1)
+}
+
+func (m *MockFollowService) ApproveFollowRequest(ctx context.Context, userID, requesterID string) error {
+	args := m.Called(ctx, userID, requesterID)
+	return args.Error(0)
+}
+
+func (m *MockFollowService) RejectFollowRequest(ctx context.Context, userID, requesterID string) error {
+	args := m.Called(ctx, userID, requesterID)
 	return args.Error(0)
 }
 
+func (m *MockFollowService) GetSettings(ctx context.Context, userID string) (*model.AccountSettings, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AccountSettings), args.Error(1)
+}
+
+func (m *MockFollowService) UpdateSettings(ctx context.Context, userID string, request *model.UpdateSettingsRequest) (*model.AccountSettings, error) {
+	args := m.Called(ctx, userID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.AccountSettings), args.Error(1)
+}
+
+func (m *MockFollowService) GetFollowCounts(ctx context.Context, userID string) (*model.FollowCounts, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.FollowCounts), args.Error(1)
+}
+
 func TestFollowUser_Success(t *testing.T) {
 	logger.Init()
 
 	mockService := &MockFollowService{}
 	mockConfig := &config.AppConfig{}
 
-	mockService.On("FollowUser", mock.Anything, "user123", "user456").Return(nil)
+	mockService.On("FollowUser", mock.Anything, "user123", "user456").Return(model.FollowStatusFollowing, nil)
 
 	controller := NewFollowController(mockService, mockConfig)
 
@@ -50,7 +132,7 @@ func TestFollowUser_Success(t *testing.T) {
 
 	response := httptest.NewRecorder()
 
-	router := chi.NewRouter()
+	router := newTestRouter()
 	controller.MountIn(router)
 	router.ServeHTTP(response, req)
 
@@ -63,3 +145,310 @@ func TestFollowUser_Success(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 }
+
+func TestFollowUser_Blocked(t *testing.T) {
+	mockService := &MockFollowService{}
+	mockService.On("FollowUser", mock.Anything, "user123", "user456").Return("", service.ErrFollowBlocked)
+
+	controller := NewFollowController(mockService, &config.AppConfig{})
+
+	body, _ := json.Marshal(model.FollowRequest{FollowingID: "user456"})
+	req := httptest.NewRequest("POST", "/follow", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUnfollowUser(t *testing.T) {
+	logger.Init()
+
+	for _, existed := range []bool{true, false} {
+		mockService := &MockFollowService{}
+		mockConfig := &config.AppConfig{}
+
+		mockService.On("UnfollowUser", mock.Anything, "user123", "user456").Return(existed, nil)
+
+		controller := NewFollowController(mockService, mockConfig)
+
+		req := httptest.NewRequest("DELETE", "/follow/user456", nil)
+		req.Header.Set("X-User-ID", "user123")
+
+		response := httptest.NewRecorder()
+
+		router := newTestRouter()
+		controller.MountIn(router)
+		router.ServeHTTP(response, req)
+
+		assert.Equal(t, http.StatusOK, response.Code)
+
+		var unfollowResponse map[string]interface{}
+		err := json.Unmarshal(response.Body.Bytes(), &unfollowResponse)
+		assert.NoError(t, err)
+		assert.Equal(t, existed, unfollowResponse["existed"])
+
+		mockService.AssertExpectations(t)
+	}
+}
+
+func TestGetFollowers_Success(t *testing.T) {
+	logger.Init()
+
+	mockService := &MockFollowService{}
+	mockConfig := &config.AppConfig{DefaultLimit: 20}
+
+	follows := []*model.Follow{
+		{FollowerID: "user123", FollowingID: "user456"},
+	}
+	mockService.On("GetFollowers", mock.Anything, "user456", model.PageRequest{Limit: 5}).Return(model.NewPage(follows, "next"), nil)
+
+	controller := NewFollowController(mockService, mockConfig)
+
+	req := httptest.NewRequest("GET", "/users/user456/followers?limit=5", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var followersResponse model.Page[*model.Follow]
+	err := json.Unmarshal(response.Body.Bytes(), &followersResponse)
+	assert.NoError(t, err)
+	assert.Len(t, followersResponse.Items, 1)
+	assert.Equal(t, "user123", followersResponse.Items[0].FollowerID)
+	assert.Equal(t, "next", followersResponse.NextCursor)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetFollowCounts_Success(t *testing.T) {
+	logger.Init()
+
+	mockService := &MockFollowService{}
+	mockConfig := &config.AppConfig{}
+
+	mockService.On("GetFollowCounts", mock.Anything, "user456").Return(&model.FollowCounts{
+		UserID:         "user456",
+		FollowersCount: 3,
+		FollowingCount: 1,
+	}, nil)
+
+	controller := NewFollowController(mockService, mockConfig)
+
+	req := httptest.NewRequest("GET", "/users/user456/follow-counts", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"user_id":"user456","followers_count":3,"following_count":1}`, response.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestBlockUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		created        bool
+		expectedStatus int
+		expectedBody   string
+	}{
+		{"new block", true, http.StatusCreated, "User blocked successfully"},
+		{"already blocked", false, http.StatusOK, "User was already blocked"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockFollowService{}
+			mockService.On("BlockUser", mock.Anything, "user123", "user456").Return(tt.created, nil)
+
+			controller := NewFollowController(mockService, &config.AppConfig{})
+
+			req := httptest.NewRequest("POST", "/blocks/user456", nil)
+			req.Header.Set("X-User-ID", "user123")
+			response := httptest.NewRecorder()
+
+			router := newTestRouter()
+			controller.MountIn(router)
+			router.ServeHTTP(response, req)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			var body map[string]string
+			assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedBody, body["message"])
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestBlockUser_Self(t *testing.T) {
+	mockService := &MockFollowService{}
+	controller := NewFollowController(mockService, &config.AppConfig{})
+
+	req := httptest.NewRequest("POST", "/blocks/user123", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	mockService.AssertNotCalled(t, "BlockUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUnmuteUser(t *testing.T) {
+	mockService := &MockFollowService{}
+	mockService.On("UnmuteUser", mock.Anything, "user123", "user456").Return(false, nil)
+
+	controller := NewFollowController(mockService, &config.AppConfig{})
+
+	req := httptest.NewRequest("DELETE", "/mutes/user456", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"message":"User was not muted","existed":false}`, response.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestMuteUser_MissingUserID(t *testing.T) {
+	mockService := &MockFollowService{}
+	controller := NewFollowController(mockService, &config.AppConfig{})
+
+	req := httptest.NewRequest("POST", "/mutes/user456", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	mockService.AssertNotCalled(t, "MuteUser", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestFollowUser_PendingApproval(t *testing.T) {
+	mockService := &MockFollowService{}
+	mockService.On("FollowUser", mock.Anything, "user123", "user456").Return(model.FollowStatusPending, nil)
+
+	controller := NewFollowController(mockService, &config.AppConfig{})
+
+	body, _ := json.Marshal(model.FollowRequest{FollowingID: "user456"})
+	req := httptest.NewRequest("POST", "/follow", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusAccepted, response.Code)
+	var followResponse map[string]string
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &followResponse))
+	assert.Equal(t, model.FollowStatusPending, followResponse["status"])
+	mockService.AssertExpectations(t)
+}
+
+func TestGetFollowRequests_Success(t *testing.T) {
+	mockService := &MockFollowService{}
+	requests := model.NewPage([]*model.PendingFollow{{UserID: "user123", RequesterID: "user456"}}, "")
+	mockService.On("GetFollowRequests", mock.Anything, "user123", model.PageRequest{Limit: 20}).Return(requests, nil)
+
+	controller := NewFollowController(mockService, &config.AppConfig{DefaultLimit: 20})
+
+	req := httptest.NewRequest("GET", "/follow-requests", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	var page model.Page[*model.PendingFollow]
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	assert.Len(t, page.Items, 1)
+	mockService.AssertExpectations(t)
+}
+
+func TestApproveFollowRequest(t *testing.T) {
+	tests := []struct {
+		name           string
+		err            error
+		expectedStatus int
+	}{
+		{"approved", nil, http.StatusOK},
+		{"no request", service.ErrFollowRequestNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockFollowService{}
+			mockService.On("ApproveFollowRequest", mock.Anything, "user123", "user456").Return(tt.err)
+
+			controller := NewFollowController(mockService, &config.AppConfig{})
+
+			req := httptest.NewRequest("POST", "/follow-requests/user456/approve", nil)
+			req.Header.Set("X-User-ID", "user123")
+			response := httptest.NewRecorder()
+
+			router := newTestRouter()
+			controller.MountIn(router)
+			router.ServeHTTP(response, req)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestRejectFollowRequest_MissingUserID(t *testing.T) {
+	mockService := &MockFollowService{}
+	controller := NewFollowController(mockService, &config.AppConfig{})
+
+	req := httptest.NewRequest("POST", "/follow-requests/user456/reject", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	mockService.AssertNotCalled(t, "RejectFollowRequest")
+}
+
+func TestUpdateSettings_Success(t *testing.T) {
+	mockService := &MockFollowService{}
+	mockService.On("UpdateSettings", mock.Anything, "user123", mock.MatchedBy(func(request *model.UpdateSettingsRequest) bool {
+		return request.Private != nil && *request.Private
+	})).Return(&model.AccountSettings{UserID: "user123", Private: true}, nil)
+
+	controller := NewFollowController(mockService, &config.AppConfig{})
+
+	req := httptest.NewRequest("PUT", "/settings", bytes.NewBufferString(`{"private": true}`))
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	var settings model.AccountSettings
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &settings))
+	assert.True(t, settings.Private)
+	mockService.AssertExpectations(t)
+}
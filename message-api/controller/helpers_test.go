@@ -0,0 +1,29 @@
+package controller
+
+import (
+	"context"
+
+	"mensajesService/components/jobqueue"
+	"mensajesService/message-api/web"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/stretchr/testify/mock"
+)
+
+// newTestRouter trusts X-User-ID like the header auth mode does in production.
+func newTestRouter() chi.Router {
+	router := chi.NewRouter()
+	router.Use(web.NewHeaderAuthenticator().Middleware)
+	return router
+}
+
+type MockEnqueuer struct {
+	mock.Mock
+}
+
+var _ jobqueue.Enqueuer = (*MockEnqueuer)(nil)
+
+func (m *MockEnqueuer) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	args := m.Called(ctx, jobType, payload)
+	return args.Error(0)
+}
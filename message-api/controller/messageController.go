@@ -1,30 +1,34 @@
 package controller
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 
 	"mensajesService/components/config"
+	"mensajesService/components/jobqueue"
 	"mensajesService/components/logger"
 	"mensajesService/components/metrics"
+	"mensajesService/components/pagination"
 	"mensajesService/message-api/model"
 	"mensajesService/message-api/service"
+	"mensajesService/message-api/web"
 
 	"github.com/go-chi/chi/v5"
 )
 
 type MessageController struct {
-	messageService  service.MessageServiceInterface
-	timelineService service.TimelineServiceInterface
-	config          *config.AppConfig
+	messageService service.MessageServiceInterface
+	jobs           jobqueue.Enqueuer
+	config         *config.AppConfig
 }
 
-func NewMessageController(messageService service.MessageServiceInterface, timelineService service.TimelineServiceInterface, cfg *config.AppConfig) *MessageController {
+func NewMessageController(messageService service.MessageServiceInterface, jobs jobqueue.Enqueuer, cfg *config.AppConfig) *MessageController {
 	return &MessageController{
-		messageService:  messageService,
-		timelineService: timelineService,
-		config:          cfg,
+		messageService: messageService,
+		jobs:           jobs,
+		config:         cfg,
 	}
 }
 
@@ -32,18 +36,28 @@ func (c *MessageController) MountIn(r chi.Router) {
 	r.Route("/message", func(r chi.Router) {
 		r.Post("/", c.CreateMessage)
 		r.Get("/", c.GetUserMessages)
+		r.Get("/{id}", c.GetMessage)
+		r.Get("/{id}/thread", c.GetThread)
+		r.Post("/{id}/repost", c.Repost)
+		r.Delete("/{id}/repost", c.UndoRepost)
+		r.Post("/{id}/like", c.LikeMessage)
+		r.Delete("/{id}/like", c.UnlikeMessage)
+		r.Delete("/{id}", c.DeleteMessage)
 	})
+	r.Get("/users/{id}/messages", c.GetUserMessagesByID)
+	r.Get("/users/{id}/likes", c.GetUserLikes)
+	r.Get("/hashtags/{tag}/messages", c.GetHashtagMessages)
 }
 
 func (c *MessageController) CreateMessage(w http.ResponseWriter, r *http.Request) {
-	userID := r.Header.Get("X-User-ID")
+	userID := web.UserIDFromContext(r.Context())
 	if userID == "" {
 		metrics.PutCountMetric(metrics.MetricMessageError, 1)
-		http.Error(w, "User ID required in X-User-ID header", http.StatusBadRequest)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
 		return
 	}
 
-	var message model.Message
+	var message model.CreateMessageRequest
 	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
 		metrics.PutCountMetric(metrics.MetricMessageError, 1)
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
@@ -62,7 +76,12 @@ func (c *MessageController) CreateMessage(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	createdMessage, err := c.messageService.CreateMessage(r.Context(), userID, message.Content)
+	createdMessage, err := c.messageService.CreateMessage(r.Context(), userID, &message)
+	if errors.Is(err, service.ErrReplyTargetNotFound) {
+		metrics.PutCountMetric(metrics.MetricMessageError, 1)
+		http.Error(w, "Reply target not found", http.StatusBadRequest)
+		return
+	}
 	if err != nil {
 		metrics.PutCountMetric(metrics.MetricMessageError, 1)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
@@ -70,11 +89,13 @@ func (c *MessageController) CreateMessage(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	go func() {
-		if err := c.timelineService.UpdateFollowersTimeline(context.Background(), createdMessage); err != nil {
-			logger.LogError("Error updating followers timeline", "error", err, "message_id", createdMessage.ID)
-		}
-	}()
+	// The message is already stored; failing the request would only invite a
+	// duplicate post, so a failed enqueue is logged instead.
+	if err := c.jobs.Enqueue(r.Context(), service.JobUpdateFollowersTimeline, createdMessage); err != nil {
+		metrics.PutCountMetric(metrics.MetricFanoutEnqueueError, 1)
+		logger.LogError("Error enqueueing fan-out", "error", err, "message_id", createdMessage.ID)
+	}
+	c.enqueueHashtagCount(r, createdMessage)
 
 	metrics.PutCountMetric(metrics.MetricMessageSuccess, 1)
 	w.Header().Set("Content-Type", "application/json")
@@ -83,24 +104,495 @@ func (c *MessageController) CreateMessage(w http.ResponseWriter, r *http.Request
 }
 
 func (c *MessageController) GetUserMessages(w http.ResponseWriter, r *http.Request) {
-	userID := r.Header.Get("X-User-ID")
+	userID := web.UserIDFromContext(r.Context())
 	if userID == "" {
 		metrics.PutCountMetric(metrics.MetricMessageError, 1)
-		http.Error(w, "User ID required in X-User-ID header", http.StatusBadRequest)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	c.listUserMessages(w, r, userID, userID)
+}
+
+// GetUserMessagesByID lists another user's messages for their profile. A
+// private account's messages are refused with 403 to anyone but the account
+// and its followers.
+func (c *MessageController) GetUserMessagesByID(w http.ResponseWriter, r *http.Request) {
+	c.listUserMessages(w, r, web.UserIDFromContext(r.Context()), chi.URLParam(r, "id"))
+}
+
+// listUserMessages lists userID's messages as seen by viewerID.
+func (c *MessageController) listUserMessages(w http.ResponseWriter, r *http.Request, viewerID, userID string) {
+	page, err := parsePageRequest(r, c.config)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUserMessagesError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	window, err := parseTimeRange(r)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUserMessagesError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	messages, err := c.messageService.GetUserMessages(r.Context(), userID, c.config.DefaultLimit)
+	expandAuthor, err := parseExpand(r)
 	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUserMessagesError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if viewerID != userID {
+		visible, err := c.messageService.CanViewMessages(r.Context(), viewerID, userID)
+		if err != nil {
+			metrics.PutCountMetric(metrics.MetricUserMessagesError, 1)
+			http.Error(w, "Internal server error", http.StatusInternalServerError)
+			logger.LogError("GetUserMessages error", "error", err, "user_id", userID)
+			return
+		}
+		if !visible {
+			metrics.PutCountMetric(metrics.MetricUserMessagesError, 1)
+			http.Error(w, "This account is private", http.StatusForbidden)
+			return
+		}
+	}
+
+	messages, err := c.messageService.GetUserMessages(r.Context(), userID, page, window)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		metrics.PutCountMetric(metrics.MetricUserMessagesError, 1)
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUserMessagesError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("GetUserMessages error", "error", err, "user_id", userID)
+		return
+	}
+	if err := c.decorateMessages(r, messages.Items, expandAuthor); err != nil {
 		metrics.PutCountMetric(metrics.MetricUserMessagesError, 1)
 		http.Error(w, "Internal server error", http.StatusInternalServerError)
 		logger.LogError("GetUserMessages error", "error", err, "user_id", userID)
 		return
 	}
-	messagesAmount := float64(len(messages))
+	messagesAmount := float64(len(messages.Items))
 	metrics.PutCountMetric(metrics.MetricUserMessagesSuccess, 1)
 	metrics.PutCountMetric(metrics.MetricUserMessagesCount, messagesAmount)
 	logger.LogInfo("GetUserMessages success", "user_id", userID, "messages_amount", messagesAmount)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(messages)
 }
+
+// GetMessage serves permalinks, so it doesn't require a caller identity.
+func (c *MessageController) GetMessage(w http.ResponseWriter, r *http.Request) {
+	messageID := chi.URLParam(r, "id")
+	expandAuthor, err := parseExpand(r)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricMessageGetError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	message, err := c.messageService.GetMessage(r.Context(), messageID)
+	if errors.Is(err, service.ErrMessageNotFound) {
+		metrics.PutCountMetric(metrics.MetricMessageGetError, 1)
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricMessageGetError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("GetMessage error", "error", err, "message_id", messageID)
+		return
+	}
+
+	// A private account's message is not found for those who can't read it.
+	visible, err := c.messageService.VisibleTo(r.Context(), web.UserIDFromContext(r.Context()), []*model.Message{message})
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricMessageGetError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("GetMessage error", "error", err, "message_id", messageID)
+		return
+	}
+	if len(visible) == 0 {
+		metrics.PutCountMetric(metrics.MetricMessageGetError, 1)
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+
+	if err := c.decorateMessages(r, visible, expandAuthor); err != nil {
+		metrics.PutCountMetric(metrics.MetricMessageGetError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("GetMessage error", "error", err, "message_id", messageID)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricMessageGetSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(message)
+}
+
+// GetThread serves the conversation view of a message; like GetMessage it
+// doesn't require a caller identity.
+func (c *MessageController) GetThread(w http.ResponseWriter, r *http.Request) {
+	depth, err := parseThreadDepth(r, c.config)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricThreadError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	messageID := chi.URLParam(r, "id")
+	thread, err := c.messageService.GetThread(r.Context(), messageID, depth)
+	if errors.Is(err, service.ErrMessageNotFound) {
+		metrics.PutCountMetric(metrics.MetricThreadError, 1)
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricThreadError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("GetThread error", "error", err, "message_id", messageID)
+		return
+	}
+
+	found, err := c.messageService.VisibleThread(r.Context(), web.UserIDFromContext(r.Context()), thread)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricThreadError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("GetThread error", "error", err, "message_id", messageID)
+		return
+	}
+	if !found {
+		metrics.PutCountMetric(metrics.MetricThreadError, 1)
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricThreadSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(thread)
+}
+
+// Repost shares a message. A body with content makes it a quote post.
+func (c *MessageController) Repost(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricRepostError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	var request model.RepostRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		metrics.PutCountMetric(metrics.MetricRepostError, 1)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if len(request.Content) > c.config.MaxMessageLength {
+		metrics.PutCountMetric(metrics.MetricRepostError, 1)
+		http.Error(w, "Content too long", http.StatusBadRequest)
+		return
+	}
+
+	messageID := chi.URLParam(r, "id")
+	repost, err := c.messageService.Repost(r.Context(), userID, messageID, &request)
+	if errors.Is(err, service.ErrMessageNotFound) {
+		metrics.PutCountMetric(metrics.MetricRepostError, 1)
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrAlreadyReposted) {
+		metrics.PutCountMetric(metrics.MetricRepostError, 1)
+		http.Error(w, "Message already reposted", http.StatusConflict)
+		return
+	}
+	if errors.Is(err, service.ErrPrivateRepost) {
+		metrics.PutCountMetric(metrics.MetricRepostError, 1)
+		http.Error(w, "Cannot repost a private account's message", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricRepostError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("Repost error", "error", err, "user_id", userID, "message_id", messageID)
+		return
+	}
+
+	if err := c.jobs.Enqueue(r.Context(), service.JobUpdateFollowersTimeline, repost); err != nil {
+		metrics.PutCountMetric(metrics.MetricFanoutEnqueueError, 1)
+		logger.LogError("Error enqueueing fan-out", "error", err, "message_id", repost.ID)
+	}
+	c.enqueueHashtagCount(r, repost)
+
+	metrics.PutCountMetric(metrics.MetricRepostSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(repost)
+}
+
+func (c *MessageController) UndoRepost(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricUnrepostError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	messageID := chi.URLParam(r, "id")
+	repost, err := c.messageService.UndoRepost(r.Context(), userID, messageID)
+	if errors.Is(err, service.ErrRepostNotFound) {
+		metrics.PutCountMetric(metrics.MetricUnrepostError, 1)
+		http.Error(w, "Repost not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUnrepostError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("UndoRepost error", "error", err, "user_id", userID, "message_id", messageID)
+		return
+	}
+
+	if repost != nil {
+		if err := c.jobs.Enqueue(r.Context(), service.JobRemoveFromFollowersTimeline, repost); err != nil {
+			metrics.PutCountMetric(metrics.MetricFanoutEnqueueError, 1)
+			logger.LogError("Error enqueueing retraction", "error", err, "message_id", repost.ID)
+		}
+	}
+
+	metrics.PutCountMetric(metrics.MetricUnrepostSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Repost removed successfully",
+	})
+}
+
+func (c *MessageController) LikeMessage(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricLikeError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	messageID := chi.URLParam(r, "id")
+	created, err := c.messageService.LikeMessage(r.Context(), userID, messageID)
+	if errors.Is(err, service.ErrMessageNotFound) {
+		metrics.PutCountMetric(metrics.MetricLikeError, 1)
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricLikeError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("LikeMessage error", "error", err, "user_id", userID, "message_id", messageID)
+		return
+	}
+
+	status, message := http.StatusOK, "Message was already liked"
+	if created {
+		status, message = http.StatusCreated, "Message liked successfully"
+		c.enqueueLikeCount(r, messageID, 1)
+	}
+
+	metrics.PutCountMetric(metrics.MetricLikeSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": message,
+	})
+}
+
+func (c *MessageController) UnlikeMessage(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricUnlikeError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	messageID := chi.URLParam(r, "id")
+	existed, err := c.messageService.UnlikeMessage(r.Context(), userID, messageID)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUnlikeError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("UnlikeMessage error", "error", err, "user_id", userID, "message_id", messageID)
+		return
+	}
+
+	message := "Message unliked successfully"
+	if existed {
+		c.enqueueLikeCount(r, messageID, -1)
+	} else {
+		message = "Message was not liked"
+	}
+
+	metrics.PutCountMetric(metrics.MetricUnlikeSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"message": message,
+		"existed": existed,
+	})
+}
+
+// GetUserLikes lists the messages a user liked, most recent like first.
+func (c *MessageController) GetUserLikes(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageRequest(r, c.config)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUserLikesError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	expandAuthor, err := parseExpand(r)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUserLikesError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	messages, err := c.messageService.GetUserLikes(r.Context(), userID, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		metrics.PutCountMetric(metrics.MetricUserLikesError, 1)
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err == nil {
+		messages.Items, err = c.messageService.VisibleTo(r.Context(), web.UserIDFromContext(r.Context()), messages.Items)
+	}
+	if err == nil {
+		err = c.decorateMessages(r, messages.Items, expandAuthor)
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUserLikesError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("GetUserLikes error", "error", err, "user_id", userID)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricUserLikesSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
+// GetHashtagMessages lists the messages tagged with a hashtag, newest first.
+func (c *MessageController) GetHashtagMessages(w http.ResponseWriter, r *http.Request) {
+	page, err := parsePageRequest(r, c.config)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricHashtagError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	expandAuthor, err := parseExpand(r)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricHashtagError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tag := chi.URLParam(r, "tag")
+	messages, err := c.messageService.GetHashtagMessages(r.Context(), tag, page)
+	if errors.Is(err, service.ErrInvalidHashtag) {
+		metrics.PutCountMetric(metrics.MetricHashtagError, 1)
+		http.Error(w, "Invalid hashtag", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		metrics.PutCountMetric(metrics.MetricHashtagError, 1)
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err == nil {
+		messages.Items, err = c.messageService.VisibleTo(r.Context(), web.UserIDFromContext(r.Context()), messages.Items)
+	}
+	if err == nil {
+		err = c.decorateMessages(r, messages.Items, expandAuthor)
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricHashtagError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("GetHashtagMessages error", "error", err, "tag", tag)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricHashtagSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
+// decorateMessages sets LikedByMe when the caller is known and, with
+// expandAuthor, embeds the authors' profiles.
+func (c *MessageController) decorateMessages(r *http.Request, messages []*model.Message, expandAuthor bool) error {
+	if len(messages) == 0 {
+		return nil
+	}
+	if viewerID := web.UserIDFromContext(r.Context()); viewerID != "" {
+		if err := c.messageService.MarkLiked(r.Context(), viewerID, messages); err != nil {
+			return err
+		}
+	}
+	if !expandAuthor {
+		return nil
+	}
+	return c.messageService.EmbedAuthors(r.Context(), messages)
+}
+
+// enqueueLikeCount schedules the counter update. Like DeleteMessage, a failed
+// enqueue is logged: the like itself is already stored.
+func (c *MessageController) enqueueLikeCount(r *http.Request, messageID string, delta int) {
+	job := service.LikeCountJob{MessageID: messageID, Delta: delta}
+	if err := c.jobs.Enqueue(r.Context(), service.JobAdjustLikeCount, job); err != nil {
+		metrics.PutCountMetric(metrics.MetricFanoutEnqueueError, 1)
+		logger.LogError("Error enqueueing like count update", "error", err, "message_id", messageID)
+	}
+}
+
+// enqueueHashtagCount counts a new message towards trending hashtags. A failed
+// enqueue is logged, as for the fan-out.
+func (c *MessageController) enqueueHashtagCount(r *http.Request, message *model.Message) {
+	if len(message.Entities.HashtagKeys()) == 0 {
+		return
+	}
+	if err := c.jobs.Enqueue(r.Context(), service.JobRecordHashtags, message); err != nil {
+		metrics.PutCountMetric(metrics.MetricFanoutEnqueueError, 1)
+		logger.LogError("Error enqueueing hashtag count", "error", err, "message_id", message.ID)
+	}
+}
+
+func (c *MessageController) DeleteMessage(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricMessageDeleteError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	messageID := chi.URLParam(r, "id")
+	deletedMessage, err := c.messageService.DeleteMessage(r.Context(), userID, messageID)
+	if errors.Is(err, service.ErrMessageNotFound) {
+		metrics.PutCountMetric(metrics.MetricMessageDeleteError, 1)
+		http.Error(w, "Message not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricMessageDeleteError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("DeleteMessage error", "error", err, "user_id", userID, "message_id", messageID)
+		return
+	}
+
+	if err := c.jobs.Enqueue(r.Context(), service.JobRemoveFromFollowersTimeline, deletedMessage); err != nil {
+		metrics.PutCountMetric(metrics.MetricFanoutEnqueueError, 1)
+		logger.LogError("Error enqueueing retraction", "error", err, "message_id", deletedMessage.ID)
+	}
+
+	metrics.PutCountMetric(metrics.MetricMessageDeleteSuccess, 1)
+	logger.LogInfo("DeleteMessage success", "user_id", userID, "message_id", messageID)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"message": "Message deleted successfully",
+	})
+}
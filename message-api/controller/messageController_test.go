@@ -11,10 +11,10 @@ import (
 
 	"mensajesService/components/config"
 	"mensajesService/components/logger"
+	"mensajesService/components/pagination"
 	"mensajesService/message-api/model"
 	"mensajesService/message-api/service"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -25,45 +25,139 @@ type MockMessageService struct {
 
 var _ service.MessageServiceInterface = (*MockMessageService)(nil)
 
-func (m *MockMessageService) CreateMessage(ctx context.Context, userID, content string) (*model.Message, error) {
-	args := m.Called(ctx, userID, content)
+func (m *MockMessageService) CreateMessage(ctx context.Context, userID string, request *model.CreateMessageRequest) (*model.Message, error) {
+	args := m.Called(ctx, userID, request)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).(*model.Message), args.Error(1)
 }
 
-func (m *MockMessageService) GetUserMessages(ctx context.Context, userID string, limit int) ([]*model.Message, error) {
-	args := m.Called(ctx, userID, limit)
+func (m *MockMessageService) GetUserMessages(ctx context.Context, userID string, page model.PageRequest, window model.TimeRange) (*model.Page[*model.Message], error) {
+	args := m.Called(ctx, userID, page, window)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Page[*model.Message]), args.Error(1)
+}
+
+func (m *MockMessageService) CanViewMessages(ctx context.Context, viewerID, authorID string) (bool, error) {
+	args := m.Called(ctx, viewerID, authorID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageService) VisibleTo(ctx context.Context, viewerID string, messages []*model.Message) ([]*model.Message, error) {
+	args := m.Called(ctx, viewerID, messages)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]*model.Message), args.Error(1)
 }
 
+func (m *MockMessageService) VisibleThread(ctx context.Context, viewerID string, thread *model.Thread) (bool, error) {
+	args := m.Called(ctx, viewerID, thread)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageService) GetMessage(ctx context.Context, messageID string) (*model.Message, error) {
+	args := m.Called(ctx, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Message), args.Error(1)
+}
+
+func (m *MockMessageService) GetThread(ctx context.Context, messageID string, depth int) (*model.Thread, error) {
+	args := m.Called(ctx, messageID, depth)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Thread), args.Error(1)
+}
+
+func (m *MockMessageService) Repost(ctx context.Context, userID, messageID string, request *model.RepostRequest) (*model.Message, error) {
+	args := m.Called(ctx, userID, messageID, request)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Message), args.Error(1)
+}
+
+func (m *MockMessageService) UndoRepost(ctx context.Context, userID, messageID string) (*model.Message, error) {
+	args := m.Called(ctx, userID, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Message), args.Error(1)
+}
+
+func (m *MockMessageService) LikeMessage(ctx context.Context, userID, messageID string) (bool, error) {
+	args := m.Called(ctx, userID, messageID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageService) UnlikeMessage(ctx context.Context, userID, messageID string) (bool, error) {
+	args := m.Called(ctx, userID, messageID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMessageService) GetUserLikes(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Message], error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Page[*model.Message]), args.Error(1)
+}
+
+func (m *MockMessageService) GetHashtagMessages(ctx context.Context, tag string, page model.PageRequest) (*model.Page[*model.Message], error) {
+	args := m.Called(ctx, tag, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Page[*model.Message]), args.Error(1)
+}
+
+func (m *MockMessageService) MarkLiked(ctx context.Context, viewerID string, messages []*model.Message) error {
+	args := m.Called(ctx, viewerID, messages)
+	return args.Error(0)
+}
+
+func (m *MockMessageService) EmbedAuthors(ctx context.Context, messages []*model.Message) error {
+	args := m.Called(ctx, messages)
+	return args.Error(0)
+}
+
+func (m *MockMessageService) DeleteMessage(ctx context.Context, userID, messageID string) (*model.Message, error) {
+	args := m.Called(ctx, userID, messageID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Message), args.Error(1)
+}
+
 func TestNewMessageController(t *testing.T) {
 	logger.Init()
 
 	mockService := &MockMessageService{}
-	mockTimelineService := &MockTimelineService{}
+	mockJobs := &MockEnqueuer{}
 	mockConfig := &config.AppConfig{}
 
-	controller := NewMessageController(mockService, mockTimelineService, mockConfig)
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
 
 	assert.NotNil(t, controller)
 	assert.Equal(t, mockService, controller.messageService)
-	assert.Equal(t, mockTimelineService, controller.timelineService)
+	assert.Equal(t, mockJobs, controller.jobs)
 	assert.Equal(t, mockConfig, controller.config)
 }
 
 func TestCreateMessage_Success(t *testing.T) {
 	mockService := &MockMessageService{}
-	mockTimelineService := &MockTimelineService{}
+	mockJobs := &MockEnqueuer{}
 	mockConfig := &config.AppConfig{
 		MaxMessageLength: 280,
 	}
 
-	controller := NewMessageController(mockService, mockTimelineService, mockConfig)
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
 
 	message := &model.Message{
 		ID:        "test-id",
@@ -72,8 +166,8 @@ func TestCreateMessage_Success(t *testing.T) {
 		CreatedAt: time.Now(),
 	}
 
-	mockService.On("CreateMessage", mock.Anything, "user123", "Test message").Return(message, nil)
-	mockTimelineService.On("UpdateFollowersTimeline", mock.Anything, message).Return(nil)
+	mockService.On("CreateMessage", mock.Anything, "user123", &model.CreateMessageRequest{Content: "Test message"}).Return(message, nil)
+	mockJobs.On("Enqueue", mock.Anything, service.JobUpdateFollowersTimeline, message).Return(nil)
 
 	body, _ := json.Marshal(map[string]string{"content": "Test message"})
 	req := httptest.NewRequest("POST", "/message", bytes.NewBuffer(body))
@@ -82,7 +176,7 @@ func TestCreateMessage_Success(t *testing.T) {
 
 	response := httptest.NewRecorder()
 
-	router := chi.NewRouter()
+	router := newTestRouter()
 	controller.MountIn(router)
 	router.ServeHTTP(response, req)
 
@@ -93,21 +187,49 @@ func TestCreateMessage_Success(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, "Test message", messageResponse.Content)
 
-	// Wait a bit for the goroutine to complete. It could be better
-	time.Sleep(100 * time.Millisecond)
+	mockService.AssertExpectations(t)
+	mockJobs.AssertExpectations(t)
+}
+
+func TestCreateMessage_EnqueuesHashtagCount(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{MaxMessageLength: 280})
 
+	message := &model.Message{
+		ID:        "test-id",
+		UserID:    "user123",
+		Content:   "#go",
+		CreatedAt: time.Now(),
+		Entities:  &model.Entities{Hashtags: []model.Hashtag{{Tag: "go", Start: 0, End: 3}}},
+	}
+
+	mockService.On("CreateMessage", mock.Anything, "user123", &model.CreateMessageRequest{Content: "#go"}).Return(message, nil)
+	mockJobs.On("Enqueue", mock.Anything, service.JobUpdateFollowersTimeline, message).Return(nil)
+	mockJobs.On("Enqueue", mock.Anything, service.JobRecordHashtags, message).Return(nil)
+
+	body, _ := json.Marshal(map[string]string{"content": "#go"})
+	req := httptest.NewRequest("POST", "/message", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusCreated, response.Code)
 	mockService.AssertExpectations(t)
-	mockTimelineService.AssertExpectations(t)
+	mockJobs.AssertExpectations(t)
 }
 
 func TestCreateMessage_MissingUserID(t *testing.T) {
 	mockService := &MockMessageService{}
-	mockTimelineService := &MockTimelineService{}
+	mockJobs := &MockEnqueuer{}
 	mockConfig := &config.AppConfig{
 		MaxMessageLength: 280,
 	}
 
-	controller := NewMessageController(mockService, mockTimelineService, mockConfig)
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
 
 	body, _ := json.Marshal(map[string]string{"content": "Test message"})
 	req := httptest.NewRequest("POST", "/message", bytes.NewBuffer(body))
@@ -115,22 +237,44 @@ func TestCreateMessage_MissingUserID(t *testing.T) {
 
 	response := httptest.NewRecorder()
 
-	router := chi.NewRouter()
+	router := newTestRouter()
 	controller.MountIn(router)
 	router.ServeHTTP(response, req)
 
-	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
 	assert.Contains(t, response.Body.String(), "User ID required")
 }
 
+func TestCreateMessage_ReplyTargetNotFound(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{MaxMessageLength: 280})
+
+	request := &model.CreateMessageRequest{Content: "Test reply", InReplyTo: "missing"}
+	mockService.On("CreateMessage", mock.Anything, "user123", request).Return(nil, service.ErrReplyTargetNotFound)
+
+	body, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/message", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	mockService.AssertExpectations(t)
+	mockJobs.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
 func TestGetUserMessages_Success(t *testing.T) {
 	mockService := &MockMessageService{}
-	mockTimelineService := &MockTimelineService{}
+	mockJobs := &MockEnqueuer{}
 	mockConfig := &config.AppConfig{
 		DefaultLimit: 20,
 	}
 
-	controller := NewMessageController(mockService, mockTimelineService, mockConfig)
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
 
 	messages := []*model.Message{
 		{
@@ -147,45 +291,656 @@ func TestGetUserMessages_Success(t *testing.T) {
 		},
 	}
 
-	mockService.On("GetUserMessages", mock.Anything, "user123", 20).Return(messages, nil)
+	mockService.On("GetUserMessages", mock.Anything, "user123", model.PageRequest{Limit: 20}, model.TimeRange{}).Return(model.NewPage(messages, "next"), nil)
+	mockService.On("MarkLiked", mock.Anything, "user123", messages).Return(nil)
 
 	req := httptest.NewRequest("GET", "/message", nil)
 	req.Header.Set("X-User-ID", "user123")
 
 	response := httptest.NewRecorder()
 
-	router := chi.NewRouter()
+	router := newTestRouter()
 	controller.MountIn(router)
 	router.ServeHTTP(response, req)
 
 	assert.Equal(t, http.StatusOK, response.Code)
 
-	var messagesResponse []*model.Message
+	var messagesResponse model.Page[*model.Message]
 	err := json.Unmarshal(response.Body.Bytes(), &messagesResponse)
 	assert.NoError(t, err)
-	assert.Len(t, messagesResponse, 2)
-	assert.Equal(t, "msg1", messagesResponse[0].ID)
+	assert.Len(t, messagesResponse.Items, 2)
+	assert.Equal(t, "msg1", messagesResponse.Items[0].ID)
+	assert.Equal(t, "next", messagesResponse.NextCursor)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetUserMessages_LimitAndCursor(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	mockConfig := &config.AppConfig{
+		DefaultLimit: 20,
+		MaxLimit:     50,
+	}
+
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
+
+	mockService.On("GetUserMessages", mock.Anything, "user123", model.PageRequest{Limit: 50, Cursor: "abc"}, model.TimeRange{}).Return(model.NewPage([]*model.Message{}, ""), nil)
+
+	req := httptest.NewRequest("GET", "/message?limit=500&cursor=abc", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"items":[]}`, response.Body.String())
 
 	mockService.AssertExpectations(t)
 }
 
+func TestGetUserMessages_InvalidCursor(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	mockConfig := &config.AppConfig{
+		DefaultLimit: 20,
+	}
+
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
+
+	mockService.On("GetUserMessages", mock.Anything, "user123", model.PageRequest{Limit: 20, Cursor: "bogus"}, model.TimeRange{}).Return(nil, pagination.ErrInvalidCursor)
+
+	req := httptest.NewRequest("GET", "/message?cursor=bogus", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Contains(t, response.Body.String(), "Invalid cursor")
+}
+
+func TestGetUserMessages_InvalidLimit(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	mockConfig := &config.AppConfig{
+		DefaultLimit: 20,
+	}
+
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
+
+	req := httptest.NewRequest("GET", "/message?limit=-1", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	mockService.AssertNotCalled(t, "GetUserMessages")
+}
+
 func TestGetUserMessages_MissingUserID(t *testing.T) {
 	mockService := &MockMessageService{}
-	mockTimelineService := &MockTimelineService{}
+	mockJobs := &MockEnqueuer{}
 	mockConfig := &config.AppConfig{
 		DefaultLimit: 20,
 	}
 
-	controller := NewMessageController(mockService, mockTimelineService, mockConfig)
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
 
 	req := httptest.NewRequest("GET", "/message", nil)
 
 	response := httptest.NewRecorder()
 
-	router := chi.NewRouter()
+	router := newTestRouter()
 	controller.MountIn(router)
 	router.ServeHTTP(response, req)
 
-	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
 	assert.Contains(t, response.Body.String(), "User ID required")
 }
+
+func TestDeleteMessage_Success(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	mockConfig := &config.AppConfig{}
+
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
+
+	message := &model.Message{
+		ID:        "msg1",
+		UserID:    "user123",
+		Content:   "Test message",
+		CreatedAt: time.Now(),
+	}
+
+	mockService.On("DeleteMessage", mock.Anything, "user123", "msg1").Return(message, nil)
+	mockJobs.On("Enqueue", mock.Anything, service.JobRemoveFromFollowersTimeline, message).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/message/msg1", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	mockService.AssertExpectations(t)
+	mockJobs.AssertExpectations(t)
+}
+
+func TestDeleteMessage_NotFound(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	mockConfig := &config.AppConfig{}
+
+	controller := NewMessageController(mockService, mockJobs, mockConfig)
+
+	mockService.On("DeleteMessage", mock.Anything, "user456", "msg1").Return(nil, service.ErrMessageNotFound)
+
+	req := httptest.NewRequest("DELETE", "/message/msg1", nil)
+	req.Header.Set("X-User-ID", "user456")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	mockJobs.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetMessage_Success(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{})
+
+	message := &model.Message{
+		ID:        "msg1",
+		UserID:    "user123",
+		Content:   "Test message",
+		CreatedAt: time.Now(),
+	}
+	mockService.On("GetMessage", mock.Anything, "msg1").Return(message, nil)
+	mockService.On("VisibleTo", mock.Anything, "", []*model.Message{message}).Return([]*model.Message{message}, nil)
+
+	req := httptest.NewRequest("GET", "/message/msg1", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var messageResponse model.Message
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &messageResponse))
+	assert.Equal(t, "msg1", messageResponse.ID)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetMessage_NotFound(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{})
+
+	mockService.On("GetMessage", mock.Anything, "missing").Return(nil, service.ErrMessageNotFound)
+
+	req := httptest.NewRequest("GET", "/message/missing", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestGetMessage_HiddenFromViewer(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{})
+
+	message := &model.Message{ID: "msg1", UserID: "alice", Content: "private", CreatedAt: time.Now()}
+	mockService.On("GetMessage", mock.Anything, "msg1").Return(message, nil)
+	mockService.On("VisibleTo", mock.Anything, "carol", []*model.Message{message}).Return([]*model.Message{}, nil)
+
+	req := httptest.NewRequest("GET", "/message/msg1", nil)
+	req.Header.Set("X-User-ID", "carol")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetUserMessagesByID_TimeRange(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{DefaultLimit: 20})
+
+	window := model.TimeRange{
+		After:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		Before: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC),
+	}
+	mockService.On("CanViewMessages", mock.Anything, "alice", "bob").Return(true, nil)
+	mockService.On("GetUserMessages", mock.Anything, "bob", model.PageRequest{Limit: 20}, mock.MatchedBy(func(w model.TimeRange) bool {
+		return w.After.Equal(window.After) && w.Before.Equal(window.Before)
+	})).Return(model.NewPage([]*model.Message{}, ""), nil)
+
+	req := httptest.NewRequest("GET", "/users/bob/messages?after=2024-01-01T00:00:00Z&before=2024-02-01T00:00:00Z", nil)
+	req.Header.Set("X-User-ID", "alice")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetUserMessagesByID_InvalidBefore(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{DefaultLimit: 20})
+
+	req := httptest.NewRequest("GET", "/users/bob/messages?before=yesterday", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	mockService.AssertNotCalled(t, "GetUserMessages")
+}
+
+func TestGetUserMessagesByID_Private(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{DefaultLimit: 20})
+	mockService.On("CanViewMessages", mock.Anything, "alice", "bob").Return(false, nil)
+
+	req := httptest.NewRequest("GET", "/users/bob/messages", nil)
+	req.Header.Set("X-User-ID", "alice")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	mockService.AssertNotCalled(t, "GetUserMessages")
+}
+
+func TestGetThread_ClampsDepth(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{ThreadMaxDepth: 5})
+
+	thread := &model.Thread{
+		ConversationID: "msg1",
+		Ancestors:      []*model.Message{},
+		Root:           &model.ThreadNode{Message: &model.Message{ID: "msg1"}, Replies: []*model.ThreadNode{}},
+	}
+	mockService.On("GetThread", mock.Anything, "msg1", 5).Return(thread, nil)
+	mockService.On("VisibleThread", mock.Anything, "", thread).Return(true, nil)
+
+	req := httptest.NewRequest("GET", "/message/msg1/thread?depth=50", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+
+	var threadResponse model.Thread
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &threadResponse))
+	assert.Equal(t, "msg1", threadResponse.Root.Message.ID)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetThread_InvalidDepth(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{ThreadMaxDepth: 5})
+
+	req := httptest.NewRequest("GET", "/message/msg1/thread?depth=-1", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	mockService.AssertNotCalled(t, "GetThread", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetThread_NotFound(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{ThreadMaxDepth: 5})
+
+	mockService.On("GetThread", mock.Anything, "missing", 5).Return(nil, service.ErrMessageNotFound)
+
+	req := httptest.NewRequest("GET", "/message/missing/thread", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetThread_HiddenFromViewer(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{ThreadMaxDepth: 5})
+
+	thread := &model.Thread{ConversationID: "msg1", Root: &model.ThreadNode{Message: &model.Message{ID: "msg1", UserID: "alice"}}}
+	mockService.On("GetThread", mock.Anything, "msg1", 5).Return(thread, nil)
+	mockService.On("VisibleThread", mock.Anything, "", thread).Return(false, nil)
+
+	req := httptest.NewRequest("GET", "/message/msg1/thread", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestRepost_Success(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{MaxMessageLength: 280})
+
+	repost := &model.Message{ID: "repost1", UserID: "user123", RepostOf: "msg1", CreatedAt: time.Now()}
+	mockService.On("Repost", mock.Anything, "user123", "msg1", &model.RepostRequest{}).Return(repost, nil)
+	mockJobs.On("Enqueue", mock.Anything, service.JobUpdateFollowersTimeline, repost).Return(nil)
+
+	req := httptest.NewRequest("POST", "/message/msg1/repost", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusCreated, response.Code)
+	mockService.AssertExpectations(t)
+	mockJobs.AssertExpectations(t)
+}
+
+func TestRepost_Quote(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{MaxMessageLength: 280})
+
+	request := &model.RepostRequest{Content: "look at this"}
+	quote := &model.Message{ID: "quote1", UserID: "user123", Content: "look at this", QuoteOf: "msg1", CreatedAt: time.Now()}
+	mockService.On("Repost", mock.Anything, "user123", "msg1", request).Return(quote, nil)
+	mockJobs.On("Enqueue", mock.Anything, service.JobUpdateFollowersTimeline, quote).Return(nil)
+
+	body, _ := json.Marshal(request)
+	req := httptest.NewRequest("POST", "/message/msg1/repost", bytes.NewBuffer(body))
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusCreated, response.Code)
+	var messageResponse model.Message
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &messageResponse))
+	assert.Equal(t, "msg1", messageResponse.QuoteOf)
+	mockService.AssertExpectations(t)
+}
+
+func TestRepost_Duplicate(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{MaxMessageLength: 280})
+
+	mockService.On("Repost", mock.Anything, "user123", "msg1", &model.RepostRequest{}).Return(nil, service.ErrAlreadyReposted)
+
+	req := httptest.NewRequest("POST", "/message/msg1/repost", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusConflict, response.Code)
+	mockJobs.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestRepost_PrivateOriginal(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{MaxMessageLength: 280})
+
+	mockService.On("Repost", mock.Anything, "user123", "msg1", &model.RepostRequest{}).Return(nil, service.ErrPrivateRepost)
+
+	req := httptest.NewRequest("POST", "/message/msg1/repost", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	mockJobs.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestUndoRepost_Success(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{})
+
+	repost := &model.Message{ID: "repost1", UserID: "user123", RepostOf: "msg1", CreatedAt: time.Now()}
+	mockService.On("UndoRepost", mock.Anything, "user123", "msg1").Return(repost, nil)
+	mockJobs.On("Enqueue", mock.Anything, service.JobRemoveFromFollowersTimeline, repost).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/message/msg1/repost", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	mockService.AssertExpectations(t)
+	mockJobs.AssertExpectations(t)
+}
+
+func TestUndoRepost_NotFound(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{})
+
+	mockService.On("UndoRepost", mock.Anything, "user123", "msg1").Return(nil, service.ErrRepostNotFound)
+
+	req := httptest.NewRequest("DELETE", "/message/msg1/repost", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestLikeMessage_Success(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{})
+
+	mockService.On("LikeMessage", mock.Anything, "user123", "msg1").Return(true, nil)
+	mockJobs.On("Enqueue", mock.Anything, service.JobAdjustLikeCount, service.LikeCountJob{MessageID: "msg1", Delta: 1}).Return(nil)
+
+	req := httptest.NewRequest("POST", "/message/msg1/like", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusCreated, response.Code)
+	mockService.AssertExpectations(t)
+	mockJobs.AssertExpectations(t)
+}
+
+func TestLikeMessage_AlreadyLiked(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{})
+
+	mockService.On("LikeMessage", mock.Anything, "user123", "msg1").Return(false, nil)
+
+	req := httptest.NewRequest("POST", "/message/msg1/like", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	mockJobs.AssertNotCalled(t, "Enqueue", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestLikeMessage_NotFound(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{})
+
+	mockService.On("LikeMessage", mock.Anything, "user123", "missing").Return(false, service.ErrMessageNotFound)
+
+	req := httptest.NewRequest("POST", "/message/missing/like", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusNotFound, response.Code)
+}
+
+func TestUnlikeMessage_Success(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{})
+
+	mockService.On("UnlikeMessage", mock.Anything, "user123", "msg1").Return(true, nil)
+	mockJobs.On("Enqueue", mock.Anything, service.JobAdjustLikeCount, service.LikeCountJob{MessageID: "msg1", Delta: -1}).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/message/msg1/like", nil)
+	req.Header.Set("X-User-ID", "user123")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Contains(t, response.Body.String(), `"existed":true`)
+	mockJobs.AssertExpectations(t)
+}
+
+func TestGetUserLikes_MarksLikedForCaller(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{DefaultLimit: 20})
+
+	messages := []*model.Message{{ID: "msg1", UserID: "alice", Content: "hi", CreatedAt: time.Now()}}
+	mockService.On("GetUserLikes", mock.Anything, "bob", model.PageRequest{Limit: 20}).Return(model.NewPage(messages, ""), nil)
+	mockService.On("VisibleTo", mock.Anything, "carol", messages).Return(messages, nil)
+	mockService.On("MarkLiked", mock.Anything, "carol", messages).Return(nil)
+
+	req := httptest.NewRequest("GET", "/users/bob/likes", nil)
+	req.Header.Set("X-User-ID", "carol")
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetHashtagMessages_Success(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{DefaultLimit: 20})
+
+	messages := []*model.Message{{ID: "msg1", UserID: "alice", Content: "#golang", CreatedAt: time.Now()}}
+	mockService.On("GetHashtagMessages", mock.Anything, "GoLang", model.PageRequest{Limit: 20}).Return(model.NewPage(messages, "next"), nil)
+	mockService.On("VisibleTo", mock.Anything, "", messages).Return(messages, nil)
+
+	req := httptest.NewRequest("GET", "/hashtags/GoLang/messages", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	var page model.Page[*model.Message]
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &page))
+	if assert.Len(t, page.Items, 1) {
+		assert.Equal(t, "msg1", page.Items[0].ID)
+	}
+	assert.Equal(t, "next", page.NextCursor)
+	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "MarkLiked", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetHashtagMessages_InvalidTag(t *testing.T) {
+	mockService := &MockMessageService{}
+	mockJobs := &MockEnqueuer{}
+	controller := NewMessageController(mockService, mockJobs, &config.AppConfig{DefaultLimit: 20})
+
+	mockService.On("GetHashtagMessages", mock.Anything, "123", model.PageRequest{Limit: 20}).Return(nil, service.ErrInvalidHashtag)
+
+	req := httptest.NewRequest("GET", "/hashtags/123/messages", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, "Invalid hashtag\n", response.Body.String())
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,124 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"mensajesService/components/config"
+	"mensajesService/message-api/model"
+)
+
+var (
+	errInvalidLimit  = errors.New("limit must be a positive integer")
+	errInvalidBefore = errors.New("before must be an RFC 3339 timestamp")
+	errInvalidAfter  = errors.New("after must be an RFC 3339 timestamp")
+	errInvalidDepth  = errors.New("depth must be a non-negative integer")
+	errInvalidWindow = errors.New("window must be a duration such as 1h")
+	errInvalidExpand = errors.New("expand only accepts author")
+)
+
+// parsePageRequest reads the optional limit and cursor query parameters.
+// Limits above the configured maximum are clamped instead of rejected.
+func parsePageRequest(r *http.Request, cfg *config.AppConfig) (model.PageRequest, error) {
+	page := model.PageRequest{
+		Limit:  cfg.DefaultLimit,
+		Cursor: r.URL.Query().Get("cursor"),
+	}
+
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		limit, err := strconv.Atoi(rawLimit)
+		if err != nil || limit <= 0 {
+			return page, errInvalidLimit
+		}
+		page.Limit = limit
+	}
+
+	if cfg.MaxLimit > 0 && page.Limit > cfg.MaxLimit {
+		page.Limit = cfg.MaxLimit
+	}
+
+	return page, nil
+}
+
+// parseTimeRange reads the optional before and after query parameters.
+func parseTimeRange(r *http.Request) (model.TimeRange, error) {
+	var window model.TimeRange
+
+	if rawBefore := r.URL.Query().Get("before"); rawBefore != "" {
+		before, err := time.Parse(time.RFC3339Nano, rawBefore)
+		if err != nil {
+			return window, errInvalidBefore
+		}
+		window.Before = before
+	}
+
+	if rawAfter := r.URL.Query().Get("after"); rawAfter != "" {
+		after, err := time.Parse(time.RFC3339Nano, rawAfter)
+		if err != nil {
+			return window, errInvalidAfter
+		}
+		window.After = after
+	}
+
+	return window, nil
+}
+
+// parseThreadDepth reads the optional depth query parameter, defaulting to
+// and clamped by the configured maximum.
+func parseThreadDepth(r *http.Request, cfg *config.AppConfig) (int, error) {
+	depth := cfg.ThreadMaxDepth
+	if rawDepth := r.URL.Query().Get("depth"); rawDepth != "" {
+		parsed, err := strconv.Atoi(rawDepth)
+		if err != nil || parsed < 0 {
+			return 0, errInvalidDepth
+		}
+		depth = parsed
+	}
+
+	if depth > cfg.ThreadMaxDepth {
+		depth = cfg.ThreadMaxDepth
+	}
+	return depth, nil
+}
+
+// parseTrendsRequest reads the optional window and limit query parameters.
+// The limit defaults to and is clamped by TRENDS_TOP_N.
+func parseTrendsRequest(r *http.Request, cfg *config.AppConfig, defaultWindow time.Duration) (time.Duration, int, error) {
+	window := defaultWindow
+	if rawWindow := r.URL.Query().Get("window"); rawWindow != "" {
+		parsed, err := time.ParseDuration(rawWindow)
+		if err != nil {
+			return 0, 0, errInvalidWindow
+		}
+		window = parsed
+	}
+
+	limit := cfg.TrendTopN
+	if rawLimit := r.URL.Query().Get("limit"); rawLimit != "" {
+		parsed, err := strconv.Atoi(rawLimit)
+		if err != nil || parsed <= 0 {
+			return 0, 0, errInvalidLimit
+		}
+		limit = parsed
+	}
+	if cfg.TrendTopN > 0 && limit > cfg.TrendTopN {
+		limit = cfg.TrendTopN
+	}
+
+	return window, limit, nil
+}
+
+// parseExpand reads the optional expand query parameter and reports whether
+// author profiles should be embedded, as asked with expand=author.
+func parseExpand(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("expand") {
+	case "":
+		return false, nil
+	case "author":
+		return true, nil
+	default:
+		return false, errInvalidExpand
+	}
+}
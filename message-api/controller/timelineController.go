@@ -2,12 +2,15 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
 
 	"mensajesService/components/config"
 	"mensajesService/components/logger"
 	"mensajesService/components/metrics"
+	"mensajesService/components/pagination"
 	"mensajesService/message-api/service"
+	"mensajesService/message-api/web"
 
 	"github.com/go-chi/chi/v5"
 )
@@ -28,18 +31,42 @@ func (c *TimelineController) MountIn(r chi.Router) {
 	r.Route("/timeline", func(r chi.Router) {
 		r.Get("/", c.GetTimeline)
 	})
+	r.Get("/mentions", c.GetMentions)
 }
 
 func (c *TimelineController) GetTimeline(w http.ResponseWriter, r *http.Request) {
-	userID := r.Header.Get("X-User-ID")
+	userID := web.UserIDFromContext(r.Context())
 	if userID == "" {
 		metrics.PutCountMetric(metrics.MetricTimelineError, 1)
-		logger.LogError("GetTimeline error", "error", "User ID required in X-User-ID header", "user_id", userID)
-		http.Error(w, "User ID required in X-User-ID header", http.StatusBadRequest)
+		logger.LogError("GetTimeline error", "error", "User ID required", "user_id", userID)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
 		return
 	}
 
-	timeline, err := c.timelineService.GetUserTimeline(r.Context(), userID, c.config.DefaultLimit)
+	page, err := parsePageRequest(r, c.config)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricTimelineError, 1)
+		logger.LogError("GetTimeline error", "error", err, "user_id", userID)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	expandAuthor, err := parseExpand(r)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricTimelineError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	timeline, err := c.timelineService.GetUserTimeline(r.Context(), userID, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		metrics.PutCountMetric(metrics.MetricTimelineError, 1)
+		logger.LogError("GetTimeline error", "error", err, "user_id", userID)
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err == nil && expandAuthor {
+		err = c.timelineService.EmbedAuthors(r.Context(), timeline.Items)
+	}
 	if err != nil {
 		metrics.PutCountMetric(metrics.MetricTimelineError, 1)
 		logger.LogError("GetTimeline error", "error", err, "user_id", userID)
@@ -47,17 +74,63 @@ func (c *TimelineController) GetTimeline(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if len(timeline) == 0 {
+	// Only the first page can tell us the user has no timeline at all. Muted
+	// authors can empty a first page that still has more after it.
+	if len(timeline.Items) == 0 && page.Cursor == "" && timeline.NextCursor == "" {
 		metrics.PutCountMetric(metrics.MetricTimelineError, 1)
 		logger.LogError("Get Timeline error", "error", "User not found", "user_id", userID)
 		http.Error(w, "User not found", http.StatusNotFound)
 		return
 	}
 
-	timelineMessagesAmount := float64(len(timeline))
+	timelineMessagesAmount := float64(len(timeline.Items))
 	metrics.PutCountMetric(metrics.MetricTimelineSuccess, 1)
 	metrics.PutCountMetric(metrics.MetricTimelineCount, timelineMessagesAmount)
 	logger.LogInfo("GetTimeline success", "user_id", userID, "timeline_count", timelineMessagesAmount)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(timeline)
 }
+
+// GetMentions lists the messages that mention the caller. Unlike the
+// timeline, an empty feed is a normal answer.
+func (c *TimelineController) GetMentions(w http.ResponseWriter, r *http.Request) {
+	userID := web.UserIDFromContext(r.Context())
+	if userID == "" {
+		metrics.PutCountMetric(metrics.MetricMentionsError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	page, err := parsePageRequest(r, c.config)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricMentionsError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	expandAuthor, err := parseExpand(r)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricMentionsError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	mentions, err := c.timelineService.GetMentions(r.Context(), userID, page)
+	if errors.Is(err, pagination.ErrInvalidCursor) {
+		metrics.PutCountMetric(metrics.MetricMentionsError, 1)
+		http.Error(w, "Invalid cursor", http.StatusBadRequest)
+		return
+	}
+	if err == nil && expandAuthor {
+		err = c.timelineService.EmbedAuthors(r.Context(), mentions.Items)
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricMentionsError, 1)
+		logger.LogError("GetMentions error", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricMentionsSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(mentions)
+}
@@ -13,7 +13,6 @@ import (
 	"mensajesService/message-api/model"
 	"mensajesService/message-api/service"
 
-	"github.com/go-chi/chi/v5"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -24,9 +23,25 @@ type MockTimelineService struct {
 
 var _ service.TimelineServiceInterface = (*MockTimelineService)(nil)
 
-func (m *MockTimelineService) GetUserTimeline(ctx context.Context, userID string, limit int) ([]*model.TimelineItem, error) {
-	args := m.Called(ctx, userID, limit)
-	return args.Get(0).([]*model.TimelineItem), args.Error(1)
+func (m *MockTimelineService) GetUserTimeline(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.TimelineItem], error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Page[*model.TimelineItem]), args.Error(1)
+}
+
+func (m *MockTimelineService) GetMentions(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.TimelineItem], error) {
+	args := m.Called(ctx, userID, page)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Page[*model.TimelineItem]), args.Error(1)
+}
+
+func (m *MockTimelineService) EmbedAuthors(ctx context.Context, items []*model.TimelineItem) error {
+	args := m.Called(ctx, items)
+	return args.Error(0)
 }
 
 func (m *MockTimelineService) UpdateFollowersTimeline(ctx context.Context, message *model.Message) error {
@@ -34,6 +49,21 @@ func (m *MockTimelineService) UpdateFollowersTimeline(ctx context.Context, messa
 	return args.Error(0)
 }
 
+func (m *MockTimelineService) RemoveFromFollowersTimeline(ctx context.Context, message *model.Message) error {
+	args := m.Called(ctx, message)
+	return args.Error(0)
+}
+
+func (m *MockTimelineService) BackfillTimeline(ctx context.Context, userID string, messages []*model.Message) error {
+	args := m.Called(ctx, userID, messages)
+	return args.Error(0)
+}
+
+func (m *MockTimelineService) RemoveAuthorFromTimeline(ctx context.Context, userID, authorID string) error {
+	args := m.Called(ctx, userID, authorID)
+	return args.Error(0)
+}
+
 func TestGetTimeline_Success(t *testing.T) {
 	logger.Init()
 
@@ -57,7 +87,7 @@ func TestGetTimeline_Success(t *testing.T) {
 		},
 	}
 
-	mockService.On("GetUserTimeline", mock.Anything, "user123", 10).Return(expectedTimeline, nil)
+	mockService.On("GetUserTimeline", mock.Anything, "user123", model.PageRequest{Limit: 10}).Return(model.NewPage(expectedTimeline, "next"), nil)
 
 	controller := NewTimelineController(mockService, mockConfig)
 
@@ -66,18 +96,151 @@ func TestGetTimeline_Success(t *testing.T) {
 
 	response := httptest.NewRecorder()
 
-	router := chi.NewRouter()
+	router := newTestRouter()
 	controller.MountIn(router)
 	router.ServeHTTP(response, req)
 
 	assert.Equal(t, http.StatusOK, response.Code)
 
-	var timelineResponse []*model.TimelineItem
+	var timelineResponse model.Page[*model.TimelineItem]
 	err := json.Unmarshal(response.Body.Bytes(), &timelineResponse)
 	assert.NoError(t, err)
-	assert.Len(t, timelineResponse, 2)
-	assert.Equal(t, expectedTimeline[0].MessageID, timelineResponse[0].MessageID)
-	assert.Equal(t, expectedTimeline[1].MessageID, timelineResponse[1].MessageID)
+	assert.Len(t, timelineResponse.Items, 2)
+	assert.Equal(t, expectedTimeline[0].MessageID, timelineResponse.Items[0].MessageID)
+	assert.Equal(t, expectedTimeline[1].MessageID, timelineResponse.Items[1].MessageID)
+	assert.Equal(t, "next", timelineResponse.NextCursor)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTimeline_EmptyLastPage(t *testing.T) {
+	mockService := &MockTimelineService{}
+	mockConfig := &config.AppConfig{DefaultLimit: 10}
+
+	mockService.On("GetUserTimeline", mock.Anything, "user123", model.PageRequest{Limit: 10, Cursor: "abc"}).Return(model.NewPage([]*model.TimelineItem{}, ""), nil)
+
+	controller := NewTimelineController(mockService, mockConfig)
+
+	req := httptest.NewRequest("GET", "/timeline?cursor=abc", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"items":[]}`, response.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTimeline_EmptyFirstPageWithMore(t *testing.T) {
+	mockService := &MockTimelineService{}
+	mockConfig := &config.AppConfig{DefaultLimit: 10}
+
+	mockService.On("GetUserTimeline", mock.Anything, "user123", model.PageRequest{Limit: 10}).Return(model.NewPage([]*model.TimelineItem{}, "next"), nil)
+
+	controller := NewTimelineController(mockService, mockConfig)
+
+	req := httptest.NewRequest("GET", "/timeline", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"items":[],"next_cursor":"next"}`, response.Body.String())
 
 	mockService.AssertExpectations(t)
 }
+
+func TestGetMentions_Empty(t *testing.T) {
+	mockService := &MockTimelineService{}
+	mockConfig := &config.AppConfig{DefaultLimit: 10}
+
+	mockService.On("GetMentions", mock.Anything, "user123", model.PageRequest{Limit: 10}).Return(model.NewPage([]*model.TimelineItem{}, ""), nil)
+
+	controller := NewTimelineController(mockService, mockConfig)
+
+	req := httptest.NewRequest("GET", "/mentions", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.JSONEq(t, `{"items":[]}`, response.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetMentions_MissingUserID(t *testing.T) {
+	mockService := &MockTimelineService{}
+	controller := NewTimelineController(mockService, &config.AppConfig{DefaultLimit: 10})
+
+	req := httptest.NewRequest("GET", "/mentions", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+	mockService.AssertNotCalled(t, "GetMentions", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestGetTimeline_ExpandAuthor(t *testing.T) {
+	mockService := &MockTimelineService{}
+	mockConfig := &config.AppConfig{DefaultLimit: 10}
+
+	items := []*model.TimelineItem{{MessageID: "msg1", UserID: "user123", AuthorID: "user456"}}
+	mockService.On("GetUserTimeline", mock.Anything, "user123", model.PageRequest{Limit: 10}).Return(model.NewPage(items, ""), nil)
+	mockService.On("EmbedAuthors", mock.Anything, items).Run(func(args mock.Arguments) {
+		args.Get(1).([]*model.TimelineItem)[0].Author = &model.UserSummary{UserID: "user456", Handle: "bob"}
+	}).Return(nil)
+
+	controller := NewTimelineController(mockService, mockConfig)
+
+	req := httptest.NewRequest("GET", "/timeline?expand=author", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	var timeline model.Page[*model.TimelineItem]
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &timeline))
+	if assert.Len(t, timeline.Items, 1) && assert.NotNil(t, timeline.Items[0].Author) {
+		assert.Equal(t, "bob", timeline.Items[0].Author.Handle)
+	}
+
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTimeline_InvalidExpand(t *testing.T) {
+	mockService := &MockTimelineService{}
+	controller := NewTimelineController(mockService, &config.AppConfig{DefaultLimit: 10})
+
+	req := httptest.NewRequest("GET", "/timeline?expand=everything", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	mockService.AssertNotCalled(t, "GetUserTimeline")
+}
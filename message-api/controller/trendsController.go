@@ -0,0 +1,64 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"time"
+
+	"mensajesService/components/config"
+	"mensajesService/components/logger"
+	"mensajesService/components/metrics"
+	"mensajesService/message-api/service"
+
+	"github.com/go-chi/chi/v5"
+)
+
+type TrendsController struct {
+	trendsService service.TrendsServiceInterface
+	config        *config.AppConfig
+}
+
+func NewTrendsController(trendsService service.TrendsServiceInterface, cfg *config.AppConfig) *TrendsController {
+	return &TrendsController{
+		trendsService: trendsService,
+		config:        cfg,
+	}
+}
+
+func (c *TrendsController) MountIn(r chi.Router) {
+	r.Get("/trends", c.GetTrends)
+}
+
+// GetTrends returns the top hashtags of a window, the first configured one
+// by default.
+func (c *TrendsController) GetTrends(w http.ResponseWriter, r *http.Request) {
+	var defaultWindow time.Duration
+	if windows := c.trendsService.Windows(); len(windows) > 0 {
+		defaultWindow = windows[0]
+	}
+
+	window, limit, err := parseTrendsRequest(r, c.config, defaultWindow)
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricTrendsError, 1)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	trends, err := c.trendsService.GetTrends(window, limit)
+	if errors.Is(err, service.ErrUnknownTrendWindow) {
+		metrics.PutCountMetric(metrics.MetricTrendsError, 1)
+		http.Error(w, "Unknown trend window", http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricTrendsError, 1)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		logger.LogError("GetTrends error", "error", err, "window", window.String())
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricTrendsSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(trends)
+}
@@ -0,0 +1,114 @@
+package controller
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"mensajesService/components/config"
+	"mensajesService/message-api/model"
+	"mensajesService/message-api/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockTrendsService struct {
+	mock.Mock
+}
+
+var _ service.TrendsServiceInterface = (*MockTrendsService)(nil)
+
+func (m *MockTrendsService) GetTrends(window time.Duration, limit int) (*model.Trends, error) {
+	args := m.Called(window, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Trends), args.Error(1)
+}
+
+func (m *MockTrendsService) Windows() []time.Duration {
+	args := m.Called()
+	return args.Get(0).([]time.Duration)
+}
+
+func TestGetTrends_DefaultsToFirstWindow(t *testing.T) {
+	mockService := &MockTrendsService{}
+	controller := NewTrendsController(mockService, &config.AppConfig{TrendTopN: 10})
+
+	trends := &model.Trends{Window: "1h", UpdatedAt: time.Now(), Items: []*model.Trend{{Tag: "go", Count: 3, Score: 2.5}}}
+	mockService.On("Windows").Return([]time.Duration{time.Hour, 24 * time.Hour})
+	mockService.On("GetTrends", time.Hour, 10).Return(trends, nil)
+
+	req := httptest.NewRequest("GET", "/trends", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	var body model.Trends
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &body))
+	assert.Equal(t, "1h", body.Window)
+	if assert.Len(t, body.Items, 1) {
+		assert.Equal(t, "go", body.Items[0].Tag)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTrends_WindowAndClampedLimit(t *testing.T) {
+	mockService := &MockTrendsService{}
+	controller := NewTrendsController(mockService, &config.AppConfig{TrendTopN: 10})
+
+	mockService.On("Windows").Return([]time.Duration{time.Hour, 24 * time.Hour})
+	mockService.On("GetTrends", 24*time.Hour, 10).Return(&model.Trends{Window: "24h", Items: []*model.Trend{}}, nil)
+
+	req := httptest.NewRequest("GET", "/trends?window=24h&limit=50", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusOK, response.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestGetTrends_InvalidWindow(t *testing.T) {
+	mockService := &MockTrendsService{}
+	controller := NewTrendsController(mockService, &config.AppConfig{TrendTopN: 10})
+
+	mockService.On("Windows").Return([]time.Duration{time.Hour})
+
+	req := httptest.NewRequest("GET", "/trends?window=tomorrow", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	mockService.AssertNotCalled(t, "GetTrends", mock.Anything, mock.Anything)
+}
+
+func TestGetTrends_UnknownWindow(t *testing.T) {
+	mockService := &MockTrendsService{}
+	controller := NewTrendsController(mockService, &config.AppConfig{TrendTopN: 10})
+
+	mockService.On("Windows").Return([]time.Duration{time.Hour})
+	mockService.On("GetTrends", 2*time.Hour, 10).Return(nil, service.ErrUnknownTrendWindow)
+
+	req := httptest.NewRequest("GET", "/trends?window=2h", nil)
+	response := httptest.NewRecorder()
+
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	assert.Equal(t, "Unknown trend window\n", response.Body.String())
+	mockService.AssertExpectations(t)
+}
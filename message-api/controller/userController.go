@@ -0,0 +1,161 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"unicode/utf8"
+
+	"mensajesService/components/config"
+	"mensajesService/components/logger"
+	"mensajesService/components/metrics"
+	"mensajesService/message-api/model"
+	"mensajesService/message-api/service"
+	"mensajesService/message-api/web"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// Profile field limits, in characters.
+const (
+	maxDisplayNameLength = 50
+	maxBioLength         = 160
+	maxAvatarURLLength   = 2048
+)
+
+type UserController struct {
+	userService service.UserServiceInterface
+	config      *config.AppConfig
+}
+
+func NewUserController(userService service.UserServiceInterface, cfg *config.AppConfig) *UserController {
+	return &UserController{
+		userService: userService,
+		config:      cfg,
+	}
+}
+
+func (c *UserController) MountIn(r chi.Router) {
+	r.Get("/users/by-handle/{handle}", c.GetUserByHandle)
+	r.Get("/users/{id}", c.GetUser)
+	r.Put("/users/{id}", c.UpdateUser)
+}
+
+// GetUser serves profiles, so it doesn't require a caller identity.
+func (c *UserController) GetUser(w http.ResponseWriter, r *http.Request) {
+	userID := chi.URLParam(r, "id")
+	user, err := c.userService.GetUser(r.Context(), userID)
+	c.writeUser(w, "GetUser", userID, user, err)
+}
+
+func (c *UserController) GetUserByHandle(w http.ResponseWriter, r *http.Request) {
+	handle := chi.URLParam(r, "handle")
+	user, err := c.userService.GetUserByHandle(r.Context(), handle)
+	if errors.Is(err, service.ErrInvalidHandle) {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		http.Error(w, "Invalid handle", http.StatusBadRequest)
+		return
+	}
+	c.writeUser(w, "GetUserByHandle", handle, user, err)
+}
+
+// UpdateUser creates the caller's profile, answering 201, or changes the
+// fields present in the body.
+func (c *UserController) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	callerID := web.UserIDFromContext(r.Context())
+	if callerID == "" {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		http.Error(w, "User ID required", http.StatusUnauthorized)
+		return
+	}
+
+	userID := chi.URLParam(r, "id")
+	if userID != callerID {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		http.Error(w, "Cannot update another user's profile", http.StatusForbidden)
+		return
+	}
+
+	var request model.UpdateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	if message := validateProfile(&request); message != "" {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		http.Error(w, message, http.StatusBadRequest)
+		return
+	}
+
+	user, created, err := c.userService.UpdateUser(r.Context(), userID, &request)
+	if errors.Is(err, service.ErrHandleRequired) {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		http.Error(w, "Handle is required", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidHandle) {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		http.Error(w, "Invalid handle", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrHandleTaken) {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		http.Error(w, "Handle already taken", http.StatusConflict)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		logger.LogError("UpdateUser error", "error", err, "user_id", userID)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricUserSuccess, 1)
+	logger.LogInfo("UpdateUser success", "user_id", userID, "created", created)
+	w.Header().Set("Content-Type", "application/json")
+	if created {
+		w.WriteHeader(http.StatusCreated)
+	}
+	json.NewEncoder(w).Encode(user)
+}
+
+func (c *UserController) writeUser(w http.ResponseWriter, operation, lookup string, user *model.User, err error) {
+	if errors.Is(err, service.ErrUserNotFound) {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		http.Error(w, "User not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		metrics.PutCountMetric(metrics.MetricUserError, 1)
+		logger.LogError(operation+" error", "error", err, "lookup", lookup)
+		http.Error(w, "Internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	metrics.PutCountMetric(metrics.MetricUserSuccess, 1)
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(user)
+}
+
+// validateProfile checks the free-form fields of request and returns what is
+// wrong with them, if anything. Handles are checked by the service.
+func validateProfile(request *model.UpdateUserRequest) string {
+	if request.DisplayName != nil && utf8.RuneCountInString(*request.DisplayName) > maxDisplayNameLength {
+		return "Display name too long"
+	}
+	if request.Bio != nil && utf8.RuneCountInString(*request.Bio) > maxBioLength {
+		return "Bio too long"
+	}
+	if request.AvatarURL != nil && *request.AvatarURL != "" {
+		if len(*request.AvatarURL) > maxAvatarURLLength {
+			return "Avatar URL too long"
+		}
+		avatarURL, err := url.Parse(*request.AvatarURL)
+		if err != nil || (avatarURL.Scheme != "http" && avatarURL.Scheme != "https") || avatarURL.Host == "" {
+			return "Avatar URL must be an http or https URL"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,163 @@
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mensajesService/components/config"
+	"mensajesService/message-api/model"
+	"mensajesService/message-api/service"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type MockUserService struct {
+	mock.Mock
+}
+
+var _ service.UserServiceInterface = (*MockUserService)(nil)
+
+func (m *MockUserService) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserService) GetUserByHandle(ctx context.Context, handle string) (*model.User, error) {
+	args := m.Called(ctx, handle)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.User), args.Error(1)
+}
+
+func (m *MockUserService) UpdateUser(ctx context.Context, userID string, request *model.UpdateUserRequest) (*model.User, bool, error) {
+	args := m.Called(ctx, userID, request)
+	if args.Get(0) == nil {
+		return nil, false, args.Error(2)
+	}
+	return args.Get(0).(*model.User), args.Bool(1), args.Error(2)
+}
+
+func serveUser(controller *UserController, req *http.Request) *httptest.ResponseRecorder {
+	response := httptest.NewRecorder()
+	router := newTestRouter()
+	controller.MountIn(router)
+	router.ServeHTTP(response, req)
+	return response
+}
+
+func TestGetUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		user           *model.User
+		err            error
+		expectedStatus int
+	}{
+		{"found", &model.User{UserID: "user123", Handle: "alice"}, nil, http.StatusOK},
+		{"no profile", nil, service.ErrUserNotFound, http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockUserService{}
+			mockService.On("GetUser", mock.Anything, "user123").Return(tt.user, tt.err)
+
+			response := serveUser(NewUserController(mockService, &config.AppConfig{}), httptest.NewRequest("GET", "/users/user123", nil))
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestGetUserByHandle(t *testing.T) {
+	mockService := &MockUserService{}
+	mockService.On("GetUserByHandle", mock.Anything, "alice").Return(&model.User{UserID: "user123", Handle: "alice"}, nil)
+	mockService.On("GetUserByHandle", mock.Anything, "a b").Return(nil, service.ErrInvalidHandle)
+	controller := NewUserController(mockService, &config.AppConfig{})
+
+	response := serveUser(controller, httptest.NewRequest("GET", "/users/by-handle/alice", nil))
+	assert.Equal(t, http.StatusOK, response.Code)
+	var user model.User
+	assert.NoError(t, json.Unmarshal(response.Body.Bytes(), &user))
+	assert.Equal(t, "user123", user.UserID)
+
+	response = serveUser(controller, httptest.NewRequest("GET", "/users/by-handle/a%20b", nil))
+	assert.Equal(t, http.StatusBadRequest, response.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestUpdateUser(t *testing.T) {
+	tests := []struct {
+		name           string
+		created        bool
+		err            error
+		expectedStatus int
+	}{
+		{"created", true, nil, http.StatusCreated},
+		{"updated", false, nil, http.StatusOK},
+		{"handle taken", false, service.ErrHandleTaken, http.StatusConflict},
+		{"no handle", false, service.ErrHandleRequired, http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := &MockUserService{}
+			var user *model.User
+			if tt.err == nil {
+				user = &model.User{UserID: "user123", Handle: "alice"}
+			}
+			mockService.On("UpdateUser", mock.Anything, "user123", mock.MatchedBy(func(request *model.UpdateUserRequest) bool {
+				return request.Handle != nil && *request.Handle == "alice"
+			})).Return(user, tt.created, tt.err)
+
+			req := httptest.NewRequest("PUT", "/users/user123", bytes.NewBufferString(`{"handle": "alice"}`))
+			req.Header.Set("X-User-ID", "user123")
+			response := serveUser(NewUserController(mockService, &config.AppConfig{}), req)
+
+			assert.Equal(t, tt.expectedStatus, response.Code)
+			mockService.AssertExpectations(t)
+		})
+	}
+}
+
+func TestUpdateUser_OtherUser(t *testing.T) {
+	mockService := &MockUserService{}
+
+	req := httptest.NewRequest("PUT", "/users/user456", bytes.NewBufferString(`{"handle": "alice"}`))
+	req.Header.Set("X-User-ID", "user123")
+	response := serveUser(NewUserController(mockService, &config.AppConfig{}), req)
+
+	assert.Equal(t, http.StatusForbidden, response.Code)
+	mockService.AssertNotCalled(t, "UpdateUser")
+}
+
+func TestUpdateUser_InvalidFields(t *testing.T) {
+	bodies := map[string]string{
+		"bio too long":    `{"bio": "` + strings.Repeat("a", maxBioLength+1) + `"}`,
+		"name too long":   `{"display_name": "` + strings.Repeat("ñ", maxDisplayNameLength+1) + `"}`,
+		"avatar not http": `{"avatar_url": "javascript:alert(1)"}`,
+	}
+
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			mockService := &MockUserService{}
+
+			req := httptest.NewRequest("PUT", "/users/user123", bytes.NewBufferString(body))
+			req.Header.Set("X-User-ID", "user123")
+			response := serveUser(NewUserController(mockService, &config.AppConfig{}), req)
+
+			assert.Equal(t, http.StatusBadRequest, response.Code)
+			mockService.AssertNotCalled(t, "UpdateUser")
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package model
+
+import "strings"
+
+// Entities are the structured parts found in a message's content. Offsets
+// count Unicode characters (code points), not bytes; Start is inclusive and
+// End exclusive.
+type Entities struct {
+	Mentions []Mention `json:"mentions,omitempty" dynamodbav:"mentions,omitempty"`
+	Hashtags []Hashtag `json:"hashtags,omitempty" dynamodbav:"hashtags,omitempty"`
+}
+
+// Mention is an @username in the content. Usernames are user IDs.
+type Mention struct {
+	Username string `json:"username" dynamodbav:"username"`
+	Start    int    `json:"start" dynamodbav:"start"`
+	End      int    `json:"end" dynamodbav:"end"`
+}
+
+// Hashtag is a #tag in the content. Tag keeps the case it was written in;
+// tags that differ only in case are the same topic.
+type Hashtag struct {
+	Tag   string `json:"tag" dynamodbav:"tag"`
+	Start int    `json:"start" dynamodbav:"start"`
+	End   int    `json:"end" dynamodbav:"end"`
+}
+
+// MentionedUsers lists each mentioned user once, in order of appearance.
+func (e *Entities) MentionedUsers() []string {
+	if e == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(e.Mentions))
+	var users []string
+	for _, mention := range e.Mentions {
+		if !seen[mention.Username] {
+			seen[mention.Username] = true
+			users = append(users, mention.Username)
+		}
+	}
+	return users
+}
+
+// HashtagKeys lists each hashtag once, lowercased, in order of appearance.
+func (e *Entities) HashtagKeys() []string {
+	if e == nil {
+		return nil
+	}
+	seen := make(map[string]bool, len(e.Hashtags))
+	var keys []string
+	for _, hashtag := range e.Hashtags {
+		key := strings.ToLower(hashtag.Tag)
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
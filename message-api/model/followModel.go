@@ -13,3 +13,23 @@ type Follow struct {
 type FollowRequest struct {
 	FollowingID string `json:"following_id"`
 }
+
+type FollowCounts struct {
+	UserID         string `json:"user_id" dynamodbav:"user_id"`
+	FollowersCount int64  `json:"followers_count" dynamodbav:"followers_count"`
+	FollowingCount int64  `json:"following_count" dynamodbav:"following_count"`
+}
+
+// Outcomes of a follow: private accounts turn it into a pending request.
+const (
+	FollowStatusFollowing = "following"
+	FollowStatusPending   = "pending"
+)
+
+// PendingFollow is a request from RequesterID to follow the private account
+// UserID, waiting for UserID to approve or reject it.
+type PendingFollow struct {
+	UserID      string    `json:"user_id" dynamodbav:"user_id"`
+	RequesterID string    `json:"requester_id" dynamodbav:"requester_id"`
+	CreatedAt   time.Time `json:"created_at" dynamodbav:"created_at"`
+}
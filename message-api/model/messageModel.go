@@ -9,4 +9,54 @@ type Message struct {
 	UserID    string    `json:"user_id" dynamodbav:"user_id"`
 	Content   string    `json:"content" dynamodbav:"content"`
 	CreatedAt time.Time `json:"created_at" dynamodbav:"created_at"`
+	Entities  *Entities `json:"entities,omitempty" dynamodbav:"entities,omitempty"`
+	// InReplyTo and InReplyToUserID point at the parent of a reply.
+	InReplyTo       string `json:"in_reply_to,omitempty" dynamodbav:"in_reply_to,omitempty"`
+	InReplyToUserID string `json:"in_reply_to_user_id,omitempty" dynamodbav:"in_reply_to_user_id,omitempty"`
+	// ConversationID is the ID of the message that started the thread; a
+	// message that isn't a reply is its own conversation.
+	ConversationID string `json:"conversation_id,omitempty" dynamodbav:"conversation_id,omitempty"`
+	// RepostOf is set on reposts, which have no content of their own, and
+	// QuoteOf on quote posts, which add commentary to the original.
+	RepostOf string `json:"repost_of,omitempty" dynamodbav:"repost_of,omitempty"`
+	QuoteOf  string `json:"quote_of,omitempty" dynamodbav:"quote_of,omitempty"`
+	// OriginalUserID and OriginalCreatedAt are the key of the reposted or
+	// quoted message, so timelines can read originals in a batch. Messages
+	// stored before they were recorded don't have them.
+	OriginalUserID    string     `json:"-" dynamodbav:"original_user_id,omitempty"`
+	OriginalCreatedAt *time.Time `json:"-" dynamodbav:"original_created_at,omitempty"`
+	// LikeCount is maintained asynchronously and may briefly lag the likes.
+	LikeCount int64 `json:"like_count" dynamodbav:"like_count,omitempty"`
+	// LikedByMe is only set when the caller is known.
+	LikedByMe *bool `json:"liked_by_me,omitempty" dynamodbav:"-"`
+	// Author is only embedded on request, and only for users with a profile.
+	Author *UserSummary `json:"author,omitempty" dynamodbav:"-"`
+}
+
+// RepostRequest is the optional body of POST /message/{id}/repost; content
+// turns the repost into a quote post.
+type RepostRequest struct {
+	Content string `json:"content,omitempty"`
+}
+
+type CreateMessageRequest struct {
+	Content   string `json:"content"`
+	InReplyTo string `json:"in_reply_to,omitempty"`
+}
+
+// Thread is a conversation seen from one of its messages: the chain of
+// parents above it and the tree of replies below it.
+type Thread struct {
+	ConversationID string      `json:"conversation_id"`
+	Ancestors      []*Message  `json:"ancestors"`
+	Root           *ThreadNode `json:"root"`
+	// Truncated is set when the conversation was too large to load whole.
+	Truncated bool `json:"truncated,omitempty"`
+}
+
+type ThreadNode struct {
+	Message *Message      `json:"message"`
+	Replies []*ThreadNode `json:"replies"`
+	// MoreReplies is set on nodes at the depth limit that have replies.
+	MoreReplies bool `json:"more_replies,omitempty"`
 }
@@ -0,0 +1,34 @@
+package model
+
+import "time"
+
+type PageRequest struct {
+	Limit  int
+	Cursor string
+}
+
+// TimeRange filters a listing by creation time. Both bounds are exclusive and
+// a zero bound is open.
+type TimeRange struct {
+	After  time.Time
+	Before time.Time
+}
+
+func (r TimeRange) Contains(t time.Time) bool {
+	return (r.After.IsZero() || t.After(r.After)) && (r.Before.IsZero() || t.Before(r.Before))
+}
+
+type Page[T any] struct {
+	Items      []T    `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+func NewPage[T any](items []T, nextCursor string) *Page[T] {
+	if items == nil {
+		items = []T{}
+	}
+	return &Page[T]{
+		Items:      items,
+		NextCursor: nextCursor,
+	}
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// AccountSettings are a user's account-wide preferences. A private account
+// approves each follower and shows its messages only to them.
+type AccountSettings struct {
+	UserID    string    `json:"user_id" dynamodbav:"user_id"`
+	Private   bool      `json:"private" dynamodbav:"private"`
+	UpdatedAt time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// UpdateSettingsRequest holds the settings to change; omitted fields keep
+// their value.
+type UpdateSettingsRequest struct {
+	Private *bool `json:"private"`
+}
@@ -5,9 +5,38 @@ import (
 )
 
 type TimelineItem struct {
-	MessageID string    `json:"message_id" dynamodbav:"message_id"`
-	UserID    string    `json:"user_id" dynamodbav:"user_id"`
-	AuthorID  string    `json:"author_id" dynamodbav:"author_id"`
-	Content   string    `json:"content" dynamodbav:"content"`
-	CreatedAt time.Time `json:"created_at" dynamodbav:"created_at"`
+	MessageID      string    `json:"message_id" dynamodbav:"message_id"`
+	UserID         string    `json:"user_id" dynamodbav:"user_id"`
+	AuthorID       string    `json:"author_id" dynamodbav:"author_id"`
+	Content        string    `json:"content" dynamodbav:"content"`
+	CreatedAt      time.Time `json:"created_at" dynamodbav:"created_at"`
+	Entities       *Entities `json:"entities,omitempty" dynamodbav:"entities,omitempty"`
+	InReplyTo      string    `json:"in_reply_to,omitempty" dynamodbav:"in_reply_to,omitempty"`
+	ConversationID string    `json:"conversation_id,omitempty" dynamodbav:"conversation_id,omitempty"`
+	RepostOf       string    `json:"repost_of,omitempty" dynamodbav:"repost_of,omitempty"`
+	QuoteOf        string    `json:"quote_of,omitempty" dynamodbav:"quote_of,omitempty"`
+	// Original is the reposted or quoted message, filled in when the timeline
+	// is read so it never shows a deleted original. It isn't stored.
+	Original *Message `json:"original,omitempty" dynamodbav:"-"`
+	// LikeCount and LikedByMe are read from the message, not stored with the item.
+	LikeCount int64 `json:"like_count" dynamodbav:"-"`
+	LikedByMe *bool `json:"liked_by_me,omitempty" dynamodbav:"-"`
+	// Author is only embedded on request, and only for users with a profile.
+	Author *UserSummary `json:"author,omitempty" dynamodbav:"-"`
+}
+
+// NewTimelineItem is the copy of message shown in userID's timeline.
+func NewTimelineItem(userID string, message *Message) *TimelineItem {
+	return &TimelineItem{
+		MessageID:      message.ID,
+		UserID:         userID,
+		AuthorID:       message.UserID,
+		Content:        message.Content,
+		CreatedAt:      message.CreatedAt,
+		Entities:       message.Entities,
+		InReplyTo:      message.InReplyTo,
+		ConversationID: message.ConversationID,
+		RepostOf:       message.RepostOf,
+		QuoteOf:        message.QuoteOf,
+	}
 }
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// Trend is a hashtag's activity within a window. Count is the number of
+// messages; Score weighs recent ones more and decides the ranking.
+type Trend struct {
+	Tag   string  `json:"tag"`
+	Count int64   `json:"count"`
+	Score float64 `json:"score"`
+}
+
+// Trends are the top hashtags of a window as of UpdatedAt. UpdatedAt is zero
+// until the first refresh has run.
+type Trends struct {
+	Window    string    `json:"window"`
+	UpdatedAt time.Time `json:"updated_at"`
+	Items     []*Trend  `json:"items"`
+}
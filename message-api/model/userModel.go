@@ -0,0 +1,43 @@
+package model
+
+import "time"
+
+// User is a profile. UserID is the identity callers authenticate as; Handle
+// is the unique, user-chosen name shown to others.
+type User struct {
+	UserID      string    `json:"user_id" dynamodbav:"user_id"`
+	Handle      string    `json:"handle" dynamodbav:"handle"`
+	DisplayName string    `json:"display_name,omitempty" dynamodbav:"display_name,omitempty"`
+	Bio         string    `json:"bio,omitempty" dynamodbav:"bio,omitempty"`
+	AvatarURL   string    `json:"avatar_url,omitempty" dynamodbav:"avatar_url,omitempty"`
+	CreatedAt   time.Time `json:"created_at" dynamodbav:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at" dynamodbav:"updated_at"`
+}
+
+// UpdateUserRequest is the body of PUT /users/{id}. Omitted fields keep their
+// value; an empty string clears all but the handle.
+type UpdateUserRequest struct {
+	Handle      *string `json:"handle"`
+	DisplayName *string `json:"display_name"`
+	Bio         *string `json:"bio"`
+	AvatarURL   *string `json:"avatar_url"`
+}
+
+// UserSummary is the part of a profile embedded next to the messages a user
+// wrote.
+type UserSummary struct {
+	UserID      string `json:"user_id"`
+	Handle      string `json:"handle"`
+	DisplayName string `json:"display_name,omitempty"`
+	AvatarURL   string `json:"avatar_url,omitempty"`
+}
+
+// Summary is the part of u embedded in messages.
+func (u *User) Summary() *UserSummary {
+	return &UserSummary{
+		UserID:      u.UserID,
+		Handle:      u.Handle,
+		DisplayName: u.DisplayName,
+		AvatarURL:   u.AvatarURL,
+	}
+}
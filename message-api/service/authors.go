@@ -0,0 +1,69 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"mensajesService/components/database"
+	"mensajesService/message-api/model"
+)
+
+// EmbedAuthors sets the author summary of each message.
+func (s *MessageService) EmbedAuthors(ctx context.Context, messages []*model.Message) error {
+	authorIDs := make([]string, 0, len(messages))
+	for _, message := range messages {
+		authorIDs = append(authorIDs, message.UserID)
+	}
+	summaries, err := authorSummaries(ctx, s.dbClient, authorIDs)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		message.Author = summaries[message.UserID]
+	}
+	return nil
+}
+
+// EmbedAuthors sets the author summary of each item and of the original it
+// reposts or quotes.
+func (s *TimelineService) EmbedAuthors(ctx context.Context, items []*model.TimelineItem) error {
+	authorIDs := make([]string, 0, len(items))
+	for _, item := range items {
+		authorIDs = append(authorIDs, item.AuthorID)
+		if item.Original != nil {
+			authorIDs = append(authorIDs, item.Original.UserID)
+		}
+	}
+	summaries, err := authorSummaries(ctx, s.dbClient, authorIDs)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		item.Author = summaries[item.AuthorID]
+		if item.Original != nil {
+			item.Original.Author = summaries[item.Original.UserID]
+		}
+	}
+	return nil
+}
+
+// authorSummaries reads the profile of each author once. Authors without a
+// profile map to nil.
+func authorSummaries(ctx context.Context, dbClient database.DDBClientInterface, authorIDs []string) (map[string]*model.UserSummary, error) {
+	summaries := make(map[string]*model.UserSummary, len(authorIDs))
+	for _, authorID := range authorIDs {
+		if _, ok := summaries[authorID]; ok {
+			continue
+		}
+		user, err := getUser(ctx, dbClient, authorID)
+		if errors.Is(err, ErrUserNotFound) {
+			summaries[authorID] = nil
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		summaries[authorID] = user.Summary()
+	}
+	return summaries, nil
+}
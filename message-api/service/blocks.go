@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"mensajesService/components/database"
+	"mensajesService/components/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// relation is a row of the blocks or mutes table: UserID blocks or mutes
+// TargetID.
+type relation struct {
+	UserID    string    `dynamodbav:"user_id"`
+	TargetID  string    `dynamodbav:"target_id"`
+	CreatedAt time.Time `dynamodbav:"created_at"`
+}
+
+// BlockUser stops targetID from following userID or seeing userID's messages
+// fanned out, and the other way round. Existing follows in both directions
+// are removed. It reports whether the block is new; blocking again still
+// removes the follows, in case a previous attempt stopped half way.
+func (s *FollowService) BlockUser(ctx context.Context, userID, targetID string) (bool, error) {
+	created, err := putRelation(ctx, s.dbClient, s.dbClient.GetBlocksTableName(), userID, targetID)
+	if err != nil {
+		return false, err
+	}
+
+	if _, err := s.UnfollowUser(ctx, userID, targetID); err != nil {
+		return false, err
+	}
+	if _, err := s.UnfollowUser(ctx, targetID, userID); err != nil {
+		return false, err
+	}
+
+	logger.LogInfo("Block finished successfully", "user_id", userID, "target_id", targetID, "created", created)
+	return created, nil
+}
+
+// UnblockUser lifts a block and reports whether there was one. Follows removed
+// by the block are not restored.
+func (s *FollowService) UnblockUser(ctx context.Context, userID, targetID string) (bool, error) {
+	return deleteRelation(ctx, s.dbClient, s.dbClient.GetBlocksTableName(), userID, targetID)
+}
+
+// MuteUser hides targetID's messages from userID's timeline without
+// unfollowing. It reports whether the mute is new.
+func (s *FollowService) MuteUser(ctx context.Context, userID, targetID string) (bool, error) {
+	return putRelation(ctx, s.dbClient, s.dbClient.GetMutesTableName(), userID, targetID)
+}
+
+// UnmuteUser lifts a mute and reports whether there was one.
+func (s *FollowService) UnmuteUser(ctx context.Context, userID, targetID string) (bool, error) {
+	return deleteRelation(ctx, s.dbClient, s.dbClient.GetMutesTableName(), userID, targetID)
+}
+
+func putRelation(ctx context.Context, dbClient database.DDBClientInterface, tableName, userID, targetID string) (bool, error) {
+	item, err := attributevalue.MarshalMap(relation{UserID: userID, TargetID: targetID, CreatedAt: time.Now()})
+	if err != nil {
+		return false, err
+	}
+
+	err = dbClient.PutItemWithCondition(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(tableName),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(user_id)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func deleteRelation(ctx context.Context, dbClient database.DDBClientInterface, tableName, userID, targetID string) (bool, error) {
+	result, err := dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(tableName),
+		Key:          relationKey(userID, targetID),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(result.Attributes) > 0, nil
+}
+
+// isBlocked reports whether either user blocks the other.
+func isBlocked(ctx context.Context, dbClient database.DDBClientInterface, userID, otherID string) (bool, error) {
+	for _, key := range []map[string]types.AttributeValue{relationKey(userID, otherID), relationKey(otherID, userID)} {
+		result, err := dbClient.GetItem(ctx, dbClient.GetBlocksTableName(), key)
+		if err != nil {
+			return false, err
+		}
+		if len(result.Item) > 0 {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// blockedUsers lists the users userID blocks or is blocked by.
+func blockedUsers(ctx context.Context, dbClient database.DDBClientInterface, userID string) (map[string]bool, error) {
+	users := map[string]bool{}
+	err := collectRelations(ctx, dbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(dbClient.GetBlocksTableName()),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+	}, func(r relation) { users[r.TargetID] = true })
+	if err != nil {
+		return nil, err
+	}
+
+	err = collectRelations(ctx, dbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(dbClient.GetBlocksTableName()),
+		IndexName:              aws.String("BlockedByIndex"),
+		KeyConditionExpression: aws.String("target_id = :target_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":target_id": &types.AttributeValueMemberS{Value: userID},
+		},
+	}, func(r relation) { users[r.UserID] = true })
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// mutedUsers lists the users userID mutes.
+func mutedUsers(ctx context.Context, dbClient database.DDBClientInterface, userID string) (map[string]bool, error) {
+	users := map[string]bool{}
+	err := collectRelations(ctx, dbClient, &dynamodb.QueryInput{
+		TableName:              aws.String(dbClient.GetMutesTableName()),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+	}, func(r relation) { users[r.TargetID] = true })
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// collectRelations reads every page of input.
+func collectRelations(ctx context.Context, dbClient database.DDBClientInterface, input *dynamodb.QueryInput, collect func(relation)) error {
+	for {
+		result, err := dbClient.Query(ctx, input)
+		if err != nil {
+			return err
+		}
+		var relations []relation
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &relations); err != nil {
+			return err
+		}
+		for _, r := range relations {
+			collect(r)
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+func relationKey(userID, targetID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"user_id":   &types.AttributeValueMemberS{Value: userID},
+		"target_id": &types.AttributeValueMemberS{Value: targetID},
+	}
+}
@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mensajesService/message-api/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBlockUser_RemovesFollowsAndPreventsFollowing(t *testing.T) {
+	services := newTestServices(testOptions{})
+	followService, jobs, dbClient := services.follows, services.jobs, services.db
+	ctx := context.Background()
+	follow(t, dbClient, "alice", "bob")
+	follow(t, dbClient, "bob", "alice")
+
+	created, err := followService.BlockUser(ctx, "alice", "bob")
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, []string{JobRemoveAuthorFromTimeline, JobRemoveAuthorFromTimeline}, jobs.jobs)
+
+	for _, pair := range [][2]string{{"alice", "bob"}, {"bob", "alice"}} {
+		following, err := followService.isFollowing(ctx, pair[0], pair[1])
+		require.NoError(t, err)
+		assert.False(t, following, pair)
+		_, err = followService.FollowUser(ctx, pair[0], pair[1])
+		assert.ErrorIs(t, err, ErrFollowBlocked, pair)
+	}
+
+	created, err = followService.BlockUser(ctx, "alice", "bob")
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	existed, err := followService.UnblockUser(ctx, "alice", "bob")
+	require.NoError(t, err)
+	assert.True(t, existed)
+	_, err = followService.FollowUser(ctx, "bob", "alice")
+	assert.NoError(t, err)
+
+	existed, err = followService.UnblockUser(ctx, "alice", "bob")
+	require.NoError(t, err)
+	assert.False(t, existed)
+}
+
+func TestUpdateFollowersTimeline_SkipsBlockedUsers(t *testing.T) {
+	services := newTestServices(testOptions{})
+	followService, timelineService, dbClient := services.follows, services.timeline, services.db
+	ctx := context.Background()
+	follow(t, dbClient, "bob", "alice")
+	follow(t, dbClient, "carol", "alice")
+	follow(t, dbClient, "dave", "carol")
+
+	_, err := followService.BlockUser(ctx, "dave", "alice")
+	require.NoError(t, err)
+	// A follow that slipped in past the block, e.g. written concurrently.
+	follow(t, dbClient, "dave", "alice")
+
+	// A reply to carol reaches carol's followers too, except dave.
+	message := &model.Message{ID: "msg1", UserID: "alice", Content: "@dave hi", CreatedAt: time.Now(), InReplyTo: "parent", InReplyToUserID: "carol"}
+	message.Entities = parseEntities(message.Content)
+	require.NoError(t, timelineService.UpdateFollowersTimeline(ctx, message))
+	require.NoError(t, timelineService.writeMentions(ctx, message))
+
+	assert.Equal(t, []string{"msg1"}, timelineMessageIDs(t, dbClient, "bob"))
+	assert.Equal(t, []string{"msg1"}, timelineMessageIDs(t, dbClient, "carol"))
+	assert.Empty(t, timelineMessageIDs(t, dbClient, "dave"))
+
+	mentions, err := timelineService.GetMentions(ctx, "dave", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, mentions.Items)
+}
+
+func TestGetUserTimeline_FiltersMutedAuthors(t *testing.T) {
+	services := newTestServices(testOptions{})
+	followService, timelineService, dbClient := services.follows, services.timeline, services.db
+	ctx := context.Background()
+	follow(t, dbClient, "bob", "alice")
+	follow(t, dbClient, "bob", "carol")
+
+	now := time.Now()
+	messages := []*model.Message{
+		{ID: "alice1", UserID: "alice", Content: "one", CreatedAt: now.Add(-3 * time.Second)},
+		{ID: "carol1", UserID: "carol", Content: "two", CreatedAt: now.Add(-2 * time.Second)},
+		{ID: "carol2", UserID: "carol", Content: "three", CreatedAt: now.Add(-time.Second)},
+	}
+	for _, message := range messages {
+		require.NoError(t, timelineService.UpdateFollowersTimeline(ctx, message))
+	}
+
+	created, err := followService.MuteUser(ctx, "bob", "carol")
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	// The first page holds only muted items, yet still leads to the next.
+	page, err := timelineService.GetUserTimeline(ctx, "bob", model.PageRequest{Limit: 2})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = timelineService.GetUserTimeline(ctx, "bob", model.PageRequest{Limit: 2, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "alice1", page.Items[0].MessageID)
+
+	following, err := followService.isFollowing(ctx, "bob", "carol")
+	require.NoError(t, err)
+	assert.True(t, following)
+
+	existed, err := followService.UnmuteUser(ctx, "bob", "carol")
+	require.NoError(t, err)
+	assert.True(t, existed)
+	page, err = timelineService.GetUserTimeline(ctx, "bob", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 3)
+}
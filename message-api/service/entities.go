@@ -0,0 +1,101 @@
+package service
+
+import (
+	"unicode"
+
+	"mensajesService/message-api/model"
+)
+
+// maxUsernameLength bounds how much of the text after an @ is taken as a
+// username.
+const maxUsernameLength = 64
+
+// maxHashtagLength bounds how much of the text after a # is taken as a tag.
+const maxHashtagLength = 100
+
+// parseEntities extracts the entities in content, or returns nil if there are
+// none. Offsets are in characters so clients can slice the content as text.
+func parseEntities(content string) *model.Entities {
+	runes := []rune(content)
+	entities := &model.Entities{}
+
+	for i := 0; i < len(runes); i++ {
+		if (runes[i] != '@' && runes[i] != '#') || (i > 0 && isEntityChar(runes[i-1])) {
+			continue
+		}
+
+		if runes[i] == '#' {
+			end := entityEnd(runes, i+1, isHashtagChar)
+			if isHashtag(runes[i+1 : end]) {
+				entities.Hashtags = append(entities.Hashtags, model.Hashtag{
+					Tag:   string(runes[i+1 : end]),
+					Start: i,
+					End:   end,
+				})
+			}
+			i = end - 1
+			continue
+		}
+
+		end := entityEnd(runes, i+1, isUsernameChar)
+		// A username may contain hyphens but doesn't end with one.
+		for end > i+1 && runes[end-1] == '-' {
+			end--
+		}
+		if end == i+1 || end-(i+1) > maxUsernameLength {
+			i = end - 1
+			continue
+		}
+		entities.Mentions = append(entities.Mentions, model.Mention{
+			Username: string(runes[i+1 : end]),
+			Start:    i,
+			End:      end,
+		})
+		i = end - 1
+	}
+
+	if len(entities.Mentions) == 0 && len(entities.Hashtags) == 0 {
+		return nil
+	}
+	return entities
+}
+
+// entityEnd returns the index just past the run of characters accepted by
+// valid, starting at start.
+func entityEnd(runes []rune, start int, valid func(rune) bool) int {
+	end := start
+	for end < len(runes) && valid(runes[end]) {
+		end++
+	}
+	return end
+}
+
+// isEntityChar reports whether r may precede an entity marker without
+// splitting a word, as in an email address or "C#".
+func isEntityChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '@' || r == '#'
+}
+
+func isUsernameChar(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '-')
+}
+
+func isHashtagChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
+
+// isHashtag reports whether tag can be a hashtag: a run of hashtag characters
+// with at least one letter, so "#1" in "item #1" is not a topic.
+func isHashtag(tag []rune) bool {
+	if len(tag) == 0 || len(tag) > maxHashtagLength {
+		return false
+	}
+	hasLetter := false
+	for _, r := range tag {
+		if !isHashtagChar(r) {
+			return false
+		}
+		hasLetter = hasLetter || unicode.IsLetter(r)
+	}
+	return hasLetter
+}
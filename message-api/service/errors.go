@@ -0,0 +1,19 @@
+package service
+
+import "errors"
+
+var (
+	ErrMessageNotFound       = errors.New("message not found")
+	ErrReplyTargetNotFound   = errors.New("in_reply_to message not found")
+	ErrAlreadyReposted       = errors.New("message already reposted")
+	ErrRepostNotFound        = errors.New("repost not found")
+	ErrInvalidHashtag        = errors.New("invalid hashtag")
+	ErrUnknownTrendWindow    = errors.New("unknown trend window")
+	ErrFollowBlocked         = errors.New("follow blocked")
+	ErrFollowRequestNotFound = errors.New("follow request not found")
+	ErrUserNotFound          = errors.New("user not found")
+	ErrHandleRequired        = errors.New("handle is required")
+	ErrInvalidHandle         = errors.New("invalid handle")
+	ErrHandleTaken           = errors.New("handle already taken")
+	ErrPrivateRepost         = errors.New("cannot repost a private account's message")
+)
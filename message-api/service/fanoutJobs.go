@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"mensajesService/components/jobqueue"
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+)
+
+// Job types handled by the fan-out queue. Payloads are JSON and, since a job
+// may run more than once, every handler is idempotent except the counters:
+// a repeated JobRecordHashtags counts its tags again, so trends may slightly
+// overcount, and a repeated JobAdjustLikeCount applies its delta again.
+const (
+	JobUpdateFollowersTimeline     = "update_followers_timeline"
+	JobRemoveFromFollowersTimeline = "remove_from_followers_timeline"
+	JobUpdateFollowerTimeline      = "update_follower_timeline"
+	JobRemoveAuthorFromTimeline    = "remove_author_from_timeline"
+	JobAdjustLikeCount             = "adjust_like_count"
+	JobRecordHashtags              = "record_hashtags"
+)
+
+type followJob struct {
+	FollowerID  string `json:"follower_id"`
+	FollowingID string `json:"following_id"`
+}
+
+// RegisterJobs registers the message fan-out and retraction handlers, which
+// also maintain the mentions feeds.
+func (s *TimelineService) RegisterJobs(queue *jobqueue.Queue) {
+	queue.Register(JobUpdateFollowersTimeline, func(ctx context.Context, payload json.RawMessage) error {
+		var message model.Message
+		if err := json.Unmarshal(payload, &message); err != nil {
+			return err
+		}
+		if err := s.UpdateFollowersTimeline(ctx, &message); err != nil {
+			return err
+		}
+		if err := s.writeMentions(ctx, &message); err != nil {
+			return err
+		}
+
+		// A retried fan-out can land after the message was deleted and retracted.
+		exists, err := messageExists(ctx, s.dbClient, &message)
+		if err != nil {
+			return err
+		}
+		if !exists {
+			logger.LogInfo("Message deleted during fan-out, retracting", "message_id", message.ID)
+			return s.retract(ctx, &message)
+		}
+		return nil
+	})
+
+	queue.Register(JobRemoveFromFollowersTimeline, func(ctx context.Context, payload json.RawMessage) error {
+		var message model.Message
+		if err := json.Unmarshal(payload, &message); err != nil {
+			return err
+		}
+		return s.retract(ctx, &message)
+	})
+}
+
+func (s *TimelineService) retract(ctx context.Context, message *model.Message) error {
+	if err := s.RemoveFromFollowersTimeline(ctx, message); err != nil {
+		return err
+	}
+	return s.removeMentions(ctx, message)
+}
+
+// RegisterJobs registers the like counter handler.
+func (s *MessageService) RegisterJobs(queue *jobqueue.Queue) {
+	queue.Register(JobAdjustLikeCount, func(ctx context.Context, payload json.RawMessage) error {
+		var job LikeCountJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return err
+		}
+		return s.adjustLikeCount(ctx, job.MessageID, job.Delta)
+	})
+}
+
+// RegisterJobs registers the handler that counts new messages towards
+// trending hashtags.
+func (s *TrendsService) RegisterJobs(queue *jobqueue.Queue) {
+	queue.Register(JobRecordHashtags, func(ctx context.Context, payload json.RawMessage) error {
+		var message model.Message
+		if err := json.Unmarshal(payload, &message); err != nil {
+			return err
+		}
+		return s.recordHashtags(ctx, &message)
+	})
+}
+
+// RegisterJobs registers the follow backfill and unfollow purge handlers.
+func (s *FollowService) RegisterJobs(queue *jobqueue.Queue) {
+	queue.Register(JobUpdateFollowerTimeline, func(ctx context.Context, payload json.RawMessage) error {
+		var job followJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return err
+		}
+		return s.updateFollowerTimeline(ctx, job.FollowerID, job.FollowingID)
+	})
+
+	queue.Register(JobRemoveAuthorFromTimeline, func(ctx context.Context, payload json.RawMessage) error {
+		var job followJob
+		if err := json.Unmarshal(payload, &job); err != nil {
+			return err
+		}
+
+		// The user may have followed again since this job was enqueued.
+		following, err := s.isFollowing(ctx, job.FollowerID, job.FollowingID)
+		if err != nil || following {
+			return err
+		}
+		return s.timelineService.RemoveAuthorFromTimeline(ctx, job.FollowerID, job.FollowingID)
+	})
+}
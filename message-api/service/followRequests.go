@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetFollowRequests lists the requests waiting for userID's approval, by
+// requester.
+func (s *FollowService) GetFollowRequests(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.PendingFollow], error) {
+	scope := "follow-requests:" + userID
+	startKey, err := s.cursors.DecodeKey(scope, page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.dbClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.dbClient.GetFollowRequestsTableName()),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+		Limit:             aws.Int32(int32(page.Limit)),
+		ExclusiveStartKey: startKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []*model.PendingFollow
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &requests); err != nil {
+		return nil, err
+	}
+
+	nextCursor, err := s.cursors.EncodeKey(scope, result.LastEvaluatedKey)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewPage(requests, nextCursor), nil
+}
+
+// ApproveFollowRequest turns requesterID's pending request into a follow of
+// userID. It returns ErrFollowRequestNotFound when there is no such request.
+func (s *FollowService) ApproveFollowRequest(ctx context.Context, userID, requesterID string) error {
+	existed, err := s.deleteFollowRequest(ctx, userID, requesterID)
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return ErrFollowRequestNotFound
+	}
+
+	// The request is gone either way; should the follow fail, the requester
+	// can ask again.
+	if err := s.follow(ctx, requesterID, userID); err != nil {
+		return err
+	}
+	logger.LogInfo("Follow request approved", "user_id", userID, "requester_id", requesterID)
+	return nil
+}
+
+// RejectFollowRequest drops requesterID's pending request. It returns
+// ErrFollowRequestNotFound when there is no such request.
+func (s *FollowService) RejectFollowRequest(ctx context.Context, userID, requesterID string) error {
+	existed, err := s.deleteFollowRequest(ctx, userID, requesterID)
+	if err != nil {
+		return err
+	}
+	if !existed {
+		return ErrFollowRequestNotFound
+	}
+	logger.LogInfo("Follow request rejected", "user_id", userID, "requester_id", requesterID)
+	return nil
+}
+
+// requestFollow leaves requesterID's request to follow userID. Asking again
+// keeps the original request.
+func (s *FollowService) requestFollow(ctx context.Context, requesterID, userID string) error {
+	item, err := attributevalue.MarshalMap(&model.PendingFollow{UserID: userID, RequesterID: requesterID, CreatedAt: time.Now()})
+	if err != nil {
+		return err
+	}
+
+	err = s.dbClient.PutItemWithCondition(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.dbClient.GetFollowRequestsTableName()),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(user_id)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionFailed) {
+		return err
+	}
+
+	logger.LogInfo("Follow request sent", "user_id", userID, "requester_id", requesterID)
+	return nil
+}
+
+// deleteFollowRequest reports whether requesterID had a request pending with
+// userID.
+func (s *FollowService) deleteFollowRequest(ctx context.Context, userID, requesterID string) (bool, error) {
+	result, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.dbClient.GetFollowRequestsTableName()),
+		Key: map[string]types.AttributeValue{
+			"user_id":      &types.AttributeValueMemberS{Value: userID},
+			"requester_id": &types.AttributeValueMemberS{Value: requesterID},
+		},
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return false, err
+	}
+	return len(result.Attributes) > 0, nil
+}
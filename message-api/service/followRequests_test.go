@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mensajesService/components/database"
+	"mensajesService/message-api/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func makePrivate(t *testing.T, dbClient database.DDBClientInterface, userID string) {
+	private := true
+	settings, err := (&FollowService{dbClient: dbClient}).UpdateSettings(context.Background(), userID, &model.UpdateSettingsRequest{Private: &private})
+	require.NoError(t, err)
+	require.True(t, settings.Private)
+}
+
+func TestFollowUser_PrivateAccountNeedsApproval(t *testing.T) {
+	services := newTestServices(testOptions{})
+	followService, jobs, dbClient := services.follows, services.jobs, services.db
+	ctx := context.Background()
+	makePrivate(t, dbClient, "alice")
+
+	for i := 0; i < 2; i++ {
+		status, err := followService.FollowUser(ctx, "bob", "alice")
+		require.NoError(t, err)
+		assert.Equal(t, model.FollowStatusPending, status)
+	}
+	_, err := followService.FollowUser(ctx, "carol", "alice")
+	require.NoError(t, err)
+	assert.Empty(t, jobs.jobs)
+
+	requests, err := followService.GetFollowRequests(ctx, "alice", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, requests.Items, 2)
+	assert.Equal(t, "bob", requests.Items[0].RequesterID)
+	assert.Equal(t, "carol", requests.Items[1].RequesterID)
+
+	require.NoError(t, followService.ApproveFollowRequest(ctx, "alice", "bob"))
+	assert.Equal(t, []string{JobUpdateFollowerTimeline}, jobs.jobs)
+	following, err := followService.isFollowing(ctx, "bob", "alice")
+	require.NoError(t, err)
+	assert.True(t, following)
+
+	status, err := followService.FollowUser(ctx, "bob", "alice")
+	require.NoError(t, err)
+	assert.Equal(t, model.FollowStatusFollowing, status)
+
+	require.NoError(t, followService.RejectFollowRequest(ctx, "alice", "carol"))
+	following, err = followService.isFollowing(ctx, "carol", "alice")
+	require.NoError(t, err)
+	assert.False(t, following)
+
+	assert.ErrorIs(t, followService.ApproveFollowRequest(ctx, "alice", "carol"), ErrFollowRequestNotFound)
+	assert.ErrorIs(t, followService.RejectFollowRequest(ctx, "alice", "bob"), ErrFollowRequestNotFound)
+
+	requests, err = followService.GetFollowRequests(ctx, "alice", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, requests.Items)
+}
+
+func TestUnfollowUser_WithdrawsFollowRequest(t *testing.T) {
+	services := newTestServices(testOptions{})
+	followService, dbClient := services.follows, services.db
+	ctx := context.Background()
+	makePrivate(t, dbClient, "alice")
+	_, err := followService.FollowUser(ctx, "bob", "alice")
+	require.NoError(t, err)
+
+	existed, err := followService.UnfollowUser(ctx, "bob", "alice")
+	require.NoError(t, err)
+	assert.True(t, existed)
+
+	assert.ErrorIs(t, followService.ApproveFollowRequest(ctx, "alice", "bob"), ErrFollowRequestNotFound)
+}
+
+func TestUpdateFollowersTimeline_PrivateAuthorReachesOnlyFollowers(t *testing.T) {
+	services := newTestServices(testOptions{})
+	timelineService, dbClient := services.timeline, services.db
+	ctx := context.Background()
+	makePrivate(t, dbClient, "alice")
+	follow(t, dbClient, "bob", "alice")
+	follow(t, dbClient, "dave", "carol")
+
+	// Under the "all" policy a reply to carol would reach dave, and the
+	// mention would reach erin; neither follows alice.
+	message := &model.Message{ID: "msg1", UserID: "alice", Content: "@bob @erin hi", CreatedAt: time.Now(), InReplyTo: "parent", InReplyToUserID: "carol"}
+	message.Entities = parseEntities(message.Content)
+	require.NoError(t, timelineService.UpdateFollowersTimeline(ctx, message))
+	require.NoError(t, timelineService.writeMentions(ctx, message))
+
+	assert.Equal(t, []string{"msg1"}, timelineMessageIDs(t, dbClient, "bob"))
+	assert.Empty(t, timelineMessageIDs(t, dbClient, "dave"))
+
+	mentions, err := timelineService.GetMentions(ctx, "bob", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Len(t, mentions.Items, 1)
+	mentions, err = timelineService.GetMentions(ctx, "erin", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, mentions.Items)
+}
+
+func TestCanViewMessages(t *testing.T) {
+	dbClient := newTestServices(testOptions{}).db
+	ctx := context.Background()
+	follow(t, dbClient, "bob", "alice")
+
+	visible, err := canViewMessages(ctx, dbClient, "", "alice")
+	require.NoError(t, err)
+	assert.True(t, visible, "public account")
+
+	makePrivate(t, dbClient, "alice")
+	for viewer, expected := range map[string]bool{"alice": true, "bob": true, "carol": false, "": false} {
+		visible, err := canViewMessages(ctx, dbClient, viewer, "alice")
+		require.NoError(t, err)
+		assert.Equal(t, expected, visible, viewer)
+	}
+}
+
+func TestVisibleTo_HidesPrivateMessagesFromNonFollowers(t *testing.T) {
+	services := newTestServices(testOptions{})
+	messageService, dbClient := services.messages, services.db
+	ctx := context.Background()
+	makePrivate(t, dbClient, "alice")
+	follow(t, dbClient, "bob", "alice")
+
+	messages := []*model.Message{{ID: "m1", UserID: "alice"}, {ID: "m2", UserID: "carol"}, {ID: "m3", UserID: "alice"}}
+	visible, err := messageService.VisibleTo(ctx, "bob", messages)
+	require.NoError(t, err)
+	assert.Len(t, visible, 3)
+
+	visible, err = messageService.VisibleTo(ctx, "dave", messages)
+	require.NoError(t, err)
+	require.Len(t, visible, 1)
+	assert.Equal(t, "m2", visible[0].ID)
+}
+
+func TestVisibleThread_PrunesPrivateReplies(t *testing.T) {
+	services := newTestServices(testOptions{})
+	messageService, dbClient := services.messages, services.db
+	ctx := context.Background()
+	makePrivate(t, dbClient, "alice")
+
+	newThread := func() *model.Thread {
+		return &model.Thread{
+			Ancestors: []*model.Message{{ID: "m0", UserID: "alice"}},
+			Root: &model.ThreadNode{
+				Message: &model.Message{ID: "m1", UserID: "carol"},
+				Replies: []*model.ThreadNode{
+					{Message: &model.Message{ID: "m2", UserID: "alice"}, Replies: []*model.ThreadNode{{Message: &model.Message{ID: "m3", UserID: "carol"}}}},
+					{Message: &model.Message{ID: "m4", UserID: "dave"}},
+				},
+			},
+		}
+	}
+
+	thread := newThread()
+	found, err := messageService.VisibleThread(ctx, "dave", thread)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Empty(t, thread.Ancestors)
+	require.Len(t, thread.Root.Replies, 1)
+	assert.Equal(t, "m4", thread.Root.Replies[0].Message.ID)
+
+	thread = newThread()
+	found, err = messageService.VisibleThread(ctx, "alice", thread)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Len(t, thread.Ancestors, 1)
+	assert.Len(t, thread.Root.Replies, 2)
+
+	thread = newThread()
+	thread.Root.Message.UserID = "alice"
+	found, err = messageService.VisibleThread(ctx, "", thread)
+	require.NoError(t, err)
+	assert.False(t, found)
+}
+
+func TestLikeAndReply_PrivateMessageLooksMissingToNonFollowers(t *testing.T) {
+	services := newTestServices(testOptions{})
+	messageService, dbClient := services.messages, services.db
+	ctx := context.Background()
+	makePrivate(t, dbClient, "alice")
+	follow(t, dbClient, "bob", "alice")
+	message, err := messageService.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "hi"})
+	require.NoError(t, err)
+
+	_, err = messageService.LikeMessage(ctx, "carol", message.ID)
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+	_, err = messageService.CreateMessage(ctx, "carol", &model.CreateMessageRequest{Content: "reply", InReplyTo: message.ID})
+	assert.ErrorIs(t, err, ErrReplyTargetNotFound)
+
+	created, err := messageService.LikeMessage(ctx, "bob", message.ID)
+	require.NoError(t, err)
+	assert.True(t, created)
+	_, err = messageService.CreateMessage(ctx, "bob", &model.CreateMessageRequest{Content: "reply", InReplyTo: message.ID})
+	assert.NoError(t, err)
+}
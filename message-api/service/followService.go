@@ -2,10 +2,15 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"time"
 
 	"mensajesService/components/database"
+	"mensajesService/components/jobqueue"
 	"mensajesService/components/logger"
+	"mensajesService/components/metrics"
+	"mensajesService/components/pagination"
 	"mensajesService/message-api/model"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -15,24 +20,88 @@ import (
 )
 
 type FollowServiceInterface interface {
-	FollowUser(ctx context.Context, userID, followingID string) error
+	FollowUser(ctx context.Context, userID, followingID string) (string, error)
+	UnfollowUser(ctx context.Context, userID, followingID string) (bool, error)
+	GetFollowers(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Follow], error)
+	GetFollowing(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Follow], error)
+	GetFollowCounts(ctx context.Context, userID string) (*model.FollowCounts, error)
+	BlockUser(ctx context.Context, userID, targetID string) (bool, error)
+	UnblockUser(ctx context.Context, userID, targetID string) (bool, error)
+	MuteUser(ctx context.Context, userID, targetID string) (bool, error)
+	UnmuteUser(ctx context.Context, userID, targetID string) (bool, error)
+	GetFollowRequests(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.PendingFollow], error)
+	ApproveFollowRequest(ctx context.Context, userID, requesterID string) error
+	RejectFollowRequest(ctx context.Context, userID, requesterID string) error
+	GetSettings(ctx context.Context, userID string) (*model.AccountSettings, error)
+	UpdateSettings(ctx context.Context, userID string, request *model.UpdateSettingsRequest) (*model.AccountSettings, error)
 }
 
 type FollowService struct {
 	dbClient        database.DDBClientInterface
 	messageService  MessageServiceInterface
 	timelineService TimelineServiceInterface
+	cursors         *pagination.CursorCodec
+	jobs            jobqueue.Enqueuer
+	heavyAuthors    *HeavyAuthors
+	backfillDepth   BackfillDepth
 }
 
-func NewFollowService(dbClient database.DDBClientInterface, messageService MessageServiceInterface, timelineService TimelineServiceInterface) *FollowService {
+// backfillPageSize is how many messages a follow backfill reads per query.
+const backfillPageSize = 100
+
+// BackfillDepth bounds the history copied into a new follower's timeline: at
+// most MaxMessages messages, none older than Window. Zero leaves a bound off.
+type BackfillDepth struct {
+	MaxMessages int
+	Window      time.Duration
+}
+
+func NewFollowService(dbClient database.DDBClientInterface, messageService MessageServiceInterface, timelineService TimelineServiceInterface, cursors *pagination.CursorCodec, jobs jobqueue.Enqueuer, heavyAuthors *HeavyAuthors, backfillDepth BackfillDepth) *FollowService {
 	return &FollowService{
 		dbClient:        dbClient,
 		messageService:  messageService,
 		timelineService: timelineService,
+		cursors:         cursors,
+		jobs:            jobs,
+		heavyAuthors:    heavyAuthors,
+		backfillDepth:   backfillDepth,
+	}
+}
+
+// FollowUser returns the follow's status: FollowStatusPending when
+// followingID is private and has yet to approve userID, who is left a follow
+// request instead. It returns ErrFollowBlocked when either user blocks the
+// other.
+func (s *FollowService) FollowUser(ctx context.Context, userID, followingID string) (string, error) {
+	blocked, err := isBlocked(ctx, s.dbClient, userID, followingID)
+	if err != nil {
+		return "", err
+	}
+	if blocked {
+		return "", ErrFollowBlocked
+	}
+
+	private, err := isPrivate(ctx, s.dbClient, followingID)
+	if err != nil {
+		return "", err
+	}
+	if private {
+		following, err := s.isFollowing(ctx, userID, followingID)
+		if err != nil {
+			return "", err
+		}
+		if !following {
+			return model.FollowStatusPending, s.requestFollow(ctx, userID, followingID)
+		}
+		return model.FollowStatusFollowing, nil
 	}
+
+	return model.FollowStatusFollowing, s.follow(ctx, userID, followingID)
 }
 
-func (s *FollowService) FollowUser(ctx context.Context, userID, followingID string) error {
+// follow writes the follow and starts the backfill of the follower's
+// timeline. It is idempotent.
+func (s *FollowService) follow(ctx context.Context, userID, followingID string) error {
 	now := time.Now()
 
 	follow := &model.Follow{
@@ -49,63 +118,268 @@ func (s *FollowService) FollowUser(ctx context.Context, userID, followingID stri
 	item["follower_id"] = &types.AttributeValueMemberS{Value: userID}
 	item["following_id"] = &types.AttributeValueMemberS{Value: followingID}
 
-	err = s.dbClient.PutItem(ctx, s.dbClient.GetFollowersTableName(), item)
+	// The condition keeps follows idempotent, so counters are only bumped once.
+	err = s.dbClient.PutItemWithCondition(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.dbClient.GetFollowersTableName()),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(follower_id)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		logger.LogInfo("Follow already exists", "follower_id", userID, "following_id", followingID)
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	go func() {
-		if err := s.updateFollowerTimeline(context.Background(), userID, followingID); err != nil {
-			logger.LogError("Error updating follower timeline", "error", err, "follower_id", userID, "following_id", followingID)
-		}
-	}()
+	s.updateFollowCounts(ctx, userID, followingID, 1)
+
+	// The follow is already written, so a failed enqueue only loses the backfill.
+	job := followJob{FollowerID: userID, FollowingID: followingID}
+	if err := s.jobs.Enqueue(ctx, JobUpdateFollowerTimeline, job); err != nil {
+		metrics.PutCountMetric(metrics.MetricFanoutEnqueueError, 1)
+		logger.LogError("Error enqueueing follower timeline backfill", "error", err, "follower_id", userID, "following_id", followingID)
+	}
 
 	logger.LogInfo("Follow finished successfully", "follower_id", userID, "following_id", followingID)
 	return nil
 }
 
-func (s *FollowService) updateFollowerTimeline(ctx context.Context, followerID, followingID string) error {
-	messages, err := s.messageService.GetUserMessages(ctx, followingID, 100)
+// UnfollowUser removes the follow relationship and purges the author's items
+// from the follower's timeline, or withdraws a pending follow request. It
+// reports whether either existed, so repeated calls are safe.
+func (s *FollowService) UnfollowUser(ctx context.Context, userID, followingID string) (bool, error) {
+	result, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.dbClient.GetFollowersTableName()),
+		Key:          followKey(userID, followingID),
+		ReturnValues: types.ReturnValueAllOld,
+	})
 	if err != nil {
-		logger.LogError("Error getting user messages", "error", err, "following_id", followingID)
-		return err
+		return false, err
 	}
 
-	for _, message := range messages {
-		if err := s.timelineService.UpdateFollowersTimeline(ctx, message); err != nil {
-			continue
+	existed := len(result.Attributes) > 0
+	if existed {
+		s.updateFollowCounts(ctx, userID, followingID, -1)
+
+		job := followJob{FollowerID: userID, FollowingID: followingID}
+		if err := s.jobs.Enqueue(ctx, JobRemoveAuthorFromTimeline, job); err != nil {
+			metrics.PutCountMetric(metrics.MetricFanoutEnqueueError, 1)
+			logger.LogError("Error enqueueing timeline purge", "error", err, "follower_id", userID, "following_id", followingID)
 		}
 	}
 
-	return nil
+	requested, err := s.deleteFollowRequest(ctx, followingID, userID)
+	if err != nil {
+		return false, err
+	}
+	existed = existed || requested
+
+	logger.LogInfo("Unfollow finished successfully", "follower_id", userID, "following_id", followingID, "existed", existed)
+	return existed, nil
 }
 
-func (s *FollowService) getFollowers(ctx context.Context, userID string) ([]string, error) {
-	input := &dynamodb.QueryInput{
+func (s *FollowService) GetFollowers(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Follow], error) {
+	return s.queryFollows(ctx, "followers:"+userID, page, &dynamodb.QueryInput{
 		TableName:              aws.String(s.dbClient.GetFollowersTableName()),
 		IndexName:              aws.String("FollowingIndex"),
 		KeyConditionExpression: aws.String("following_id = :following_id"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
 			":following_id": &types.AttributeValueMemberS{Value: userID},
 		},
+	})
+}
+
+func (s *FollowService) GetFollowing(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Follow], error) {
+	return s.queryFollows(ctx, "following:"+userID, page, &dynamodb.QueryInput{
+		TableName:              aws.String(s.dbClient.GetFollowersTableName()),
+		KeyConditionExpression: aws.String("follower_id = :follower_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":follower_id": &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+}
+
+// GetFollowCounts reads the denormalized counters, so profiles don't need to
+// walk FollowingIndex to show totals.
+func (s *FollowService) GetFollowCounts(ctx context.Context, userID string) (*model.FollowCounts, error) {
+	result, err := s.dbClient.GetItem(ctx, s.dbClient.GetUserStatsTableName(), map[string]types.AttributeValue{
+		"user_id": &types.AttributeValueMemberS{Value: userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	counts := &model.FollowCounts{}
+	if err := attributevalue.UnmarshalMap(result.Item, counts); err != nil {
+		return nil, err
 	}
+	counts.UserID = userID
+	return counts, nil
+}
+
+func (s *FollowService) queryFollows(ctx context.Context, scope string, page model.PageRequest, input *dynamodb.QueryInput) (*model.Page[*model.Follow], error) {
+	startKey, err := s.cursors.DecodeKey(scope, page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	input.ExclusiveStartKey = startKey
+	input.Limit = aws.Int32(int32(page.Limit))
 
 	result, err := s.dbClient.Query(ctx, input)
 	if err != nil {
-		logger.LogError("Error getting followers", "error", err, "following_id", userID)
 		return nil, err
 	}
 
 	var follows []*model.Follow
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &follows)
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &follows); err != nil {
+		return nil, err
+	}
+
+	nextCursor, err := s.cursors.EncodeKey(scope, result.LastEvaluatedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewPage(follows, nextCursor), nil
+}
+
+// updateFollowCounts is best effort: a failure is logged rather than failing
+// a follow that has already been written.
+func (s *FollowService) updateFollowCounts(ctx context.Context, followerID, followingID string, delta int) {
+	if _, err := s.addToCounter(ctx, followerID, "following_count", delta); err != nil {
+		logger.LogError("Error updating following count", "error", err, "user_id", followerID)
+	}
+
+	followersCount, err := s.addToCounter(ctx, followingID, "followers_count", delta)
+	if err != nil {
+		logger.LogError("Error updating followers count", "error", err, "user_id", followingID)
+		return
+	}
+	if err := s.heavyAuthors.Observe(ctx, followingID, followersCount); err != nil {
+		logger.LogError("Error marking heavy author", "error", err, "user_id", followingID)
+	}
+}
+
+// addToCounter returns the counter's value after the update.
+func (s *FollowService) addToCounter(ctx context.Context, userID, counter string, delta int) (int64, error) {
+	result, err := s.dbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.dbClient.GetUserStatsTableName()),
+		Key: map[string]types.AttributeValue{
+			"user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("ADD #counter :delta"),
+		ExpressionAttributeNames: map[string]string{
+			"#counter": counter,
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", delta)},
+		},
+		ReturnValues: types.ReturnValueUpdatedNew,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var value int64
+	if err := attributevalue.Unmarshal(result.Attributes[counter], &value); err != nil {
+		return 0, err
+	}
+	return value, nil
+}
+
+func (s *FollowService) isFollowing(ctx context.Context, userID, followingID string) (bool, error) {
+	return followsUser(ctx, s.dbClient, userID, followingID)
+}
+
+func followKey(followerID, followingID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"follower_id":  &types.AttributeValueMemberS{Value: followerID},
+		"following_id": &types.AttributeValueMemberS{Value: followingID},
+	}
+}
+
+// batchGetFollows returns the follows among keys that exist.
+func batchGetFollows(ctx context.Context, dbClient database.DDBClientInterface, keys []map[string]types.AttributeValue) ([]model.Follow, error) {
+	if len(keys) == 0 {
+		return nil, nil
+	}
+	items, err := dbClient.BatchGetItem(ctx, dbClient.GetFollowersTableName(), keys)
 	if err != nil {
 		return nil, err
 	}
+	var follows []model.Follow
+	if err := attributevalue.UnmarshalListOfMaps(items, &follows); err != nil {
+		return nil, err
+	}
+	return follows, nil
+}
 
-	var followers []string
-	for _, follow := range follows {
-		followers = append(followers, follow.FollowerID)
+// updateFollowerTimeline copies the followed author's recent history into the
+// new follower's timeline only, page by page, up to the configured depth.
+func (s *FollowService) updateFollowerTimeline(ctx context.Context, followerID, followingID string) error {
+	heavy, err := s.heavyAuthors.IsHeavy(ctx, followingID)
+	if err != nil {
+		return err
+	}
+
+	copied := 0
+	if !heavy {
+		copied, err = s.backfill(ctx, followerID, followingID)
+		if err != nil {
+			logger.LogError("Error backfilling follower timeline", "error", err, "follower_id", followerID, "following_id", followingID, "copied", copied)
+			return err
+		}
 	}
 
-	return followers, nil
+	// An unfollow that raced with this backfill may have purged the timeline
+	// before our writes landed.
+	following, err := s.isFollowing(ctx, followerID, followingID)
+	if err != nil {
+		return err
+	}
+	if !following {
+		return s.timelineService.RemoveAuthorFromTimeline(ctx, followerID, followingID)
+	}
+
+	logger.LogInfo("Follower timeline backfilled", "follower_id", followerID, "following_id", followingID, "messages_count", copied)
+	return nil
+}
+
+func (s *FollowService) backfill(ctx context.Context, followerID, followingID string) (int, error) {
+	var window model.TimeRange
+	if s.backfillDepth.Window > 0 {
+		window.After = time.Now().Add(-s.backfillDepth.Window)
+	}
+
+	copied := 0
+	page := model.PageRequest{Limit: backfillPageSize}
+	for {
+		if limit := s.backfillDepth.MaxMessages; limit > 0 {
+			if copied >= limit {
+				return copied, nil
+			}
+			if limit-copied < page.Limit {
+				page.Limit = limit - copied
+			}
+		}
+
+		messages, err := s.messageService.GetUserMessages(ctx, followingID, page, window)
+		if err != nil {
+			return copied, err
+		}
+
+		if len(messages.Items) > 0 {
+			if err := s.timelineService.BackfillTimeline(ctx, followerID, messages.Items); err != nil {
+				return copied, err
+			}
+			copied += len(messages.Items)
+		}
+
+		if messages.NextCursor == "" {
+			return copied, nil
+		}
+		page.Cursor = messages.NextCursor
+	}
 }
@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"mensajesService/components/config"
+	"mensajesService/components/database"
+	"mensajesService/components/logger"
+	"mensajesService/components/pagination"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestDBClient names every table, since tables left unnamed would all be
+// created as "" and overwrite each other.
+func newTestDBClient() *database.MemoryClient {
+	return database.NewMemoryClient(&config.AppConfig{
+		TableMensajesName:        "messages",
+		TableSeguidoresName:      "follows",
+		TableTimelineName:        "timeline",
+		TableUserStatsName:       "user_stats",
+		TableHeavyAuthorsName:    "heavy_authors",
+		TableRepostsName:         "reposts",
+		TableLikesName:           "likes",
+		TableMentionsName:        "mentions",
+		TableHashtagsName:        "hashtags",
+		TableHashtagCountsName:   "hashtag_counts",
+		TableBlocksName:          "blocks",
+		TableMutesName:           "mutes",
+		TableAccountSettingsName: "account_settings",
+		TableFollowRequestsName:  "follow_requests",
+		TableUsersName:           "users",
+		TableHandlesName:         "handles",
+	})
+}
+
+// testServices wires every service over one in-memory database, as main
+// does. Follow jobs are recorded instead of run.
+type testServices struct {
+	db           *database.MemoryClient
+	heavyAuthors *HeavyAuthors
+	messages     *MessageService
+	timeline     *TimelineService
+	follows      *FollowService
+	users        *UserService
+	trends       *TrendsService
+	jobs         *recordingEnqueuer
+}
+
+// testOptions changes what newTestServices builds. The zero value uses the
+// "all" reply policy, no backfill limits and no heavy authors.
+type testOptions struct {
+	replyFanout    string
+	backfill       BackfillDepth
+	heavyThreshold int
+}
+
+func newTestServices(opts testOptions) *testServices {
+	logger.Init()
+	if opts.replyFanout == "" {
+		opts.replyFanout = config.ReplyFanoutAll
+	}
+
+	dbClient := newTestDBClient()
+	cursors := pagination.NewCursorCodec("secret")
+	heavyAuthors := NewHeavyAuthors(dbClient, opts.heavyThreshold)
+	messageService := NewMessageService(dbClient, cursors)
+	timelineService := NewTimelineService(dbClient, cursors, heavyAuthors, opts.replyFanout)
+	jobs := &recordingEnqueuer{}
+	return &testServices{
+		db:           dbClient,
+		heavyAuthors: heavyAuthors,
+		messages:     messageService,
+		timeline:     timelineService,
+		follows:      NewFollowService(dbClient, messageService, timelineService, cursors, jobs, heavyAuthors, opts.backfill),
+		users:        NewUserService(dbClient),
+		trends: NewTrendsService(dbClient, TrendOptions{
+			Windows: []time.Duration{time.Hour, 24 * time.Hour},
+			Bucket:  5 * time.Minute,
+			TopN:    10,
+		}),
+		jobs: jobs,
+	}
+}
+
+// recordingEnqueuer keeps the jobs it is given instead of running them.
+type recordingEnqueuer struct {
+	jobs []string
+}
+
+func (e *recordingEnqueuer) Enqueue(ctx context.Context, jobType string, payload interface{}) error {
+	e.jobs = append(e.jobs, jobType)
+	return nil
+}
+
+func seedMessages(t *testing.T, dbClient *database.MemoryClient, authorID string, ages ...time.Duration) {
+	for i, age := range ages {
+		message := &model.Message{
+			ID:        fmt.Sprintf("msg%d", i),
+			UserID:    authorID,
+			Content:   "content",
+			CreatedAt: time.Now().Add(-age),
+		}
+		item, err := attributevalue.MarshalMap(message)
+		require.NoError(t, err)
+		require.NoError(t, dbClient.PutItem(context.Background(), "messages", item))
+	}
+}
+
+func timelineMessageIDs(t *testing.T, dbClient *database.MemoryClient, userID string) []string {
+	result, err := dbClient.Query(context.Background(), &dynamodb.QueryInput{
+		TableName:              aws.String("timeline"),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+		ScanIndexForward: aws.Bool(false),
+	})
+	require.NoError(t, err)
+
+	var items []*model.TimelineItem
+	require.NoError(t, attributevalue.UnmarshalListOfMaps(result.Items, &items))
+	var ids []string
+	for _, item := range items {
+		ids = append(ids, item.MessageID)
+	}
+	return ids
+}
+
+func follow(t *testing.T, dbClient *database.MemoryClient, followerID, followingID string) {
+	require.NoError(t, dbClient.PutItem(context.Background(), "follows", followKey(followerID, followingID)))
+}
+
+func TestUpdateFollowerTimeline_OnlyWritesNewFollower(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, dbClient := services.follows, services.db
+	seedMessages(t, dbClient, "alice", time.Hour, 2*time.Hour, 3*time.Hour)
+	follow(t, dbClient, "carol", "alice")
+	follow(t, dbClient, "bob", "alice")
+
+	require.NoError(t, service.updateFollowerTimeline(context.Background(), "bob", "alice"))
+
+	assert.Equal(t, []string{"msg0", "msg1", "msg2"}, timelineMessageIDs(t, dbClient, "bob"))
+	assert.Empty(t, timelineMessageIDs(t, dbClient, "carol"))
+}
+
+func TestUpdateFollowerTimeline_PagesUpToMaxMessages(t *testing.T) {
+	services := newTestServices(testOptions{backfill: BackfillDepth{MaxMessages: 150}})
+	service, dbClient := services.follows, services.db
+	ages := make([]time.Duration, 200)
+	for i := range ages {
+		ages[i] = time.Duration(i+1) * time.Minute
+	}
+	seedMessages(t, dbClient, "alice", ages...)
+	follow(t, dbClient, "bob", "alice")
+
+	require.NoError(t, service.updateFollowerTimeline(context.Background(), "bob", "alice"))
+
+	ids := timelineMessageIDs(t, dbClient, "bob")
+	assert.Len(t, ids, 150)
+	assert.Equal(t, "msg0", ids[0])
+	assert.Equal(t, "msg149", ids[149])
+}
+
+func TestUpdateFollowerTimeline_SameSecondMessages(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, dbClient := services.follows, services.db
+	second := time.Now().Add(-time.Hour).Truncate(time.Second)
+	putMessage(t, dbClient, &model.Message{ID: "older", UserID: "alice", Content: "one", CreatedAt: second.Add(100 * time.Millisecond)})
+	putMessage(t, dbClient, &model.Message{ID: "newer", UserID: "alice", Content: "two", CreatedAt: second.Add(600 * time.Millisecond)})
+	putMessage(t, dbClient, &model.Message{ID: "earlier", UserID: "alice", Content: "three", CreatedAt: second.Add(-time.Minute)})
+	follow(t, dbClient, "bob", "alice")
+
+	require.NoError(t, service.updateFollowerTimeline(context.Background(), "bob", "alice"))
+
+	assert.Equal(t, []string{"newer", "earlier"}, timelineMessageIDs(t, dbClient, "bob"))
+}
+
+func TestBackfillTimeline_RechecksWrittenMessagesInABatch(t *testing.T) {
+	services := newTestServices(testOptions{})
+	timelineService, dbClient := services.timeline, services.db
+	counting := &countingDBClient{MemoryClient: dbClient}
+	timelineService.dbClient = counting
+	now := time.Now()
+	kept := &model.Message{ID: "kept", UserID: "alice", Content: "one", CreatedAt: now.Add(-time.Hour)}
+	putMessage(t, dbClient, kept)
+	// Deleted after it was read for the backfill.
+	deleted := &model.Message{ID: "deleted", UserID: "alice", Content: "two", CreatedAt: now.Add(-2 * time.Hour)}
+
+	require.NoError(t, timelineService.BackfillTimeline(context.Background(), "bob", []*model.Message{kept, deleted}))
+
+	assert.Equal(t, []string{"kept"}, timelineMessageIDs(t, dbClient, "bob"))
+	assert.Zero(t, counting.gets)
+}
+
+func TestUpdateFollowerTimeline_StopsAtWindow(t *testing.T) {
+	services := newTestServices(testOptions{backfill: BackfillDepth{Window: 90 * time.Minute}})
+	service, dbClient := services.follows, services.db
+	seedMessages(t, dbClient, "alice", time.Hour, 2*time.Hour, 3*time.Hour)
+	follow(t, dbClient, "bob", "alice")
+
+	require.NoError(t, service.updateFollowerTimeline(context.Background(), "bob", "alice"))
+
+	assert.Equal(t, []string{"msg0"}, timelineMessageIDs(t, dbClient, "bob"))
+}
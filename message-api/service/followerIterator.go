@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+
+	"mensajesService/components/database"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// followerChunkSize is how many followers fan-out handles per step, so memory
+// stays flat however many followers an author has.
+const followerChunkSize = 500
+
+// followerIterator walks FollowingIndex page by page, following
+// LastEvaluatedKey until the whole follower list has been read.
+type followerIterator struct {
+	dbClient database.DDBClientInterface
+	input    *dynamodb.QueryInput
+	done     bool
+}
+
+func newFollowerIterator(dbClient database.DDBClientInterface, userID string, chunkSize int) *followerIterator {
+	return &followerIterator{
+		dbClient: dbClient,
+		input: &dynamodb.QueryInput{
+			TableName:              aws.String(dbClient.GetFollowersTableName()),
+			IndexName:              aws.String("FollowingIndex"),
+			KeyConditionExpression: aws.String("following_id = :following_id"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":following_id": &types.AttributeValueMemberS{Value: userID},
+			},
+			Limit: aws.Int32(int32(chunkSize)),
+		},
+	}
+}
+
+// Next returns the next chunk of follower IDs, or nil once every page has
+// been read.
+func (it *followerIterator) Next(ctx context.Context) ([]string, error) {
+	for !it.done {
+		result, err := it.dbClient.Query(ctx, it.input)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			it.done = true
+		}
+		it.input.ExclusiveStartKey = result.LastEvaluatedKey
+
+		var follows []*model.Follow
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &follows); err != nil {
+			return nil, err
+		}
+		if len(follows) == 0 {
+			continue
+		}
+
+		followers := make([]string, 0, len(follows))
+		for _, follow := range follows {
+			followers = append(followers, follow.FollowerID)
+		}
+		return followers, nil
+	}
+	return nil, nil
+}
+
+// forEachFollowerChunk calls fn with every chunk of userID's followers and
+// returns how many followers were processed.
+func forEachFollowerChunk(ctx context.Context, dbClient database.DDBClientInterface, userID string, fn func(followers []string) error) (int, error) {
+	it := newFollowerIterator(dbClient, userID, followerChunkSize)
+	processed := 0
+	for {
+		followers, err := it.Next(ctx)
+		if err != nil {
+			return processed, err
+		}
+		if followers == nil {
+			return processed, nil
+		}
+		if err := fn(followers); err != nil {
+			return processed, err
+		}
+		processed += len(followers)
+	}
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"mensajesService/components/config"
+	"mensajesService/components/database"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollowerIterator_WalksEveryPage(t *testing.T) {
+	ctx := context.Background()
+	dbClient := database.NewMemoryClient(&config.AppConfig{TableSeguidoresName: "follows"})
+	for i := 0; i < 5; i++ {
+		require.NoError(t, dbClient.PutItem(ctx, "follows", map[string]types.AttributeValue{
+			"follower_id":  &types.AttributeValueMemberS{Value: fmt.Sprintf("follower%d", i)},
+			"following_id": &types.AttributeValueMemberS{Value: "author"},
+		}))
+	}
+
+	it := newFollowerIterator(dbClient, "author", 2)
+	var chunks [][]string
+	for {
+		followers, err := it.Next(ctx)
+		require.NoError(t, err)
+		if followers == nil {
+			break
+		}
+		chunks = append(chunks, followers)
+	}
+
+	assert.Equal(t, [][]string{
+		{"follower0", "follower1"},
+		{"follower2", "follower3"},
+		{"follower4"},
+	}, chunks)
+}
+
+func TestForEachFollowerChunk_StopsOnError(t *testing.T) {
+	ctx := context.Background()
+	dbClient := database.NewMemoryClient(&config.AppConfig{TableSeguidoresName: "follows"})
+	require.NoError(t, dbClient.PutItem(ctx, "follows", map[string]types.AttributeValue{
+		"follower_id":  &types.AttributeValueMemberS{Value: "follower"},
+		"following_id": &types.AttributeValueMemberS{Value: "author"},
+	}))
+
+	processed, err := forEachFollowerChunk(ctx, dbClient, "author", func(followers []string) error {
+		return assert.AnError
+	})
+
+	assert.ErrorIs(t, err, assert.AnError)
+	assert.Equal(t, 0, processed)
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// hashtagRecord points from a tag to a message. The message itself is read
+// when the tag is listed, so like counts are current and deleted messages
+// drop out. UserID and CreatedAt are the message's table key, so a page is
+// read in a batch; records stored before CreatedAt was recorded lack it.
+type hashtagRecord struct {
+	Tag       string     `dynamodbav:"tag"`
+	MessageID string     `dynamodbav:"message_id"`
+	UserID    string     `dynamodbav:"user_id"`
+	CreatedAt *time.Time `dynamodbav:"created_at,omitempty"`
+}
+
+// GetHashtagMessages lists the messages tagged with tag, newest first. The tag
+// may be given with or without its leading # and in any case. Messages
+// deleted since they were indexed are skipped, so a page can come up short.
+func (s *MessageService) GetHashtagMessages(ctx context.Context, tag string, page model.PageRequest) (*model.Page[*model.Message], error) {
+	tag = strings.TrimPrefix(tag, "#")
+	if !isHashtag([]rune(tag)) {
+		return nil, ErrInvalidHashtag
+	}
+	tag = strings.ToLower(tag)
+
+	scope := "hashtag:" + tag
+	startKey, err := s.cursors.DecodeKey(scope, page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.dbClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.dbClient.GetHashtagsTableName()),
+		KeyConditionExpression: aws.String("tag = :tag"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":tag": &types.AttributeValueMemberS{Value: tag},
+		},
+		ScanIndexForward:  aws.Bool(false),
+		Limit:             aws.Int32(int32(page.Limit)),
+		ExclusiveStartKey: startKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var records []*hashtagRecord
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &records); err != nil {
+		return nil, err
+	}
+
+	refs := make([]messageRef, len(records))
+	for i, record := range records {
+		refs[i] = messageRef{ID: record.MessageID, UserID: record.UserID, CreatedAt: record.CreatedAt}
+	}
+	messages, err := loadMessages(ctx, s.dbClient, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor, err := s.cursors.EncodeKey(scope, result.LastEvaluatedKey)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewPage(messages, nextCursor), nil
+}
+
+// indexHashtags adds the message under each of its tags.
+func (s *MessageService) indexHashtags(ctx context.Context, message *model.Message) error {
+	var requests []types.WriteRequest
+	for _, tag := range message.Entities.HashtagKeys() {
+		item, err := attributevalue.MarshalMap(hashtagRecord{
+			Tag:       tag,
+			MessageID: message.ID,
+			UserID:    message.UserID,
+			CreatedAt: &message.CreatedAt,
+		})
+		if err != nil {
+			return err
+		}
+		item["sort_key"] = feedSortKey(message)
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+	return s.dbClient.BatchWriteItem(ctx, s.dbClient.GetHashtagsTableName(), requests)
+}
+
+// removeHashtags deletes the message from the tags it was indexed under.
+func (s *MessageService) removeHashtags(ctx context.Context, message *model.Message) error {
+	failed := 0
+	for _, tag := range message.Entities.HashtagKeys() {
+		_, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(s.dbClient.GetHashtagsTableName()),
+			Key: map[string]types.AttributeValue{
+				"tag":      &types.AttributeValueMemberS{Value: tag},
+				"sort_key": feedSortKey(message),
+			},
+		})
+		if err != nil {
+			logger.LogError("Error deleting hashtag entry", "error", err, "message_id", message.ID, "tag", tag)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("removing hashtags of message %s failed for %d tags", message.ID, failed)
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"mensajesService/message-api/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntities_Hashtags(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *model.Entities
+	}{
+		{"single", "learning #Go!", &model.Entities{Hashtags: []model.Hashtag{{Tag: "Go", Start: 9, End: 12}}}},
+		{"unicode", "#café con leche", &model.Entities{Hashtags: []model.Hashtag{{Tag: "café", Start: 0, End: 5}}}},
+		{"number only", "item #1", nil},
+		{"inside a word", "C# and F#", nil},
+		{"with mention", "@bob #go_lang", &model.Entities{
+			Mentions: []model.Mention{{Username: "bob", Start: 0, End: 4}},
+			Hashtags: []model.Hashtag{{Tag: "go_lang", Start: 5, End: 13}},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseEntities(tt.content))
+		})
+	}
+}
+
+func TestHashtagKeys_IgnoresCase(t *testing.T) {
+	entities := parseEntities("#Go #go #GoLang")
+
+	assert.Equal(t, []string{"go", "golang"}, entities.HashtagKeys())
+}
+
+func TestGetHashtagMessages(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+
+	first, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "hello #Go"})
+	require.NoError(t, err)
+	second, err := service.CreateMessage(ctx, "bob", &model.CreateMessageRequest{Content: "#go #go again"})
+	require.NoError(t, err)
+	_, err = service.CreateMessage(ctx, "carol", &model.CreateMessageRequest{Content: "#rust"})
+	require.NoError(t, err)
+
+	page, err := service.GetHashtagMessages(ctx, "#GO", model.PageRequest{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, second.ID, page.Items[0].ID)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = service.GetHashtagMessages(ctx, "go", model.PageRequest{Limit: 1, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, first.ID, page.Items[0].ID)
+
+	_, err = service.DeleteMessage(ctx, "bob", second.ID)
+	require.NoError(t, err)
+	page, err = service.GetHashtagMessages(ctx, "go", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, first.ID, page.Items[0].ID)
+}
+
+func TestGetHashtagMessages_ReadsMessagesInABatch(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, dbClient := services.messages, services.db
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		_, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: fmt.Sprintf("#go %d", i)})
+		require.NoError(t, err)
+	}
+
+	counting := &countingDBClient{MemoryClient: dbClient}
+	service.dbClient = counting
+	page, err := service.GetHashtagMessages(ctx, "go", model.PageRequest{Limit: 10})
+
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 3)
+	assert.Zero(t, counting.gets)
+	assert.Equal(t, 1, counting.queries)
+}
+
+func TestGetHashtagMessages_IndexesQuotes(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+	original, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "hi"})
+	require.NoError(t, err)
+
+	quote, err := service.Repost(ctx, "bob", original.ID, &model.RepostRequest{Content: "so #true"})
+	require.NoError(t, err)
+
+	page, err := service.GetHashtagMessages(ctx, "true", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, quote.ID, page.Items[0].ID)
+}
+
+func TestGetHashtagMessages_InvalidTag(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+
+	for _, tag := range []string{"", "#", "123", "go-lang"} {
+		_, err := service.GetHashtagMessages(context.Background(), tag, model.PageRequest{Limit: 10})
+		assert.ErrorIs(t, err, ErrInvalidHashtag, tag)
+	}
+}
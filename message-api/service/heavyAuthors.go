@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"mensajesService/components/database"
+	"mensajesService/components/logger"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// heavyAuthorsShard is the single partition of the heavy authors table. The
+// table stays small: only accounts above the follower threshold land in it.
+const heavyAuthorsShard = "heavy"
+
+// heavyAuthorsCacheTTL bounds how long timeline reads on one instance may miss
+// an author another instance just marked heavy: that author's new messages
+// show up once the cache refreshes.
+const heavyAuthorsCacheTTL = time.Minute
+
+// HeavyAuthors tracks accounts whose messages are not copied into follower
+// timelines but merged in at read time. An account is marked once its follower
+// count reaches the threshold and stays marked: its older messages were never
+// fanned out, so unmarking it would make them vanish from timelines.
+type HeavyAuthors struct {
+	dbClient  database.DDBClientInterface
+	threshold int
+
+	mu       sync.Mutex
+	cached   []string
+	cachedAt time.Time
+}
+
+// NewHeavyAuthors disables fan-out-on-read when threshold is 0 or less.
+func NewHeavyAuthors(dbClient database.DDBClientInterface, threshold int) *HeavyAuthors {
+	return &HeavyAuthors{
+		dbClient:  dbClient,
+		threshold: threshold,
+	}
+}
+
+// Observe marks userID as heavy when followersCount reaches the threshold.
+func (h *HeavyAuthors) Observe(ctx context.Context, userID string, followersCount int64) error {
+	if h.threshold <= 0 || followersCount < int64(h.threshold) {
+		return nil
+	}
+
+	err := h.dbClient.PutItem(ctx, h.dbClient.GetHeavyAuthorsTableName(), map[string]types.AttributeValue{
+		"shard":           &types.AttributeValueMemberS{Value: heavyAuthorsShard},
+		"user_id":         &types.AttributeValueMemberS{Value: userID},
+		"followers_count": &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", followersCount)},
+		"marked_at":       &types.AttributeValueMemberS{Value: time.Now().Format(time.RFC3339)},
+	})
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	if !h.cachedAt.IsZero() && !slices.Contains(h.cached, userID) {
+		h.cached = append(h.cached, userID)
+	}
+	h.mu.Unlock()
+
+	logger.LogInfo("Author switched to fan-out-on-read", "user_id", userID, "followers_count", followersCount)
+	return nil
+}
+
+func (h *HeavyAuthors) IsHeavy(ctx context.Context, userID string) (bool, error) {
+	if h.threshold <= 0 {
+		return false, nil
+	}
+
+	result, err := h.dbClient.GetItem(ctx, h.dbClient.GetHeavyAuthorsTableName(), heavyAuthorKey(userID))
+	if err != nil {
+		return false, err
+	}
+	return len(result.Item) > 0, nil
+}
+
+func (h *HeavyAuthors) List(ctx context.Context) ([]string, error) {
+	if h.threshold <= 0 {
+		return nil, nil
+	}
+
+	input := &dynamodb.QueryInput{
+		TableName:                aws.String(h.dbClient.GetHeavyAuthorsTableName()),
+		KeyConditionExpression:   aws.String("#shard = :shard"),
+		ExpressionAttributeNames: map[string]string{"#shard": "shard"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":shard": &types.AttributeValueMemberS{Value: heavyAuthorsShard},
+		},
+	}
+
+	var authors []string
+	for {
+		result, err := h.dbClient.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range result.Items {
+			if userID, ok := item["user_id"].(*types.AttributeValueMemberS); ok {
+				authors = append(authors, userID.Value)
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return authors, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// Cached returns what List does, reading it at most once per
+// heavyAuthorsCacheTTL. Authors are only ever added, so a stale list lacks at
+// worst the newest ones.
+func (h *HeavyAuthors) Cached(ctx context.Context) ([]string, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.cachedAt.IsZero() || time.Since(h.cachedAt) >= heavyAuthorsCacheTTL {
+		authors, err := h.List(ctx)
+		if err != nil {
+			return nil, err
+		}
+		h.cached, h.cachedAt = authors, time.Now()
+	}
+	return h.cached, nil
+}
+
+func heavyAuthorKey(userID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"shard":   &types.AttributeValueMemberS{Value: heavyAuthorsShard},
+		"user_id": &types.AttributeValueMemberS{Value: userID},
+	}
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetUserTimeline_MergesFollowedHeavyAuthors(t *testing.T) {
+	services := newTestServices(testOptions{heavyThreshold: 1})
+	heavyAuthors, timelineService, dbClient := services.heavyAuthors, services.timeline, services.db
+	ctx := context.Background()
+	require.NoError(t, heavyAuthors.Observe(ctx, "alice", 1))
+	require.NoError(t, heavyAuthors.Observe(ctx, "bob", 1))
+	follow(t, dbClient, "carol", "alice")
+
+	now := time.Now()
+	putMessage(t, dbClient, &model.Message{ID: "from-alice", UserID: "alice", Content: "hi", CreatedAt: now.Add(-time.Minute)})
+	putMessage(t, dbClient, &model.Message{ID: "from-bob", UserID: "bob", Content: "hi", CreatedAt: now})
+
+	page, err := timelineService.GetUserTimeline(ctx, "carol", model.PageRequest{Limit: 10})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "from-alice", page.Items[0].MessageID)
+}
+
+func TestHeavyAuthors_CachedReadsListOnce(t *testing.T) {
+	logger.Init()
+	counting := &countingDBClient{MemoryClient: newTestDBClient()}
+	heavyAuthors := NewHeavyAuthors(counting, 1)
+	ctx := context.Background()
+	require.NoError(t, heavyAuthors.Observe(ctx, "alice", 1))
+
+	for i := 0; i < 3; i++ {
+		authors, err := heavyAuthors.Cached(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"alice"}, authors)
+	}
+	assert.Equal(t, 1, counting.queries)
+
+	// An author marked on this instance shows up without waiting for the TTL.
+	require.NoError(t, heavyAuthors.Observe(ctx, "bob", 1))
+	authors, err := heavyAuthors.Cached(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"alice", "bob"}, authors)
+	assert.Equal(t, 1, counting.queries)
+}
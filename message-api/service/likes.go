@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"mensajesService/components/database"
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// like is a row of the likes table. liked_at is in milliseconds so
+// UserLikesIndex sorts numerically. MessageUserID and MessageCreatedAt are the
+// key of the liked message, so a page of likes is read in a batch; likes
+// stored before they were recorded don't have them.
+type like struct {
+	MessageID        string     `dynamodbav:"message_id"`
+	UserID           string     `dynamodbav:"user_id"`
+	LikedAt          int64      `dynamodbav:"liked_at"`
+	MessageUserID    string     `dynamodbav:"message_user_id,omitempty"`
+	MessageCreatedAt *time.Time `dynamodbav:"message_created_at,omitempty"`
+}
+
+// LikeCountJob adjusts a message's like counter by Delta.
+type LikeCountJob struct {
+	MessageID string `json:"message_id"`
+	Delta     int    `json:"delta"`
+}
+
+// LikeMessage records that userID likes messageID and reports whether the
+// like is new. The counter is updated later by a JobAdjustLikeCount job. A
+// private account's messages can only be liked by the users who may read them.
+func (s *MessageService) LikeMessage(ctx context.Context, userID, messageID string) (bool, error) {
+	message, err := s.viewableMessage(ctx, userID, messageID)
+	if err != nil {
+		return false, err
+	}
+
+	item, err := attributevalue.MarshalMap(like{
+		MessageID:        messageID,
+		UserID:           userID,
+		LikedAt:          time.Now().UnixMilli(),
+		MessageUserID:    message.UserID,
+		MessageCreatedAt: &message.CreatedAt,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	err = s.dbClient.PutItemWithCondition(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.dbClient.GetLikesTableName()),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(message_id)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	logger.LogInfo("Message liked", "message_id", messageID, "user_id", userID)
+	return true, nil
+}
+
+// UnlikeMessage removes userID's like and reports whether there was one.
+func (s *MessageService) UnlikeMessage(ctx context.Context, userID, messageID string) (bool, error) {
+	result, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:    aws.String(s.dbClient.GetLikesTableName()),
+		Key:          likeKey(messageID, userID),
+		ReturnValues: types.ReturnValueAllOld,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	existed := len(result.Attributes) > 0
+	logger.LogInfo("Message unliked", "message_id", messageID, "user_id", userID, "existed", existed)
+	return existed, nil
+}
+
+// GetUserLikes lists the messages userID liked, most recently liked first.
+// Likes of messages deleted since are skipped, so a page can come up short.
+func (s *MessageService) GetUserLikes(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Message], error) {
+	scope := "likes:" + userID
+	startKey, err := s.cursors.DecodeKey(scope, page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.dbClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.dbClient.GetLikesTableName()),
+		IndexName:              aws.String("UserLikesIndex"),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+		ScanIndexForward:  aws.Bool(false),
+		Limit:             aws.Int32(int32(page.Limit)),
+		ExclusiveStartKey: startKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var likes []*like
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &likes); err != nil {
+		return nil, err
+	}
+
+	refs := make([]messageRef, len(likes))
+	for i, l := range likes {
+		refs[i] = messageRef{ID: l.MessageID, UserID: l.MessageUserID, CreatedAt: l.MessageCreatedAt}
+	}
+	messages, err := loadMessages(ctx, s.dbClient, refs)
+	if err != nil {
+		return nil, err
+	}
+
+	nextCursor, err := s.cursors.EncodeKey(scope, result.LastEvaluatedKey)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewPage(messages, nextCursor), nil
+}
+
+// MarkLiked sets LikedByMe on messages from viewerID's point of view.
+func (s *MessageService) MarkLiked(ctx context.Context, viewerID string, messages []*model.Message) error {
+	messageIDs := make([]string, len(messages))
+	for i, message := range messages {
+		messageIDs[i] = message.ID
+	}
+	liked, err := likedMessages(ctx, s.dbClient, viewerID, messageIDs)
+	if err != nil {
+		return err
+	}
+	for _, message := range messages {
+		likedByMe := liked[message.ID]
+		message.LikedByMe = &likedByMe
+	}
+	return nil
+}
+
+// adjustLikeCount applies delta to the message's counter. The job is only
+// enqueued when the conditional put or delete of the like row changed it, so
+// a repeated like or unlike adds nothing; a job that runs again after its
+// update, when a worker dies before completing it, counts twice. Likes of a
+// message deleted in the meantime are dropped.
+func (s *MessageService) adjustLikeCount(ctx context.Context, messageID string, delta int) error {
+	message, err := s.GetMessage(ctx, messageID)
+	if errors.Is(err, ErrMessageNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	key, err := messageKey(message)
+	if err != nil {
+		return err
+	}
+	_, err = s.dbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName:           aws.String(s.dbClient.GetMessagesTableName()),
+		Key:                 key,
+		UpdateExpression:    aws.String("ADD like_count :delta"),
+		ConditionExpression: aws.String("message_id = :message_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":delta":      &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", delta)},
+			":message_id": &types.AttributeValueMemberS{Value: messageID},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	return err
+}
+
+// likedMessages reports which of messageIDs userID likes, with one batch
+// read of their likes.
+func likedMessages(ctx context.Context, dbClient database.DDBClientInterface, userID string, messageIDs []string) (map[string]bool, error) {
+	liked := make(map[string]bool, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return liked, nil
+	}
+	keys := make([]map[string]types.AttributeValue, len(messageIDs))
+	for i, messageID := range messageIDs {
+		keys[i] = likeKey(messageID, userID)
+	}
+	items, err := dbClient.BatchGetItem(ctx, dbClient.GetLikesTableName(), keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		var row like
+		if err := attributevalue.UnmarshalMap(item, &row); err != nil {
+			return nil, err
+		}
+		liked[row.MessageID] = true
+	}
+	return liked, nil
+}
+
+func likeKey(messageID, userID string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"message_id": &types.AttributeValueMemberS{Value: messageID},
+		"user_id":    &types.AttributeValueMemberS{Value: userID},
+	}
+}
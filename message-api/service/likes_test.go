@@ -0,0 +1,204 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"mensajesService/components/database"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLikeMessage_IsIdempotent(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+	message, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "hi"})
+	require.NoError(t, err)
+
+	created, err := service.LikeMessage(ctx, "bob", message.ID)
+	require.NoError(t, err)
+	assert.True(t, created)
+
+	created, err = service.LikeMessage(ctx, "bob", message.ID)
+	require.NoError(t, err)
+	assert.False(t, created)
+
+	existed, err := service.UnlikeMessage(ctx, "bob", message.ID)
+	require.NoError(t, err)
+	assert.True(t, existed)
+
+	existed, err = service.UnlikeMessage(ctx, "bob", message.ID)
+	require.NoError(t, err)
+	assert.False(t, existed)
+}
+
+func TestLikeMessage_NotFound(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+
+	_, err := service.LikeMessage(context.Background(), "bob", "missing")
+
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+}
+
+func TestAdjustLikeCount(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+	message, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "hi"})
+	require.NoError(t, err)
+
+	require.NoError(t, service.adjustLikeCount(ctx, message.ID, 1))
+	require.NoError(t, service.adjustLikeCount(ctx, message.ID, 1))
+	require.NoError(t, service.adjustLikeCount(ctx, message.ID, -1))
+
+	stored, err := service.GetMessage(ctx, message.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), stored.LikeCount)
+
+	_, err = service.DeleteMessage(ctx, "alice", message.ID)
+	require.NoError(t, err)
+	assert.NoError(t, service.adjustLikeCount(ctx, message.ID, 1))
+	_, err = service.GetMessage(ctx, message.ID)
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+}
+
+func TestGetUserLikes_NewestFirstSkippingDeleted(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+	var ids []string
+	for i := 0; i < 3; i++ {
+		message, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "hi"})
+		require.NoError(t, err)
+		_, err = service.LikeMessage(ctx, "bob", message.ID)
+		require.NoError(t, err)
+		ids = append(ids, message.ID)
+		time.Sleep(2 * time.Millisecond)
+	}
+	_, err := service.DeleteMessage(ctx, "alice", ids[1])
+	require.NoError(t, err)
+
+	page, err := service.GetUserLikes(ctx, "bob", model.PageRequest{Limit: 10})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, ids[2], page.Items[0].ID)
+	assert.Equal(t, ids[0], page.Items[1].ID)
+}
+
+func TestGetUserLikes_ReadsMessagesInABatch(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, dbClient := services.messages, services.db
+	ctx := context.Background()
+	for i := 0; i < 3; i++ {
+		message, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "hi"})
+		require.NoError(t, err)
+		_, err = service.LikeMessage(ctx, "bob", message.ID)
+		require.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	counting := &countingDBClient{MemoryClient: dbClient}
+	service.dbClient = counting
+	page, err := service.GetUserLikes(ctx, "bob", model.PageRequest{Limit: 10})
+
+	require.NoError(t, err)
+	assert.Len(t, page.Items, 3)
+	assert.Zero(t, counting.gets)
+	assert.Equal(t, 1, counting.queries)
+}
+
+func TestMarkLiked(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+	liked, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "liked"})
+	require.NoError(t, err)
+	other, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "other"})
+	require.NoError(t, err)
+	_, err = service.LikeMessage(ctx, "bob", liked.ID)
+	require.NoError(t, err)
+
+	require.NoError(t, service.MarkLiked(ctx, "bob", []*model.Message{liked, other}))
+
+	require.NotNil(t, liked.LikedByMe)
+	assert.True(t, *liked.LikedByMe)
+	require.NotNil(t, other.LikedByMe)
+	assert.False(t, *other.LikedByMe)
+}
+
+func TestGetUserTimeline_LikeCountAndFlag(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, timelineService, dbClient := services.messages, services.timeline, services.db
+	ctx := context.Background()
+	follow(t, dbClient, "carol", "alice")
+	message, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "hi"})
+	require.NoError(t, err)
+	require.NoError(t, timelineService.UpdateFollowersTimeline(ctx, message))
+	_, err = service.LikeMessage(ctx, "carol", message.ID)
+	require.NoError(t, err)
+	require.NoError(t, service.adjustLikeCount(ctx, message.ID, 1))
+
+	page, err := timelineService.GetUserTimeline(ctx, "carol", model.PageRequest{Limit: 10})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, int64(1), page.Items[0].LikeCount)
+	require.NotNil(t, page.Items[0].LikedByMe)
+	assert.True(t, *page.Items[0].LikedByMe)
+}
+
+// countingDBClient counts the single-item reads that batch reads replace.
+type countingDBClient struct {
+	*database.MemoryClient
+	gets, queries int
+}
+
+func (c *countingDBClient) GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue) (*dynamodb.GetItemOutput, error) {
+	c.gets++
+	return c.MemoryClient.GetItem(ctx, tableName, key)
+}
+
+func (c *countingDBClient) Query(ctx context.Context, input *dynamodb.QueryInput) (*dynamodb.QueryOutput, error) {
+	c.queries++
+	return c.MemoryClient.Query(ctx, input)
+}
+
+func TestDecorateTimelineItems_ReadsInBatches(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, timelineService, dbClient := services.messages, services.timeline, services.db
+	ctx := context.Background()
+	follow(t, dbClient, "carol", "bob")
+	start := time.Now().Add(-time.Hour)
+	for i := 0; i < 3; i++ {
+		original := &model.Message{ID: fmt.Sprintf("original%d", i), UserID: "alice", Content: "original", CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+		putMessage(t, dbClient, original)
+		quote := &model.Message{ID: fmt.Sprintf("quote%d", i), UserID: "bob", Content: "quote", CreatedAt: original.CreatedAt.Add(time.Second),
+			QuoteOf: original.ID, OriginalUserID: original.UserID, OriginalCreatedAt: &original.CreatedAt}
+		putMessage(t, dbClient, quote)
+		require.NoError(t, timelineService.UpdateFollowersTimeline(ctx, quote))
+		_, err := service.LikeMessage(ctx, "carol", quote.ID)
+		require.NoError(t, err)
+	}
+	page, err := timelineService.GetUserTimeline(ctx, "carol", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 3)
+
+	counting := &countingDBClient{MemoryClient: dbClient}
+	timelineService.dbClient = counting
+	for _, item := range page.Items {
+		item.Original, item.LikedByMe = nil, nil
+	}
+	require.NoError(t, timelineService.decorateTimelineItems(ctx, "carol", page.Items))
+
+	assert.Zero(t, counting.gets)
+	assert.Zero(t, counting.queries)
+	for _, item := range page.Items {
+		require.NotNil(t, item.Original)
+		assert.Equal(t, "alice", item.Original.UserID)
+		assert.True(t, *item.LikedByMe)
+	}
+}
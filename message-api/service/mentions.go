@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// feedSortKeyLayout is a fixed-width UTC timestamp, so feed sort keys order
+// by time as strings.
+const feedSortKeyLayout = "2006-01-02T15:04:05.000000000Z"
+
+// feedSortKey orders a message in a per-user feed. The message ID keeps two
+// messages from the same instant apart.
+func feedSortKey(message *model.Message) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: message.CreatedAt.UTC().Format(feedSortKeyLayout) + "#" + message.ID}
+}
+
+// GetMentions lists the messages that mention userID, newest first.
+func (s *TimelineService) GetMentions(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.TimelineItem], error) {
+	scope := "mentions:" + userID
+	startKey, err := s.cursors.DecodeKey(scope, page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.dbClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(s.dbClient.GetMentionsTableName()),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+		ScanIndexForward:  aws.Bool(false),
+		Limit:             aws.Int32(int32(page.Limit)),
+		ExclusiveStartKey: startKey,
+	})
+	if err != nil {
+		logger.LogError("Error getting mentions", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	var items []*model.TimelineItem
+	if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+		return nil, err
+	}
+	if err := s.decorateTimelineItems(ctx, userID, items); err != nil {
+		return nil, err
+	}
+
+	nextCursor, err := s.cursors.EncodeKey(scope, result.LastEvaluatedKey)
+	if err != nil {
+		return nil, err
+	}
+	return model.NewPage(items, nextCursor), nil
+}
+
+// writeMentions adds the message to the mentions feed of every user it
+// mentions, except its author and users on either side of a block with them.
+// A private author only reaches the mentioned users who follow them.
+func (s *TimelineService) writeMentions(ctx context.Context, message *model.Message) error {
+	mentioned := message.Entities.MentionedUsers()
+	if len(mentioned) == 0 {
+		return nil
+	}
+	blocked, err := blockedUsers(ctx, s.dbClient, message.UserID)
+	if err != nil {
+		return err
+	}
+
+	var requests []types.WriteRequest
+	for _, userID := range mentioned {
+		if userID == message.UserID || blocked[userID] {
+			continue
+		}
+		visible, err := canViewMessages(ctx, s.dbClient, userID, message.UserID)
+		if err != nil {
+			return err
+		}
+		if !visible {
+			continue
+		}
+		item, err := attributevalue.MarshalMap(model.NewTimelineItem(userID, message))
+		if err != nil {
+			return err
+		}
+		item["sort_key"] = feedSortKey(message)
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: item}})
+	}
+	if len(requests) == 0 {
+		return nil
+	}
+
+	if err := s.dbClient.BatchWriteItem(ctx, s.dbClient.GetMentionsTableName(), requests); err != nil {
+		logger.LogError("Error writing mentions", "error", err, "message_id", message.ID)
+		return err
+	}
+	logger.LogInfo("Mentions written", "message_id", message.ID, "mentions_count", len(requests))
+	return nil
+}
+
+// removeMentions deletes a message from the mentions feeds it was written to.
+func (s *TimelineService) removeMentions(ctx context.Context, message *model.Message) error {
+	failed := 0
+	for _, userID := range message.Entities.MentionedUsers() {
+		_, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+			TableName: aws.String(s.dbClient.GetMentionsTableName()),
+			Key: map[string]types.AttributeValue{
+				"user_id":  &types.AttributeValueMemberS{Value: userID},
+				"sort_key": feedSortKey(message),
+			},
+		})
+		if err != nil {
+			logger.LogError("Error deleting mention", "error", err, "message_id", message.ID, "user_id", userID)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("removing mentions of message %s failed for %d users", message.ID, failed)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mensajesService/message-api/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEntities(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		expected *model.Entities
+	}{
+		{"none", "hello world", nil},
+		{"single", "hi @bob!", &model.Entities{Mentions: []model.Mention{{Username: "bob", Start: 3, End: 7}}}},
+		{"several", "@ana and @luis_2", &model.Entities{Mentions: []model.Mention{
+			{Username: "ana", Start: 0, End: 4},
+			{Username: "luis_2", Start: 9, End: 16},
+		}}},
+		{"email", "write to ana@example.com", nil},
+		{"bare at", "meet @ 5", nil},
+		{"trailing hyphen", "cc @bob-", &model.Entities{Mentions: []model.Mention{{Username: "bob", Start: 3, End: 7}}}},
+		{"unicode offsets", "¡olé @bob", &model.Entities{Mentions: []model.Mention{{Username: "bob", Start: 5, End: 9}}}},
+		{"stops at non-ascii", "@josé", &model.Entities{Mentions: []model.Mention{{Username: "jos", Start: 0, End: 4}}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, parseEntities(tt.content))
+		})
+	}
+}
+
+func TestMentionedUsers_Deduplicates(t *testing.T) {
+	entities := parseEntities("@bob @carol @bob")
+
+	assert.Equal(t, []string{"bob", "carol"}, entities.MentionedUsers())
+	assert.Nil(t, (*model.Entities)(nil).MentionedUsers())
+}
+
+func TestWriteMentions_SkipsAuthorAndRemoves(t *testing.T) {
+	timelineService := newTestServices(testOptions{}).timeline
+	ctx := context.Background()
+	message := &model.Message{
+		ID:        "msg1",
+		UserID:    "alice",
+		Content:   "@alice @bob @carol",
+		CreatedAt: time.Now(),
+	}
+	message.Entities = parseEntities(message.Content)
+
+	require.NoError(t, timelineService.writeMentions(ctx, message))
+
+	page, err := timelineService.GetMentions(ctx, "bob", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "msg1", page.Items[0].MessageID)
+	assert.Equal(t, "alice", page.Items[0].AuthorID)
+	assert.Equal(t, message.Entities, page.Items[0].Entities)
+
+	page, err = timelineService.GetMentions(ctx, "alice", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	assert.Empty(t, page.Items)
+
+	require.NoError(t, timelineService.removeMentions(ctx, message))
+	for _, userID := range []string{"bob", "carol"} {
+		page, err = timelineService.GetMentions(ctx, userID, model.PageRequest{Limit: 10})
+		require.NoError(t, err)
+		assert.Empty(t, page.Items)
+	}
+}
+
+func TestGetMentions_Paginates(t *testing.T) {
+	timelineService := newTestServices(testOptions{}).timeline
+	ctx := context.Background()
+	now := time.Now()
+	// Both messages share a second, which the sort key keeps apart.
+	for i, id := range []string{"older", "newer"} {
+		message := &model.Message{ID: id, UserID: "alice", Content: "@bob", CreatedAt: now.Add(time.Duration(i) * time.Millisecond)}
+		message.Entities = parseEntities(message.Content)
+		require.NoError(t, timelineService.writeMentions(ctx, message))
+	}
+
+	page, err := timelineService.GetMentions(ctx, "bob", model.PageRequest{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "newer", page.Items[0].MessageID)
+	require.NotEmpty(t, page.NextCursor)
+
+	page, err = timelineService.GetMentions(ctx, "bob", model.PageRequest{Limit: 1, Cursor: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, "older", page.Items[0].MessageID)
+}
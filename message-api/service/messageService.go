@@ -2,10 +2,12 @@ package service
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"mensajesService/components/database"
 	"mensajesService/components/logger"
+	"mensajesService/components/pagination"
 	"mensajesService/message-api/model"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -16,47 +18,116 @@ import (
 )
 
 type MessageServiceInterface interface {
-	CreateMessage(ctx context.Context, userID, content string) (*model.Message, error)
-	GetUserMessages(ctx context.Context, userID string, limit int) ([]*model.Message, error)
+	CreateMessage(ctx context.Context, userID string, request *model.CreateMessageRequest) (*model.Message, error)
+	GetUserMessages(ctx context.Context, userID string, page model.PageRequest, window model.TimeRange) (*model.Page[*model.Message], error)
+	CanViewMessages(ctx context.Context, viewerID, authorID string) (bool, error)
+	VisibleTo(ctx context.Context, viewerID string, messages []*model.Message) ([]*model.Message, error)
+	VisibleThread(ctx context.Context, viewerID string, thread *model.Thread) (bool, error)
+	GetMessage(ctx context.Context, messageID string) (*model.Message, error)
+	GetThread(ctx context.Context, messageID string, depth int) (*model.Thread, error)
+	Repost(ctx context.Context, userID, messageID string, request *model.RepostRequest) (*model.Message, error)
+	UndoRepost(ctx context.Context, userID, messageID string) (*model.Message, error)
+	LikeMessage(ctx context.Context, userID, messageID string) (bool, error)
+	UnlikeMessage(ctx context.Context, userID, messageID string) (bool, error)
+	GetUserLikes(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.Message], error)
+	MarkLiked(ctx context.Context, viewerID string, messages []*model.Message) error
+	EmbedAuthors(ctx context.Context, messages []*model.Message) error
+	GetHashtagMessages(ctx context.Context, tag string, page model.PageRequest) (*model.Page[*model.Message], error)
+	DeleteMessage(ctx context.Context, userID, messageID string) (*model.Message, error)
 }
 
 type MessageService struct {
 	dbClient database.DDBClientInterface
+	cursors  *pagination.CursorCodec
 }
 
-func NewMessageService(dbClient database.DDBClientInterface) *MessageService {
-	return &MessageService{dbClient: dbClient}
+func NewMessageService(dbClient database.DDBClientInterface, cursors *pagination.CursorCodec) *MessageService {
+	return &MessageService{
+		dbClient: dbClient,
+		cursors:  cursors,
+	}
 }
 
-func (s *MessageService) CreateMessage(ctx context.Context, userID, content string) (*model.Message, error) {
+// CreateMessage stores a new message. A reply joins its parent's
+// conversation; any other message starts its own.
+func (s *MessageService) CreateMessage(ctx context.Context, userID string, request *model.CreateMessageRequest) (*model.Message, error) {
 	messageID := generateUUID()
 	now := time.Now()
 
 	message := &model.Message{
-		ID:        messageID,
-		UserID:    userID,
-		Content:   content,
-		CreatedAt: now,
+		ID:             messageID,
+		UserID:         userID,
+		Content:        request.Content,
+		CreatedAt:      now,
+		Entities:       parseEntities(request.Content),
+		ConversationID: messageID,
+	}
+
+	if request.InReplyTo != "" {
+		// A parent userID may not read is reported like a missing one.
+		parent, err := s.viewableMessage(ctx, userID, request.InReplyTo)
+		if errors.Is(err, ErrMessageNotFound) {
+			return nil, ErrReplyTargetNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		message.InReplyTo = parent.ID
+		message.InReplyToUserID = parent.UserID
+		message.ConversationID = conversationID(parent)
 	}
 
 	err := s.saveMessage(ctx, message)
 	if err != nil {
 		return nil, err
 	}
+	s.indexEntities(ctx, message)
 
 	logger.LogInfo("Message created successfully", "message_id", message.ID, "user_id", userID)
 	return message, nil
 }
 
-func (s *MessageService) GetUserMessages(ctx context.Context, userID string, limit int) ([]*model.Message, error) {
+// CanViewMessages reports whether viewerID may list authorID's messages. A
+// private account's messages are only listed to the account itself and its
+// approved followers.
+func (s *MessageService) CanViewMessages(ctx context.Context, viewerID, authorID string) (bool, error) {
+	return canViewMessages(ctx, s.dbClient, viewerID, authorID)
+}
+
+// GetUserMessages lists a user's messages newest first, optionally limited to
+// a time window. A page can hold fewer than page.Limit items when the window
+// edges fall inside it; NextCursor still tells whether more pages exist.
+func (s *MessageService) GetUserMessages(ctx context.Context, userID string, page model.PageRequest, window model.TimeRange) (*model.Page[*model.Message], error) {
+	scope := "messages:" + userID
+	startKey, err := s.cursors.DecodeKey(scope, page.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	keyCondition := "user_id = :user_id"
+	values := map[string]types.AttributeValue{
+		":user_id": &types.AttributeValueMemberS{Value: userID},
+	}
+	switch {
+	case !window.After.IsZero() && !window.Before.IsZero():
+		keyCondition += " AND created_at BETWEEN :after AND :before"
+		values[":after"] = createdAtLowerBound(window.After)
+		values[":before"] = createdAtUpperBound(window.Before)
+	case !window.After.IsZero():
+		keyCondition += " AND created_at > :after"
+		values[":after"] = createdAtLowerBound(window.After)
+	case !window.Before.IsZero():
+		keyCondition += " AND created_at < :before"
+		values[":before"] = createdAtUpperBound(window.Before)
+	}
+
 	input := &dynamodb.QueryInput{
-		TableName:              aws.String(s.dbClient.GetMessagesTableName()),
-		KeyConditionExpression: aws.String("user_id = :user_id"),
-		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":user_id": &types.AttributeValueMemberS{Value: userID},
-		},
-		ScanIndexForward: aws.Bool(false),
-		Limit:            aws.Int32(int32(limit)),
+		TableName:                 aws.String(s.dbClient.GetMessagesTableName()),
+		KeyConditionExpression:    aws.String(keyCondition),
+		ExpressionAttributeValues: values,
+		ScanIndexForward:          aws.Bool(false),
+		Limit:                     aws.Int32(int32(page.Limit)),
+		ExclusiveStartKey:         startKey,
 	}
 
 	result, err := s.dbClient.Query(ctx, input)
@@ -70,7 +141,71 @@ func (s *MessageService) GetUserMessages(ctx context.Context, userID string, lim
 		return nil, err
 	}
 
-	return messages, nil
+	inWindow := messages[:0]
+	for _, message := range messages {
+		if window.Contains(message.CreatedAt) {
+			inWindow = append(inWindow, message)
+		}
+	}
+
+	nextCursor, err := s.cursors.EncodeKey(scope, result.LastEvaluatedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return model.NewPage(inWindow, nextCursor), nil
+}
+
+// GetMessage looks a message up by ID through MessageIdIndex.
+func (s *MessageService) GetMessage(ctx context.Context, messageID string) (*model.Message, error) {
+	return getMessage(ctx, s.dbClient, messageID)
+}
+
+// DeleteMessage removes one of the user's own messages. Messages written by
+// somebody else are reported as not found.
+func (s *MessageService) DeleteMessage(ctx context.Context, userID, messageID string) (*model.Message, error) {
+	message, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if message.UserID != userID {
+		return nil, ErrMessageNotFound
+	}
+
+	key, err := messageKey(message)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.dbClient.GetMessagesTableName()),
+		Key:                 key,
+		ConditionExpression: aws.String("message_id = :message_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":message_id": &types.AttributeValueMemberS{Value: messageID},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, ErrMessageNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if message.RepostOf != "" {
+		// Deleting a repost directly frees the user to repost again.
+		if err := s.deleteRepostRecord(ctx, message); err != nil {
+			logger.LogError("Error deleting repost record", "error", err, "message_id", messageID, "user_id", userID)
+		}
+	}
+
+	if err := s.removeHashtags(ctx, message); err != nil {
+		logger.LogError("Error removing message from hashtags", "error", err, "message_id", messageID, "user_id", userID)
+	}
+
+	logger.LogInfo("Message deleted successfully", "message_id", messageID, "user_id", userID)
+	return message, nil
 }
 
 func (s *MessageService) saveMessage(ctx context.Context, message *model.Message) error {
@@ -82,6 +217,153 @@ func (s *MessageService) saveMessage(ctx context.Context, message *model.Message
 	return s.dbClient.PutItem(ctx, s.dbClient.GetMessagesTableName(), item)
 }
 
+// indexEntities makes a stored message findable by its hashtags. The message
+// is already saved, so a failure is logged rather than failing the post.
+func (s *MessageService) indexEntities(ctx context.Context, message *model.Message) {
+	if err := s.indexHashtags(ctx, message); err != nil {
+		logger.LogError("Error indexing hashtags", "error", err, "message_id", message.ID, "user_id", message.UserID)
+	}
+}
+
+func messageKey(message *model.Message) (map[string]types.AttributeValue, error) {
+	createdAt, err := attributevalue.Marshal(message.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]types.AttributeValue{
+		"user_id":    &types.AttributeValueMemberS{Value: message.UserID},
+		"created_at": createdAt,
+	}, nil
+}
+
+// created_at is stored as an RFC 3339 string that drops trailing zeros from
+// the fraction, so string order can disagree with time order inside a second.
+// Key conditions therefore bound on whole seconds, widened so they match a
+// superset, and callers check the exact range on the decoded times.
+
+// createdAtUpperBound is meant for "created_at < :bound".
+func createdAtUpperBound(before time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: before.Truncate(time.Second).Add(time.Second).Local().Format(time.RFC3339)}
+}
+
+// createdAtLowerBound is meant for "created_at > :bound".
+func createdAtLowerBound(after time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: after.Truncate(time.Second).Add(-time.Second).Local().Format(time.RFC3339)}
+}
+
+func getMessage(ctx context.Context, dbClient database.DDBClientInterface, messageID string) (*model.Message, error) {
+	result, err := dbClient.Query(ctx, &dynamodb.QueryInput{
+		TableName:              aws.String(dbClient.GetMessagesTableName()),
+		IndexName:              aws.String("MessageIdIndex"),
+		KeyConditionExpression: aws.String("message_id = :message_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":message_id": &types.AttributeValueMemberS{Value: messageID},
+		},
+		Limit: aws.Int32(1),
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Items) == 0 {
+		return nil, ErrMessageNotFound
+	}
+
+	var message model.Message
+	if err := attributevalue.UnmarshalMap(result.Items[0], &message); err != nil {
+		return nil, err
+	}
+	return &message, nil
+}
+
+// batchGetMessages reads messages by their table keys, indexed by ID. Keys
+// of deleted messages are left out.
+func batchGetMessages(ctx context.Context, dbClient database.DDBClientInterface, keys []map[string]types.AttributeValue) (map[string]*model.Message, error) {
+	messages := make(map[string]*model.Message, len(keys))
+	if len(keys) == 0 {
+		return messages, nil
+	}
+	items, err := dbClient.BatchGetItem(ctx, dbClient.GetMessagesTableName(), keys)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		message := &model.Message{}
+		if err := attributevalue.UnmarshalMap(item, message); err != nil {
+			return nil, err
+		}
+		messages[message.ID] = message
+	}
+	return messages, nil
+}
+
+// originalKey is the table key of the message that message reposts or
+// quotes, or nil when it wasn't recorded.
+func originalKey(message *model.Message) (map[string]types.AttributeValue, error) {
+	if message.OriginalUserID == "" || message.OriginalCreatedAt == nil {
+		return nil, nil
+	}
+	return messageKey(&model.Message{UserID: message.OriginalUserID, CreatedAt: *message.OriginalCreatedAt})
+}
+
+// messageRef points at a message from another table. UserID and CreatedAt, the
+// message's table key, are empty on rows stored before they were recorded.
+type messageRef struct {
+	ID        string
+	UserID    string
+	CreatedAt *time.Time
+}
+
+// loadMessages reads the referenced messages in refs order, in one batch for
+// those with a key and by ID for the rest. Deleted messages are left out.
+func loadMessages(ctx context.Context, dbClient database.DDBClientInterface, refs []messageRef) ([]*model.Message, error) {
+	var keys []map[string]types.AttributeValue
+	for _, ref := range refs {
+		if ref.UserID == "" || ref.CreatedAt == nil {
+			continue
+		}
+		key, err := messageKey(&model.Message{UserID: ref.UserID, CreatedAt: *ref.CreatedAt})
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	found, err := batchGetMessages(ctx, dbClient, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	messages := make([]*model.Message, 0, len(refs))
+	for _, ref := range refs {
+		message, ok := found[ref.ID]
+		if !ok && (ref.UserID == "" || ref.CreatedAt == nil) {
+			message, err = getMessage(ctx, dbClient, ref.ID)
+			if errors.Is(err, ErrMessageNotFound) {
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+			ok = true
+		}
+		if ok {
+			messages = append(messages, message)
+		}
+	}
+	return messages, nil
+}
+
+func messageExists(ctx context.Context, dbClient database.DDBClientInterface, message *model.Message) (bool, error) {
+	key, err := messageKey(message)
+	if err != nil {
+		return false, err
+	}
+	result, err := dbClient.GetItem(ctx, dbClient.GetMessagesTableName(), key)
+	if err != nil {
+		return false, err
+	}
+	return len(result.Item) > 0, nil
+}
+
 func generateUUID() string {
 	return uuid.New().String()
 }
@@ -7,6 +7,8 @@ import (
 
 	"mensajesService/components/database"
 	"mensajesService/components/logger"
+	"mensajesService/components/pagination"
+	"mensajesService/message-api/model"
 
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
 	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
@@ -25,6 +27,11 @@ func (m *MockDDBClient) PutItem(ctx context.Context, tableName string, item map[
 	return args.Error(0)
 }
 
+func (m *MockDDBClient) PutItemWithCondition(ctx context.Context, input *dynamodb.PutItemInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
 func (m *MockDDBClient) GetItem(ctx context.Context, tableName string, key map[string]types.AttributeValue) (*dynamodb.GetItemOutput, error) {
 	args := m.Called(ctx, tableName, key)
 	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
@@ -35,6 +42,22 @@ func (m *MockDDBClient) Query(ctx context.Context, input *dynamodb.QueryInput) (
 	return args.Get(0).(*dynamodb.QueryOutput), args.Error(1)
 }
 
+func (m *MockDDBClient) DeleteItem(ctx context.Context, input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
+}
+
+func (m *MockDDBClient) UpdateItem(ctx context.Context, input *dynamodb.UpdateItemInput) (*dynamodb.UpdateItemOutput, error) {
+	args := m.Called(ctx, input)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
+}
+
 func (m *MockDDBClient) GetMessagesTableName() string {
 	args := m.Called()
 	return args.String(0)
@@ -50,9 +73,97 @@ func (m *MockDDBClient) GetTimelineTableName() string {
 	return args.String(0)
 }
 
+func (m *MockDDBClient) GetUserStatsTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) BatchWriteItem(ctx context.Context, tableName string, requests []types.WriteRequest) error {
+	args := m.Called(ctx, tableName, requests)
+	return args.Error(0)
+}
+
+func (m *MockDDBClient) BatchGetItem(ctx context.Context, tableName string, keys []map[string]types.AttributeValue) ([]map[string]types.AttributeValue, error) {
+	args := m.Called(ctx, tableName, keys)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]map[string]types.AttributeValue), args.Error(1)
+}
+
+func (m *MockDDBClient) GetJobsTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetDeadLettersTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetHeavyAuthorsTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetRepostsTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetLikesTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetMentionsTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetHashtagsTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetHashtagCountsTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetBlocksTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetMutesTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetAccountSettingsTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetFollowRequestsTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetUsersTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
+func (m *MockDDBClient) GetHandlesTableName() string {
+	args := m.Called()
+	return args.String(0)
+}
+
 func TestNewMessageService(t *testing.T) {
 	mockDB := &MockDDBClient{}
-	service := NewMessageService(mockDB)
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
 
 	assert.NotNil(t, service)
 	assert.Equal(t, mockDB, service.dbClient)
@@ -61,7 +172,7 @@ func TestNewMessageService(t *testing.T) {
 func TestCreateMessage_Success(t *testing.T) {
 	logger.Init()
 	mockDB := &MockDDBClient{}
-	service := NewMessageService(mockDB)
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
 
 	ctx := context.Background()
 	userID := "user123"
@@ -74,7 +185,7 @@ func TestCreateMessage_Success(t *testing.T) {
 		Items: []map[string]types.AttributeValue{},
 	}, nil)
 
-	message, err := service.CreateMessage(ctx, userID, content)
+	message, err := service.CreateMessage(ctx, userID, &model.CreateMessageRequest{Content: content})
 
 	assert.NoError(t, err)
 	assert.NotNil(t, message)
@@ -92,7 +203,7 @@ func TestCreateMessage_Success(t *testing.T) {
 
 func TestCreateMessage_DatabaseError(t *testing.T) {
 	mockDB := &MockDDBClient{}
-	service := NewMessageService(mockDB)
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
 
 	ctx := context.Background()
 	userID := "user123"
@@ -101,7 +212,7 @@ func TestCreateMessage_DatabaseError(t *testing.T) {
 	mockDB.On("GetMessagesTableName").Return("messages-table")
 	mockDB.On("PutItem", ctx, mock.AnythingOfType("string"), mock.AnythingOfType("map[string]types.AttributeValue")).Return(assert.AnError)
 
-	message, err := service.CreateMessage(ctx, userID, content)
+	message, err := service.CreateMessage(ctx, userID, &model.CreateMessageRequest{Content: content})
 
 	assert.Error(t, err)
 	assert.Nil(t, message)
@@ -111,7 +222,7 @@ func TestCreateMessage_DatabaseError(t *testing.T) {
 
 func TestGetUserMessages_Success(t *testing.T) {
 	mockDB := &MockDDBClient{}
-	service := NewMessageService(mockDB)
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
 
 	ctx := context.Background()
 	userID := "user123"
@@ -131,12 +242,194 @@ func TestGetUserMessages_Success(t *testing.T) {
 		Items: messages,
 	}, nil)
 
-	result, err := service.GetUserMessages(ctx, userID, limit)
+	result, err := service.GetUserMessages(ctx, userID, model.PageRequest{Limit: limit}, model.TimeRange{})
 
 	assert.NoError(t, err)
-	assert.Len(t, result, 1)
-	assert.Equal(t, "msg1", result[0].ID)
-	assert.Equal(t, "Test content 1", result[0].Content)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, "msg1", result.Items[0].ID)
+	assert.Equal(t, "Test content 1", result.Items[0].Content)
+	assert.Empty(t, result.NextCursor)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetUserMessages_Pagination(t *testing.T) {
+	mockDB := &MockDDBClient{}
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
 
+	ctx := context.Background()
+	lastKey := map[string]types.AttributeValue{
+		"user_id":    &types.AttributeValueMemberS{Value: "user123"},
+		"created_at": &types.AttributeValueMemberS{Value: "2024-01-01T00:00:00Z"},
+	}
+
+	mockDB.On("GetMessagesTableName").Return("messages-table")
+	mockDB.On("Query", ctx, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.QueryOutput{LastEvaluatedKey: lastKey}, nil).Once()
+	mockDB.On("Query", ctx, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return assert.ObjectsAreEqual(lastKey, input.ExclusiveStartKey)
+	})).Return(&dynamodb.QueryOutput{}, nil).Once()
+
+	first, err := service.GetUserMessages(ctx, "user123", model.PageRequest{Limit: 1}, model.TimeRange{})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, first.NextCursor)
+	assert.NotNil(t, first.Items)
+
+	second, err := service.GetUserMessages(ctx, "user123", model.PageRequest{Limit: 1, Cursor: first.NextCursor}, model.TimeRange{})
+	assert.NoError(t, err)
+	assert.Empty(t, second.NextCursor)
+
+	_, err = service.GetUserMessages(ctx, "user456", model.PageRequest{Limit: 1, Cursor: first.NextCursor}, model.TimeRange{})
+	assert.ErrorIs(t, err, pagination.ErrInvalidCursor)
+
+	mockDB.AssertExpectations(t)
+}
+
+func TestDeleteMessage_Success(t *testing.T) {
+	mockDB := &MockDDBClient{}
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
+
+	ctx := context.Background()
+	createdAt := time.Now().UTC()
+
+	mockDB.On("GetMessagesTableName").Return("messages-table")
+	mockDB.On("Query", ctx, mock.AnythingOfType("*dynamodb.QueryInput")).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"message_id": &types.AttributeValueMemberS{Value: "msg1"},
+				"user_id":    &types.AttributeValueMemberS{Value: "user123"},
+				"content":    &types.AttributeValueMemberS{Value: "Test content 1"},
+				"created_at": &types.AttributeValueMemberS{Value: createdAt.Format(time.RFC3339Nano)},
+			},
+		},
+	}, nil)
+	mockDB.On("DeleteItem", ctx, mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return input.Key["user_id"].(*types.AttributeValueMemberS).Value == "user123" &&
+			input.Key["created_at"].(*types.AttributeValueMemberS).Value == createdAt.Format(time.RFC3339Nano)
+	})).Return(&dynamodb.DeleteItemOutput{}, nil)
+
+	message, err := service.DeleteMessage(ctx, "user123", "msg1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "msg1", message.ID)
+	mockDB.AssertExpectations(t)
+}
+
+func TestDeleteMessage_NotFound(t *testing.T) {
+	mockDB := &MockDDBClient{}
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
+
+	ctx := context.Background()
+
+	mockDB.On("GetMessagesTableName").Return("messages-table")
+	mockDB.On("Query", ctx, mock.AnythingOfType("*dynamodb.QueryInput")).Return(&dynamodb.QueryOutput{}, nil)
+
+	message, err := service.DeleteMessage(ctx, "user456", "msg1")
+
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+	assert.Nil(t, message)
+	mockDB.AssertNotCalled(t, "DeleteItem", mock.Anything, mock.Anything)
+}
+
+func TestGetMessage_Success(t *testing.T) {
+	mockDB := &MockDDBClient{}
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
+
+	ctx := context.Background()
+
+	mockDB.On("GetMessagesTableName").Return("messages-table")
+	mockDB.On("Query", ctx, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return *input.IndexName == "MessageIdIndex" &&
+			input.ExpressionAttributeValues[":message_id"].(*types.AttributeValueMemberS).Value == "msg1"
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"message_id": &types.AttributeValueMemberS{Value: "msg1"},
+				"user_id":    &types.AttributeValueMemberS{Value: "user123"},
+				"content":    &types.AttributeValueMemberS{Value: "Test content 1"},
+				"created_at": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)},
+			},
+		},
+	}, nil)
+
+	message, err := service.GetMessage(ctx, "msg1")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "msg1", message.ID)
+	assert.Equal(t, "user123", message.UserID)
+	mockDB.AssertExpectations(t)
+}
+
+func TestGetMessage_NotFound(t *testing.T) {
+	mockDB := &MockDDBClient{}
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
+
+	ctx := context.Background()
+
+	mockDB.On("GetMessagesTableName").Return("messages-table")
+	mockDB.On("Query", ctx, mock.AnythingOfType("*dynamodb.QueryInput")).Return(&dynamodb.QueryOutput{}, nil)
+
+	message, err := service.GetMessage(ctx, "missing")
+
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+	assert.Nil(t, message)
+}
+
+func TestDeleteMessage_OtherAuthor(t *testing.T) {
+	mockDB := &MockDDBClient{}
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
+
+	ctx := context.Background()
+
+	mockDB.On("GetMessagesTableName").Return("messages-table")
+	mockDB.On("Query", ctx, mock.AnythingOfType("*dynamodb.QueryInput")).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"message_id": &types.AttributeValueMemberS{Value: "msg1"},
+				"user_id":    &types.AttributeValueMemberS{Value: "user123"},
+				"content":    &types.AttributeValueMemberS{Value: "Test content 1"},
+				"created_at": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)},
+			},
+		},
+	}, nil)
+
+	message, err := service.DeleteMessage(ctx, "user456", "msg1")
+
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+	assert.Nil(t, message)
+	mockDB.AssertNotCalled(t, "DeleteItem", mock.Anything, mock.Anything)
+}
+
+func TestGetUserMessages_TimeRange(t *testing.T) {
+	mockDB := &MockDDBClient{}
+	service := NewMessageService(mockDB, pagination.NewCursorCodec("secret"))
+
+	ctx := context.Background()
+	before := time.Date(2024, 1, 1, 12, 0, 0, 500000000, time.UTC)
+
+	mockDB.On("GetMessagesTableName").Return("messages-table")
+	mockDB.On("Query", ctx, mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return *input.KeyConditionExpression == "user_id = :user_id AND created_at < :before"
+	})).Return(&dynamodb.QueryOutput{
+		Items: []map[string]types.AttributeValue{
+			{
+				"message_id": &types.AttributeValueMemberS{Value: "same-second-later"},
+				"user_id":    &types.AttributeValueMemberS{Value: "user123"},
+				"created_at": &types.AttributeValueMemberS{Value: before.Add(100 * time.Millisecond).Format(time.RFC3339Nano)},
+			},
+			{
+				"message_id": &types.AttributeValueMemberS{Value: "earlier"},
+				"user_id":    &types.AttributeValueMemberS{Value: "user123"},
+				"created_at": &types.AttributeValueMemberS{Value: before.Add(-time.Minute).Format(time.RFC3339Nano)},
+			},
+		},
+	}, nil)
+
+	result, err := service.GetUserMessages(ctx, "user123", model.PageRequest{Limit: 10}, model.TimeRange{Before: before})
+
+	assert.NoError(t, err)
+	assert.Len(t, result.Items, 1)
+	assert.Equal(t, "earlier", result.Items[0].ID)
 	mockDB.AssertExpectations(t)
 }
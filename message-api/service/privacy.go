@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"mensajesService/components/database"
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// GetSettings returns userID's settings, with defaults for users who never
+// changed them.
+func (s *FollowService) GetSettings(ctx context.Context, userID string) (*model.AccountSettings, error) {
+	return accountSettings(ctx, s.dbClient, userID)
+}
+
+// UpdateSettings applies the fields set in request. Making an account public
+// leaves its pending follow requests in place, to be approved or rejected as
+// before; making it private keeps the followers it already has.
+func (s *FollowService) UpdateSettings(ctx context.Context, userID string, request *model.UpdateSettingsRequest) (*model.AccountSettings, error) {
+	if request.Private == nil {
+		return s.GetSettings(ctx, userID)
+	}
+
+	result, err := s.dbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+		TableName: aws.String(s.dbClient.GetAccountSettingsTableName()),
+		Key: map[string]types.AttributeValue{
+			"user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+		UpdateExpression: aws.String("SET #private = :private, updated_at = :updated_at"),
+		ExpressionAttributeNames: map[string]string{
+			"#private": "private",
+		},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":private":    &types.AttributeValueMemberBOOL{Value: *request.Private},
+			":updated_at": &types.AttributeValueMemberS{Value: time.Now().UTC().Format(time.RFC3339Nano)},
+		},
+		ReturnValues: types.ReturnValueAllNew,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &model.AccountSettings{}
+	if err := attributevalue.UnmarshalMap(result.Attributes, settings); err != nil {
+		return nil, err
+	}
+	logger.LogInfo("Settings updated successfully", "user_id", userID, "private", settings.Private)
+	return settings, nil
+}
+
+func accountSettings(ctx context.Context, dbClient database.DDBClientInterface, userID string) (*model.AccountSettings, error) {
+	result, err := dbClient.GetItem(ctx, dbClient.GetAccountSettingsTableName(), map[string]types.AttributeValue{
+		"user_id": &types.AttributeValueMemberS{Value: userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	settings := &model.AccountSettings{}
+	if err := attributevalue.UnmarshalMap(result.Item, settings); err != nil {
+		return nil, err
+	}
+	settings.UserID = userID
+	return settings, nil
+}
+
+func isPrivate(ctx context.Context, dbClient database.DDBClientInterface, userID string) (bool, error) {
+	settings, err := accountSettings(ctx, dbClient, userID)
+	if err != nil {
+		return false, err
+	}
+	return settings.Private, nil
+}
+
+// canViewMessages reports whether viewerID may read authorID's messages: the
+// author's own, a public account's, or a private account's approved
+// followers. An empty viewerID is an anonymous reader.
+func canViewMessages(ctx context.Context, dbClient database.DDBClientInterface, viewerID, authorID string) (bool, error) {
+	if viewerID == authorID {
+		return true, nil
+	}
+	private, err := isPrivate(ctx, dbClient, authorID)
+	if err != nil || !private {
+		return !private, err
+	}
+	if viewerID == "" {
+		return false, nil
+	}
+	return followsUser(ctx, dbClient, viewerID, authorID)
+}
+
+// viewableMessage looks messageID up for viewerID, reporting a message of a
+// private account viewerID doesn't follow as ErrMessageNotFound so that
+// liking or replying to it doesn't reveal that it exists.
+func (s *MessageService) viewableMessage(ctx context.Context, viewerID, messageID string) (*model.Message, error) {
+	message, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	visible, err := canViewMessages(ctx, s.dbClient, viewerID, message.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if !visible {
+		return nil, ErrMessageNotFound
+	}
+	return message, nil
+}
+
+func followsUser(ctx context.Context, dbClient database.DDBClientInterface, followerID, followingID string) (bool, error) {
+	result, err := dbClient.GetItem(ctx, dbClient.GetFollowersTableName(), followKey(followerID, followingID))
+	if err != nil {
+		return false, err
+	}
+	return len(result.Item) > 0, nil
+}
+
+// VisibleTo returns, in order, the messages viewerID may read.
+func (s *MessageService) VisibleTo(ctx context.Context, viewerID string, messages []*model.Message) ([]*model.Message, error) {
+	visible, err := visibleAuthors(ctx, s.dbClient, viewerID, messages)
+	if err != nil {
+		return nil, err
+	}
+
+	filtered := make([]*model.Message, 0, len(messages))
+	for _, message := range messages {
+		if visible[message.UserID] {
+			filtered = append(filtered, message)
+		}
+	}
+	return filtered, nil
+}
+
+// VisibleThread drops from thread the messages viewerID may not read: hidden
+// ancestors are left out of the chain, and a hidden reply is removed with the
+// replies below it. It reports false when the message the thread was read
+// from is hidden itself.
+func (s *MessageService) VisibleThread(ctx context.Context, viewerID string, thread *model.Thread) (bool, error) {
+	messages := append([]*model.Message(nil), thread.Ancestors...)
+	var collect func(node *model.ThreadNode)
+	collect = func(node *model.ThreadNode) {
+		messages = append(messages, node.Message)
+		for _, reply := range node.Replies {
+			collect(reply)
+		}
+	}
+	collect(thread.Root)
+
+	visible, err := visibleAuthors(ctx, s.dbClient, viewerID, messages)
+	if err != nil {
+		return false, err
+	}
+	if !visible[thread.Root.Message.UserID] {
+		return false, nil
+	}
+
+	ancestors := make([]*model.Message, 0, len(thread.Ancestors))
+	for _, ancestor := range thread.Ancestors {
+		if visible[ancestor.UserID] {
+			ancestors = append(ancestors, ancestor)
+		}
+	}
+	thread.Ancestors = ancestors
+
+	var prune func(node *model.ThreadNode)
+	prune = func(node *model.ThreadNode) {
+		replies := make([]*model.ThreadNode, 0, len(node.Replies))
+		for _, reply := range node.Replies {
+			if visible[reply.Message.UserID] {
+				prune(reply)
+				replies = append(replies, reply)
+			}
+		}
+		node.Replies = replies
+	}
+	prune(thread.Root)
+	return true, nil
+}
+
+// visibleAuthors reports whether viewerID may read each of the messages'
+// authors.
+func visibleAuthors(ctx context.Context, dbClient database.DDBClientInterface, viewerID string, messages []*model.Message) (map[string]bool, error) {
+	authorIDs := make([]string, len(messages))
+	for i, message := range messages {
+		authorIDs[i] = message.UserID
+	}
+	return viewableAuthors(ctx, dbClient, viewerID, authorIDs)
+}
+
+// viewableAuthors applies canViewMessages to many authors at once, with one
+// batch read of their settings and one of viewerID's follows of the private
+// ones.
+func viewableAuthors(ctx context.Context, dbClient database.DDBClientInterface, viewerID string, authorIDs []string) (map[string]bool, error) {
+	visible := make(map[string]bool, len(authorIDs))
+	var settingsKeys []map[string]types.AttributeValue
+	for _, authorID := range authorIDs {
+		if _, ok := visible[authorID]; ok {
+			continue
+		}
+		visible[authorID] = true
+		if authorID != viewerID {
+			settingsKeys = append(settingsKeys, map[string]types.AttributeValue{
+				"user_id": &types.AttributeValueMemberS{Value: authorID},
+			})
+		}
+	}
+	if len(settingsKeys) == 0 {
+		return visible, nil
+	}
+
+	items, err := dbClient.BatchGetItem(ctx, dbClient.GetAccountSettingsTableName(), settingsKeys)
+	if err != nil {
+		return nil, err
+	}
+	var followKeys []map[string]types.AttributeValue
+	for _, item := range items {
+		var settings model.AccountSettings
+		if err := attributevalue.UnmarshalMap(item, &settings); err != nil {
+			return nil, err
+		}
+		if !settings.Private {
+			continue
+		}
+		visible[settings.UserID] = false
+		if viewerID != "" {
+			followKeys = append(followKeys, followKey(viewerID, settings.UserID))
+		}
+	}
+	follows, err := batchGetFollows(ctx, dbClient, followKeys)
+	if err != nil {
+		return nil, err
+	}
+	for _, follow := range follows {
+		visible[follow.FollowingID] = true
+	}
+	return visible, nil
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+
+	"mensajesService/components/config"
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// fanoutSources lists the users whose followers receive message. Heavy
+// authors are left out, since their followers read them at request time;
+// under the "all" policy a reply also reaches the followers of the author it
+// answers, unless that author is heavy or the reply's author is private.
+func (s *TimelineService) fanoutSources(ctx context.Context, message *model.Message) ([]string, error) {
+	var sources []string
+
+	heavy, err := s.heavyAuthors.IsHeavy(ctx, message.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if heavy {
+		logger.LogInfo("Skipping fan-out, heavy author is merged at read time", "message_id", message.ID, "user_id", message.UserID)
+	} else {
+		sources = append(sources, message.UserID)
+	}
+
+	parentAuthor := message.InReplyToUserID
+	if s.replyFanout != config.ReplyFanoutAll || parentAuthor == "" || parentAuthor == message.UserID {
+		return sources, nil
+	}
+	// A private author's messages only reach their approved followers.
+	private, err := isPrivate(ctx, s.dbClient, message.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if private {
+		return sources, nil
+	}
+	heavy, err = s.heavyAuthors.IsHeavy(ctx, parentAuthor)
+	if err != nil {
+		return nil, err
+	}
+	if heavy {
+		logger.LogInfo("Skipping reply fan-out to heavy author's followers", "message_id", message.ID, "user_id", parentAuthor)
+		return sources, nil
+	}
+	return append(sources, parentAuthor), nil
+}
+
+// replyAudience applies the "mutual" policy, under which a reply only reaches
+// users who follow both its author and the author it answers. It returns which
+// of userIDs may see message, checking their follows with batch reads, or nil
+// when the policy or the message doesn't restrict the audience.
+func (s *TimelineService) replyAudience(ctx context.Context, message *model.Message, userIDs []string) (map[string]bool, error) {
+	parentAuthor := message.InReplyToUserID
+	if s.replyFanout != config.ReplyFanoutMutual || parentAuthor == "" {
+		return nil, nil
+	}
+
+	keys := make([]map[string]types.AttributeValue, 0, len(userIDs))
+	for _, userID := range userIDs {
+		keys = append(keys, followKey(userID, parentAuthor))
+	}
+	follows, err := batchGetFollows(ctx, s.dbClient, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	audience := map[string]bool{parentAuthor: true}
+	for _, follow := range follows {
+		audience[follow.FollowerID] = true
+	}
+	return audience, nil
+}
+
+// visibleReplies applies the "mutual" policy to messages read for userID,
+// dropping the replies to authors userID doesn't follow.
+func (s *TimelineService) visibleReplies(ctx context.Context, userID string, messages []*model.Message) ([]*model.Message, error) {
+	if s.replyFanout != config.ReplyFanoutMutual {
+		return messages, nil
+	}
+
+	var keys []map[string]types.AttributeValue
+	for _, message := range messages {
+		if message.InReplyToUserID != "" {
+			keys = append(keys, followKey(userID, message.InReplyToUserID))
+		}
+	}
+	follows, err := batchGetFollows(ctx, s.dbClient, keys)
+	if err != nil {
+		return nil, err
+	}
+
+	followed := map[string]bool{userID: true}
+	for _, follow := range follows {
+		followed[follow.FollowingID] = true
+	}
+	visible := make([]*model.Message, 0, len(messages))
+	for _, message := range messages {
+		if message.InReplyToUserID == "" || followed[message.InReplyToUserID] {
+			visible = append(visible, message)
+		}
+	}
+	return visible, nil
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"mensajesService/components/config"
+	"mensajesService/components/database"
+	"mensajesService/message-api/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fanOutReply has bob reply to alice. dave follows both, erin only bob and
+// frank only alice.
+func fanOutReply(t *testing.T, policy string) *database.MemoryClient {
+	services := newTestServices(testOptions{replyFanout: policy})
+	service, dbClient := services.timeline, services.db
+	follow(t, dbClient, "dave", "alice")
+	follow(t, dbClient, "dave", "bob")
+	follow(t, dbClient, "erin", "bob")
+	follow(t, dbClient, "frank", "alice")
+
+	reply := &model.Message{
+		ID:              "reply",
+		UserID:          "bob",
+		Content:         "reply",
+		CreatedAt:       time.Now(),
+		InReplyTo:       "root",
+		InReplyToUserID: "alice",
+		ConversationID:  "root",
+	}
+	require.NoError(t, service.UpdateFollowersTimeline(context.Background(), reply))
+	return dbClient
+}
+
+func TestUpdateFollowersTimeline_ReplyPolicyFollowers(t *testing.T) {
+	dbClient := fanOutReply(t, config.ReplyFanoutFollowers)
+
+	assert.Equal(t, []string{"reply"}, timelineMessageIDs(t, dbClient, "dave"))
+	assert.Equal(t, []string{"reply"}, timelineMessageIDs(t, dbClient, "erin"))
+	assert.Empty(t, timelineMessageIDs(t, dbClient, "frank"))
+}
+
+func TestUpdateFollowersTimeline_ReplyPolicyMutual(t *testing.T) {
+	dbClient := fanOutReply(t, config.ReplyFanoutMutual)
+
+	assert.Equal(t, []string{"reply"}, timelineMessageIDs(t, dbClient, "dave"))
+	assert.Empty(t, timelineMessageIDs(t, dbClient, "erin"))
+	assert.Empty(t, timelineMessageIDs(t, dbClient, "frank"))
+}
+
+func TestUpdateFollowersTimeline_ReplyPolicyAll(t *testing.T) {
+	dbClient := fanOutReply(t, config.ReplyFanoutAll)
+
+	assert.Equal(t, []string{"reply"}, timelineMessageIDs(t, dbClient, "dave"))
+	assert.Equal(t, []string{"reply"}, timelineMessageIDs(t, dbClient, "erin"))
+	assert.Equal(t, []string{"reply"}, timelineMessageIDs(t, dbClient, "frank"))
+}
+
+func TestRemoveFromFollowersTimeline_ReplyPolicyAll(t *testing.T) {
+	services := newTestServices(testOptions{replyFanout: config.ReplyFanoutAll})
+	service, dbClient := services.timeline, services.db
+	follow(t, dbClient, "erin", "bob")
+	follow(t, dbClient, "frank", "alice")
+	reply := &model.Message{ID: "reply", UserID: "bob", Content: "reply", CreatedAt: time.Now(), InReplyTo: "root", InReplyToUserID: "alice"}
+	require.NoError(t, service.UpdateFollowersTimeline(context.Background(), reply))
+
+	require.NoError(t, service.RemoveFromFollowersTimeline(context.Background(), reply))
+
+	assert.Empty(t, timelineMessageIDs(t, dbClient, "erin"))
+	assert.Empty(t, timelineMessageIDs(t, dbClient, "frank"))
+}
+
+func TestUpdateFollowersTimeline_ReplyPolicyMutualBatchesFollowChecks(t *testing.T) {
+	services := newTestServices(testOptions{replyFanout: config.ReplyFanoutMutual})
+	service, dbClient := services.timeline, services.db
+	follow(t, dbClient, "alice", "bob")
+	for i := 0; i < 30; i++ {
+		followerID := fmt.Sprintf("follower%d", i)
+		follow(t, dbClient, followerID, "bob")
+		if i%2 == 0 {
+			follow(t, dbClient, followerID, "alice")
+		}
+	}
+	counting := &countingDBClient{MemoryClient: dbClient}
+	service.dbClient = counting
+
+	reply := &model.Message{ID: "reply", UserID: "bob", Content: "reply", CreatedAt: time.Now(), InReplyTo: "root", InReplyToUserID: "alice"}
+	require.NoError(t, service.UpdateFollowersTimeline(context.Background(), reply))
+
+	assert.Zero(t, counting.gets)
+	assert.Equal(t, []string{"reply"}, timelineMessageIDs(t, dbClient, "alice"))
+	assert.Equal(t, []string{"reply"}, timelineMessageIDs(t, dbClient, "follower0"))
+	assert.Empty(t, timelineMessageIDs(t, dbClient, "follower1"))
+}
+
+func TestGetUserTimeline_ReplyPolicyMutualForHeavyAuthor(t *testing.T) {
+	services := newTestServices(testOptions{replyFanout: config.ReplyFanoutMutual, heavyThreshold: 1})
+	heavyAuthors, service, dbClient := services.heavyAuthors, services.timeline, services.db
+	ctx := context.Background()
+	require.NoError(t, heavyAuthors.Observe(ctx, "bob", 1))
+	follow(t, dbClient, "dave", "alice")
+	follow(t, dbClient, "dave", "bob")
+	follow(t, dbClient, "erin", "bob")
+	putMessage(t, dbClient, &model.Message{ID: "reply", UserID: "bob", Content: "reply", CreatedAt: time.Now(), InReplyTo: "root", InReplyToUserID: "alice"})
+
+	for userID, expected := range map[string]int{"dave": 1, "erin": 0} {
+		page, err := service.GetUserTimeline(ctx, userID, model.PageRequest{Limit: 10})
+		require.NoError(t, err)
+		assert.Len(t, page.Items, expected, userID)
+	}
+}
@@ -0,0 +1,159 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// repostRecord marks that a user reposted a message. Its key is what makes
+// a second repost of the same message fail.
+type repostRecord struct {
+	MessageID string    `dynamodbav:"message_id"`
+	UserID    string    `dynamodbav:"user_id"`
+	RepostID  string    `dynamodbav:"repost_id"`
+	CreatedAt time.Time `dynamodbav:"created_at"`
+}
+
+// Repost shares messageID as a new message of userID. With content it is a
+// quote post, which may be repeated; a plain repost is allowed once per user
+// and message. Reposting a repost shares its original. Only a private
+// account may repost or quote its own messages, since a repost would carry
+// them past its approved followers; others get ErrPrivateRepost.
+func (s *MessageService) Repost(ctx context.Context, userID, messageID string, request *model.RepostRequest) (*model.Message, error) {
+	original, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+	if original.RepostOf != "" {
+		original, err = s.GetMessage(ctx, original.RepostOf)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if original.UserID != userID {
+		private, err := isPrivate(ctx, s.dbClient, original.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if private {
+			return nil, ErrPrivateRepost
+		}
+	}
+
+	repostID := generateUUID()
+	message := &model.Message{
+		ID:                repostID,
+		UserID:            userID,
+		Content:           request.Content,
+		CreatedAt:         time.Now(),
+		ConversationID:    repostID,
+		OriginalUserID:    original.UserID,
+		OriginalCreatedAt: &original.CreatedAt,
+	}
+
+	if request.Content != "" {
+		message.QuoteOf = original.ID
+		message.Entities = parseEntities(request.Content)
+		if err := s.saveMessage(ctx, message); err != nil {
+			return nil, err
+		}
+		s.indexEntities(ctx, message)
+		logger.LogInfo("Quote post created successfully", "message_id", message.ID, "quote_of", original.ID, "user_id", userID)
+		return message, nil
+	}
+
+	message.RepostOf = original.ID
+	record, err := attributevalue.MarshalMap(repostRecord{
+		MessageID: original.ID,
+		UserID:    userID,
+		RepostID:  message.ID,
+		CreatedAt: message.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = s.dbClient.PutItemWithCondition(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.dbClient.GetRepostsTableName()),
+		Item:                record,
+		ConditionExpression: aws.String("attribute_not_exists(message_id)"),
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil, ErrAlreadyReposted
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.saveMessage(ctx, message); err != nil {
+		if cleanupErr := s.deleteRepostRecord(ctx, message); cleanupErr != nil {
+			logger.LogError("Error rolling back repost record", "error", cleanupErr, "message_id", original.ID, "user_id", userID)
+		}
+		return nil, err
+	}
+
+	logger.LogInfo("Repost created successfully", "message_id", message.ID, "repost_of", original.ID, "user_id", userID)
+	return message, nil
+}
+
+// UndoRepost deletes userID's repost of messageID and returns it, so its
+// fan-out can be retracted. The result is nil when only a dangling record
+// was left behind by a repost that was never stored.
+func (s *MessageService) UndoRepost(ctx context.Context, userID, messageID string) (*model.Message, error) {
+	key := map[string]types.AttributeValue{
+		"message_id": &types.AttributeValueMemberS{Value: messageID},
+		"user_id":    &types.AttributeValueMemberS{Value: userID},
+	}
+	result, err := s.dbClient.GetItem(ctx, s.dbClient.GetRepostsTableName(), key)
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Item) == 0 {
+		return nil, ErrRepostNotFound
+	}
+
+	var record repostRecord
+	if err := attributevalue.UnmarshalMap(result.Item, &record); err != nil {
+		return nil, err
+	}
+
+	// DeleteMessage also removes the record.
+	repost, err := s.DeleteMessage(ctx, userID, record.RepostID)
+	if errors.Is(err, ErrMessageNotFound) {
+		return nil, s.deleteRepostRecord(ctx, &model.Message{ID: record.RepostID, UserID: userID, RepostOf: messageID})
+	}
+	if err != nil {
+		return nil, err
+	}
+	return repost, nil
+}
+
+// deleteRepostRecord removes the record of repost, unless it already points
+// at a newer repost of the same message.
+func (s *MessageService) deleteRepostRecord(ctx context.Context, repost *model.Message) error {
+	_, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.dbClient.GetRepostsTableName()),
+		Key: map[string]types.AttributeValue{
+			"message_id": &types.AttributeValueMemberS{Value: repost.RepostOf},
+			"user_id":    &types.AttributeValueMemberS{Value: repost.UserID},
+		},
+		ConditionExpression: aws.String("repost_id = :repost_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":repost_id": &types.AttributeValueMemberS{Value: repost.ID},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mensajesService/message-api/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepost_RejectsDuplicate(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+	original, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "original"})
+	require.NoError(t, err)
+
+	repost, err := service.Repost(ctx, "bob", original.ID, &model.RepostRequest{})
+	require.NoError(t, err)
+	assert.Equal(t, original.ID, repost.RepostOf)
+	assert.Empty(t, repost.Content)
+	assert.Equal(t, "alice", repost.OriginalUserID)
+	require.NotNil(t, repost.OriginalCreatedAt)
+	assert.True(t, repost.OriginalCreatedAt.Equal(original.CreatedAt))
+
+	_, err = service.Repost(ctx, "bob", original.ID, &model.RepostRequest{})
+	assert.ErrorIs(t, err, ErrAlreadyReposted)
+
+	// Reposting the repost shares the original, so it's a duplicate too.
+	_, err = service.Repost(ctx, "bob", repost.ID, &model.RepostRequest{})
+	assert.ErrorIs(t, err, ErrAlreadyReposted)
+
+	_, err = service.Repost(ctx, "carol", repost.ID, &model.RepostRequest{})
+	assert.NoError(t, err)
+}
+
+func TestRepost_QuotesMayRepeat(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+	original, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "original"})
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		quote, err := service.Repost(ctx, "bob", original.ID, &model.RepostRequest{Content: "look at this"})
+		require.NoError(t, err)
+		assert.Equal(t, original.ID, quote.QuoteOf)
+		assert.Empty(t, quote.RepostOf)
+		assert.Equal(t, "look at this", quote.Content)
+	}
+
+	_, err = service.Repost(ctx, "bob", original.ID, &model.RepostRequest{})
+	assert.NoError(t, err)
+}
+
+func TestRepost_NotFound(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+
+	_, err := service.Repost(context.Background(), "bob", "missing", &model.RepostRequest{})
+
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+}
+
+func TestUndoRepost_AllowsRepostingAgain(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+	original, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "original"})
+	require.NoError(t, err)
+	repost, err := service.Repost(ctx, "bob", original.ID, &model.RepostRequest{})
+	require.NoError(t, err)
+
+	undone, err := service.UndoRepost(ctx, "bob", original.ID)
+	require.NoError(t, err)
+	assert.Equal(t, repost.ID, undone.ID)
+	_, err = service.GetMessage(ctx, repost.ID)
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+
+	_, err = service.UndoRepost(ctx, "bob", original.ID)
+	assert.ErrorIs(t, err, ErrRepostNotFound)
+
+	_, err = service.Repost(ctx, "bob", original.ID, &model.RepostRequest{})
+	assert.NoError(t, err)
+}
+
+func TestDeleteMessage_RepostAllowsRepostingAgain(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+	original, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "original"})
+	require.NoError(t, err)
+	repost, err := service.Repost(ctx, "bob", original.ID, &model.RepostRequest{})
+	require.NoError(t, err)
+
+	_, err = service.DeleteMessage(ctx, "bob", repost.ID)
+	require.NoError(t, err)
+
+	_, err = service.Repost(ctx, "bob", original.ID, &model.RepostRequest{})
+	assert.NoError(t, err)
+}
+
+func TestGetUserTimeline_EmbedsOriginal(t *testing.T) {
+	services := newTestServices(testOptions{})
+	timelineService, dbClient := services.timeline, services.db
+	ctx := context.Background()
+
+	now := time.Now()
+	original := &model.Message{ID: "original", UserID: "alice", Content: "original", CreatedAt: now.Add(-time.Hour)}
+	putMessage(t, dbClient, original)
+	follow(t, dbClient, "carol", "bob")
+	require.NoError(t, timelineService.UpdateFollowersTimeline(ctx, &model.Message{ID: "repost", UserID: "bob", CreatedAt: now.Add(-time.Minute), RepostOf: "original"}))
+	require.NoError(t, timelineService.UpdateFollowersTimeline(ctx, &model.Message{ID: "quote", UserID: "bob", Content: "quote", CreatedAt: now, QuoteOf: "gone"}))
+
+	page, err := timelineService.GetUserTimeline(ctx, "carol", model.PageRequest{Limit: 10})
+
+	require.NoError(t, err)
+	require.Len(t, page.Items, 2)
+	assert.Equal(t, "quote", page.Items[0].MessageID)
+	assert.Nil(t, page.Items[0].Original)
+	assert.Equal(t, "repost", page.Items[1].MessageID)
+	require.NotNil(t, page.Items[1].Original)
+	assert.Equal(t, "alice", page.Items[1].Original.UserID)
+}
+
+func TestRepost_PrivateMessageDoesNotReachNonFollowers(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, timelineService, dbClient := services.messages, services.timeline, services.db
+	ctx := context.Background()
+	follow(t, dbClient, "bob", "alice")
+	follow(t, dbClient, "carol", "bob")
+
+	original, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "friends only"})
+	require.NoError(t, err)
+	// A repost made while alice was still public.
+	repost, err := service.Repost(ctx, "bob", original.ID, &model.RepostRequest{})
+	require.NoError(t, err)
+	require.NoError(t, timelineService.UpdateFollowersTimeline(ctx, repost))
+	makePrivate(t, dbClient, "alice")
+
+	// bob may read alice's messages, but not share them with carol.
+	_, err = service.Repost(ctx, "bob", original.ID, &model.RepostRequest{})
+	assert.ErrorIs(t, err, ErrPrivateRepost)
+	_, err = service.Repost(ctx, "bob", repost.ID, &model.RepostRequest{Content: "look"})
+	assert.ErrorIs(t, err, ErrPrivateRepost)
+	_, err = service.Repost(ctx, "alice", original.ID, &model.RepostRequest{Content: "again"})
+	assert.NoError(t, err)
+
+	page, err := timelineService.GetUserTimeline(ctx, "carol", model.PageRequest{Limit: 10})
+	require.NoError(t, err)
+	require.Len(t, page.Items, 1)
+	assert.Equal(t, repost.ID, page.Items[0].MessageID)
+	assert.Nil(t, page.Items[0].Original)
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxThreadMessages caps how much of a conversation a thread view loads.
+const maxThreadMessages = 1000
+
+// threadPageSize is how many messages a thread view reads per query.
+const threadPageSize = 200
+
+// GetThread returns the conversation around messageID: its ancestors up to
+// the conversation root and its replies, oldest first, depth levels deep.
+func (s *MessageService) GetThread(ctx context.Context, messageID string, depth int) (*model.Thread, error) {
+	message, err := s.GetMessage(ctx, messageID)
+	if err != nil {
+		return nil, err
+	}
+
+	conversation := conversationID(message)
+	messages, truncated, err := s.conversationMessages(ctx, conversation)
+	if err != nil {
+		logger.LogError("Error loading conversation", "error", err, "conversation_id", conversation)
+		return nil, err
+	}
+
+	byID := make(map[string]*model.Message, len(messages)+1)
+	for _, m := range messages {
+		byID[m.ID] = m
+	}
+	byID[message.ID] = message
+	// Messages written before conversations were tracked aren't in the index,
+	// but a root is still reachable by its ID.
+	if _, ok := byID[conversation]; !ok {
+		root, err := s.GetMessage(ctx, conversation)
+		if err != nil && !errors.Is(err, ErrMessageNotFound) {
+			return nil, err
+		}
+		if root != nil {
+			byID[root.ID] = root
+		}
+	}
+
+	replies := make(map[string][]*model.Message)
+	for _, m := range byID {
+		if m.InReplyTo != "" {
+			replies[m.InReplyTo] = append(replies[m.InReplyTo], m)
+		}
+	}
+	for _, children := range replies {
+		sort.Slice(children, func(i, j int) bool {
+			if children[i].CreatedAt.Equal(children[j].CreatedAt) {
+				return children[i].ID < children[j].ID
+			}
+			return children[i].CreatedAt.Before(children[j].CreatedAt)
+		})
+	}
+
+	return &model.Thread{
+		ConversationID: conversation,
+		Ancestors:      threadAncestors(message, byID),
+		Root:           threadNode(message, replies, depth),
+		Truncated:      truncated,
+	}, nil
+}
+
+// conversationMessages reads a conversation from ConversationIndex, oldest
+// first, and reports whether it stopped at maxThreadMessages.
+func (s *MessageService) conversationMessages(ctx context.Context, conversation string) ([]*model.Message, bool, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(s.dbClient.GetMessagesTableName()),
+		IndexName:              aws.String("ConversationIndex"),
+		KeyConditionExpression: aws.String("conversation_id = :conversation_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":conversation_id": &types.AttributeValueMemberS{Value: conversation},
+		},
+		ScanIndexForward: aws.Bool(true),
+		Limit:            aws.Int32(threadPageSize),
+	}
+
+	var messages []*model.Message
+	for {
+		result, err := s.dbClient.Query(ctx, input)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var page []*model.Message
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, false, err
+		}
+		messages = append(messages, page...)
+
+		if len(messages) >= maxThreadMessages {
+			return messages[:maxThreadMessages], len(messages) > maxThreadMessages || len(result.LastEvaluatedKey) > 0, nil
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return messages, false, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// threadAncestors walks up from message to the conversation root, stopping
+// early where a parent was deleted or not loaded.
+func threadAncestors(message *model.Message, byID map[string]*model.Message) []*model.Message {
+	ancestors := []*model.Message{}
+	seen := map[string]bool{message.ID: true}
+	for parentID := message.InReplyTo; parentID != "" && !seen[parentID]; {
+		parent, ok := byID[parentID]
+		if !ok {
+			break
+		}
+		seen[parentID] = true
+		ancestors = append(ancestors, parent)
+		parentID = parent.InReplyTo
+	}
+
+	for i, j := 0, len(ancestors)-1; i < j; i, j = i+1, j-1 {
+		ancestors[i], ancestors[j] = ancestors[j], ancestors[i]
+	}
+	return ancestors
+}
+
+func threadNode(message *model.Message, replies map[string][]*model.Message, depth int) *model.ThreadNode {
+	node := &model.ThreadNode{Message: message, Replies: []*model.ThreadNode{}}
+	if depth == 0 {
+		node.MoreReplies = len(replies[message.ID]) > 0
+		return node
+	}
+	for _, reply := range replies[message.ID] {
+		node.Replies = append(node.Replies, threadNode(reply, replies, depth-1))
+	}
+	return node
+}
+
+// conversationID is the conversation a message belongs to. Messages stored
+// before conversations were tracked start their own.
+func conversationID(message *model.Message) string {
+	if message.ConversationID != "" {
+		return message.ConversationID
+	}
+	return message.ID
+}
@@ -0,0 +1,132 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mensajesService/components/database"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func putMessage(t *testing.T, dbClient *database.MemoryClient, message *model.Message) {
+	item, err := attributevalue.MarshalMap(message)
+	require.NoError(t, err)
+	require.NoError(t, dbClient.PutItem(context.Background(), "messages", item))
+}
+
+// seedThread stores root <- a <- a1 <- a1x and root <- b, one second apart.
+func seedThread(t *testing.T, dbClient *database.MemoryClient) {
+	start := time.Now().Add(-time.Hour)
+	reply := func(id, parentID, parentAuthor string, offset int) *model.Message {
+		return &model.Message{
+			ID:              id,
+			UserID:          "user-" + id,
+			Content:         id,
+			CreatedAt:       start.Add(time.Duration(offset) * time.Second),
+			InReplyTo:       parentID,
+			InReplyToUserID: parentAuthor,
+			ConversationID:  "root",
+		}
+	}
+	putMessage(t, dbClient, &model.Message{ID: "root", UserID: "user-root", Content: "root", CreatedAt: start, ConversationID: "root"})
+	putMessage(t, dbClient, reply("b", "root", "user-root", 3))
+	putMessage(t, dbClient, reply("a", "root", "user-root", 1))
+	putMessage(t, dbClient, reply("a1", "a", "user-a", 2))
+	putMessage(t, dbClient, reply("a1x", "a1", "user-a1", 4))
+}
+
+func replyIDs(node *model.ThreadNode) []string {
+	ids := []string{}
+	for _, reply := range node.Replies {
+		ids = append(ids, reply.Message.ID)
+	}
+	return ids
+}
+
+func TestCreateMessage_ReplyJoinsConversation(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+	ctx := context.Background()
+
+	root, err := service.CreateMessage(ctx, "alice", &model.CreateMessageRequest{Content: "root"})
+	require.NoError(t, err)
+	assert.Equal(t, root.ID, root.ConversationID)
+
+	reply, err := service.CreateMessage(ctx, "bob", &model.CreateMessageRequest{Content: "reply", InReplyTo: root.ID})
+	require.NoError(t, err)
+	nested, err := service.CreateMessage(ctx, "carol", &model.CreateMessageRequest{Content: "nested", InReplyTo: reply.ID})
+	require.NoError(t, err)
+
+	assert.Equal(t, root.ID, reply.InReplyTo)
+	assert.Equal(t, "alice", reply.InReplyToUserID)
+	assert.Equal(t, root.ID, reply.ConversationID)
+	assert.Equal(t, "bob", nested.InReplyToUserID)
+	assert.Equal(t, root.ID, nested.ConversationID)
+}
+
+func TestCreateMessage_ReplyTargetNotFound(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+
+	message, err := service.CreateMessage(context.Background(), "bob", &model.CreateMessageRequest{Content: "reply", InReplyTo: "missing"})
+
+	assert.ErrorIs(t, err, ErrReplyTargetNotFound)
+	assert.Nil(t, message)
+}
+
+func TestGetThread_OrdersRepliesByTime(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, dbClient := services.messages, services.db
+	seedThread(t, dbClient)
+
+	thread, err := service.GetThread(context.Background(), "root", 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, "root", thread.ConversationID)
+	assert.Empty(t, thread.Ancestors)
+	assert.Equal(t, []string{"a", "b"}, replyIDs(thread.Root))
+	assert.Equal(t, []string{"a1"}, replyIDs(thread.Root.Replies[0]))
+	assert.Equal(t, []string{"a1x"}, replyIDs(thread.Root.Replies[0].Replies[0]))
+	assert.False(t, thread.Truncated)
+}
+
+func TestGetThread_DepthLimit(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, dbClient := services.messages, services.db
+	seedThread(t, dbClient)
+
+	thread, err := service.GetThread(context.Background(), "root", 1)
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, replyIDs(thread.Root))
+	assert.Empty(t, thread.Root.Replies[0].Replies)
+	assert.True(t, thread.Root.Replies[0].MoreReplies)
+	assert.False(t, thread.Root.Replies[1].MoreReplies)
+}
+
+func TestGetThread_FromReplyIncludesAncestors(t *testing.T) {
+	services := newTestServices(testOptions{})
+	service, dbClient := services.messages, services.db
+	seedThread(t, dbClient)
+
+	thread, err := service.GetThread(context.Background(), "a1", 10)
+
+	require.NoError(t, err)
+	require.Len(t, thread.Ancestors, 2)
+	assert.Equal(t, "root", thread.Ancestors[0].ID)
+	assert.Equal(t, "a", thread.Ancestors[1].ID)
+	assert.Equal(t, "a1", thread.Root.Message.ID)
+	assert.Equal(t, []string{"a1x"}, replyIDs(thread.Root))
+}
+
+func TestGetThread_NotFound(t *testing.T) {
+	service := newTestServices(testOptions{}).messages
+
+	thread, err := service.GetThread(context.Background(), "missing", 10)
+
+	assert.ErrorIs(t, err, ErrMessageNotFound)
+	assert.Nil(t, thread)
+}
@@ -2,10 +2,16 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
+	"time"
 
+	"mensajesService/components/config"
 	"mensajesService/components/database"
 	"mensajesService/components/logger"
+	"mensajesService/components/metrics"
+	"mensajesService/components/pagination"
 	"mensajesService/message-api/model"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -15,19 +21,130 @@ import (
 )
 
 type TimelineServiceInterface interface {
-	GetUserTimeline(ctx context.Context, userID string, limit int) ([]*model.TimelineItem, error)
+	GetUserTimeline(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.TimelineItem], error)
 	UpdateFollowersTimeline(ctx context.Context, message *model.Message) error
+	RemoveFromFollowersTimeline(ctx context.Context, message *model.Message) error
+	BackfillTimeline(ctx context.Context, userID string, messages []*model.Message) error
+	RemoveAuthorFromTimeline(ctx context.Context, userID, authorID string) error
+	GetMentions(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.TimelineItem], error)
+	EmbedAuthors(ctx context.Context, items []*model.TimelineItem) error
 }
 
 type TimelineService struct {
-	dbClient database.DDBClientInterface
+	dbClient     database.DDBClientInterface
+	cursors      *pagination.CursorCodec
+	heavyAuthors *HeavyAuthors
+	replyFanout  string
 }
 
-func NewTimelineService(dbClient database.DDBClientInterface) *TimelineService {
-	return &TimelineService{dbClient: dbClient}
+// NewTimelineService builds the service. replyFanout is one of the
+// config.ReplyFanout* policies and decides which timelines receive replies.
+func NewTimelineService(dbClient database.DDBClientInterface, cursors *pagination.CursorCodec, heavyAuthors *HeavyAuthors, replyFanout string) *TimelineService {
+	return &TimelineService{
+		dbClient:     dbClient,
+		cursors:      cursors,
+		heavyAuthors: heavyAuthors,
+		replyFanout:  replyFanout,
+	}
+}
+
+// timelineCursor is the last item served. Every source (the stored timeline
+// and each heavy author's messages) resumes strictly after it, so pages stay
+// consistent however the sources interleave.
+type timelineCursor struct {
+	CreatedAt time.Time `json:"created_at"`
+	MessageID string    `json:"message_id"`
+}
+
+// follows reports whether an item comes after the cursor in newest-first order.
+func (c *timelineCursor) follows(createdAt time.Time, messageID string) bool {
+	if c == nil {
+		return true
+	}
+	if createdAt.Equal(c.CreatedAt) {
+		return messageID < c.MessageID
+	}
+	return createdAt.Before(c.CreatedAt)
 }
 
-func (s *TimelineService) GetUserTimeline(ctx context.Context, userID string, limit int) ([]*model.TimelineItem, error) {
+// GetUserTimeline merges the stored timeline with the recent messages of the
+// heavy authors the user follows, newest first.
+func (s *TimelineService) GetUserTimeline(ctx context.Context, userID string, page model.PageRequest) (*model.Page[*model.TimelineItem], error) {
+	scope := "timeline:" + userID
+	var cursor *timelineCursor
+	if page.Cursor != "" {
+		cursor = &timelineCursor{}
+		if err := s.cursors.Decode(scope, page.Cursor, cursor); err != nil {
+			return nil, err
+		}
+	}
+
+	timelineItems, more, err := s.storedTimeline(ctx, userID, cursor, page.Limit)
+	if err != nil {
+		logger.LogError("Error getting user timeline", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	heavyItems, heavyMore, err := s.heavyAuthorsTimeline(ctx, userID, cursor, page.Limit)
+	if err != nil {
+		logger.LogError("Error getting heavy authors timeline", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	timelineItems = mergeTimelineItems(timelineItems, heavyItems)
+	more = more || heavyMore || len(timelineItems) > page.Limit
+	if len(timelineItems) > page.Limit {
+		timelineItems = timelineItems[:page.Limit]
+	}
+
+	// The cursor points past muted items too, so a page can come up short.
+	var last *model.TimelineItem
+	if len(timelineItems) > 0 {
+		last = timelineItems[len(timelineItems)-1]
+	}
+	timelineItems, err = s.withoutMuted(ctx, userID, timelineItems)
+	if err != nil {
+		logger.LogError("Error filtering muted users", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	if err := s.decorateTimelineItems(ctx, userID, timelineItems); err != nil {
+		logger.LogError("Error decorating timeline items", "error", err, "user_id", userID)
+		return nil, err
+	}
+
+	nextCursor := ""
+	if more && last != nil {
+		nextCursor, err = s.cursors.Encode(scope, timelineCursor{CreatedAt: last.CreatedAt, MessageID: last.MessageID})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	logger.LogInfo("Timeline retrieved successfully", "user_id", userID, "items_count", len(timelineItems), "heavy_items_count", len(heavyItems))
+	return model.NewPage(timelineItems, nextCursor), nil
+}
+
+// withoutMuted drops the items whose author userID mutes.
+func (s *TimelineService) withoutMuted(ctx context.Context, userID string, items []*model.TimelineItem) ([]*model.TimelineItem, error) {
+	if len(items) == 0 {
+		return items, nil
+	}
+	muted, err := mutedUsers(ctx, s.dbClient, userID)
+	if err != nil || len(muted) == 0 {
+		return items, err
+	}
+
+	visible := items[:0]
+	for _, item := range items {
+		if !muted[item.AuthorID] {
+			visible = append(visible, item)
+		}
+	}
+	return visible, nil
+}
+
+func (s *TimelineService) storedTimeline(ctx context.Context, userID string, cursor *timelineCursor, limit int) ([]*model.TimelineItem, bool, error) {
 	input := &dynamodb.QueryInput{
 		TableName:              aws.String(s.dbClient.GetTimelineTableName()),
 		KeyConditionExpression: aws.String("user_id = :user_id"),
@@ -35,93 +152,481 @@ func (s *TimelineService) GetUserTimeline(ctx context.Context, userID string, li
 			":user_id": &types.AttributeValueMemberS{Value: userID},
 		},
 		ScanIndexForward: aws.Bool(false),
-		Limit:            aws.Int32(int32(limit)),
+	}
+	if cursor != nil {
+		input.KeyConditionExpression = aws.String("user_id = :user_id AND #timestamp <= :timestamp")
+		input.ExpressionAttributeNames = map[string]string{"#timestamp": "timestamp"}
+		input.ExpressionAttributeValues[":timestamp"] = timelineTimestamp(cursor.CreatedAt)
 	}
 
-	result, err := s.dbClient.Query(ctx, input)
+	return queryAfterCursor(ctx, s.dbClient, input, cursor, limit, func(item *model.TimelineItem) (time.Time, string) {
+		return item.CreatedAt, item.MessageID
+	})
+}
+
+// heavyAuthorsTimeline reads, from the messages table, what fan-out-on-write
+// skipped: the messages of heavy authors the user follows.
+func (s *TimelineService) heavyAuthorsTimeline(ctx context.Context, userID string, cursor *timelineCursor, limit int) ([]*model.TimelineItem, bool, error) {
+	authors, err := s.followedHeavyAuthors(ctx, userID)
 	if err != nil {
-		logger.LogError("Error getting user timeline", "error", err, "user_id", userID)
-		return nil, err
+		return nil, false, err
 	}
 
 	var timelineItems []*model.TimelineItem
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &timelineItems)
+	more := false
+	for _, authorID := range authors {
+		input := &dynamodb.QueryInput{
+			TableName:              aws.String(s.dbClient.GetMessagesTableName()),
+			KeyConditionExpression: aws.String("user_id = :user_id"),
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":user_id": &types.AttributeValueMemberS{Value: authorID},
+			},
+			ScanIndexForward: aws.Bool(false),
+		}
+		if cursor != nil {
+			// The bound is coarse; queryAfterCursor drops what precedes the cursor.
+			input.KeyConditionExpression = aws.String("user_id = :user_id AND created_at < :before")
+			input.ExpressionAttributeValues[":before"] = createdAtUpperBound(cursor.CreatedAt)
+		}
+
+		messages, authorMore, err := queryAfterCursor(ctx, s.dbClient, input, cursor, limit, func(message *model.Message) (time.Time, string) {
+			return message.CreatedAt, message.ID
+		})
+		if err != nil {
+			return nil, false, err
+		}
+		more = more || authorMore
+
+		messages, err = s.visibleReplies(ctx, userID, messages)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, message := range messages {
+			timelineItems = append(timelineItems, model.NewTimelineItem(userID, message))
+		}
+	}
+
+	return timelineItems, more, nil
+}
+
+// followedHeavyAuthors returns the heavy authors userID follows, checking
+// the follows with one batch read.
+func (s *TimelineService) followedHeavyAuthors(ctx context.Context, userID string) ([]string, error) {
+	authors, err := s.heavyAuthors.Cached(ctx)
+	if err != nil || len(authors) == 0 {
+		return nil, err
+	}
+
+	keys := make([]map[string]types.AttributeValue, len(authors))
+	for i, authorID := range authors {
+		keys[i] = followKey(userID, authorID)
+	}
+	follows, err := batchGetFollows(ctx, s.dbClient, keys)
 	if err != nil {
 		return nil, err
 	}
 
-	logger.LogInfo("Timeline retrieved successfully", "user_id", userID, "items_count", len(timelineItems))
-	return timelineItems, nil
+	followed := make([]string, len(follows))
+	for i, follow := range follows {
+		followed[i] = follow.FollowingID
+	}
+	return followed, nil
 }
 
-func (s *TimelineService) UpdateFollowersTimeline(ctx context.Context, message *model.Message) error {
-	followers, err := s.getFollowers(ctx, message.UserID)
+// decorateTimelineItems fills in what timeline rows don't store: each
+// message's current like count, whether the reader liked it, and the message
+// a repost or quote post refers to. A deleted original is left out, so
+// clients can show it as unavailable. The page is read in batches: its
+// messages, the reader's likes of them, the originals, and whether the reader
+// may see the originals' authors.
+func (s *TimelineService) decorateTimelineItems(ctx context.Context, userID string, items []*model.TimelineItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	keys := make([]map[string]types.AttributeValue, len(items))
+	messageIDs := make([]string, len(items))
+	for i, item := range items {
+		key, err := messageKey(&model.Message{UserID: item.AuthorID, CreatedAt: item.CreatedAt})
+		if err != nil {
+			return err
+		}
+		keys[i] = key
+		messageIDs[i] = item.MessageID
+	}
+	messages, err := batchGetMessages(ctx, s.dbClient, keys)
+	if err != nil {
+		return err
+	}
+	liked, err := likedMessages(ctx, s.dbClient, userID, messageIDs)
+	if err != nil {
+		return err
+	}
+
+	originals, err := s.timelineOriginals(ctx, items, messages)
+	if err != nil {
+		return err
+	}
+	authorIDs := make([]string, 0, len(originals))
+	for _, original := range originals {
+		authorIDs = append(authorIDs, original.UserID)
+	}
+	// A private original stays out of timelines of users who don't follow
+	// its author, e.g. after they were unfollowed.
+	visible, err := viewableAuthors(ctx, s.dbClient, userID, authorIDs)
 	if err != nil {
-		logger.LogError("Error getting followers", "error", err, "user_id", message.UserID)
 		return err
 	}
 
-	for _, followerID := range followers {
-		timelineItem := &model.TimelineItem{
-			MessageID: message.ID,
-			UserID:    followerID,
-			AuthorID:  message.UserID,
-			Content:   message.Content,
-			CreatedAt: message.CreatedAt,
+	for _, item := range items {
+		if message, ok := messages[item.MessageID]; ok {
+			item.LikeCount = message.LikeCount
 		}
+		likedByMe := liked[item.MessageID]
+		item.LikedByMe = &likedByMe
 
-		if err := s.saveTimelineItem(ctx, timelineItem); err != nil {
-			logger.LogError("Error saving timeline item", "error", err, "message_id", message.ID, "follower_id", followerID)
+		if original, ok := originals[timelineOriginalID(item)]; ok && visible[original.UserID] {
+			item.Original = original
+		}
+	}
+	return nil
+}
+
+// timelineOriginals reads the messages the items repost or quote, indexed by
+// ID. Originals are read in a batch through the key stored on the repost;
+// those of reposts stored before the key was recorded are looked up by ID.
+// Deleted originals are left out.
+func (s *TimelineService) timelineOriginals(ctx context.Context, items []*model.TimelineItem, messages map[string]*model.Message) (map[string]*model.Message, error) {
+	var keys []map[string]types.AttributeValue
+	unkeyed := make(map[string]bool)
+	for _, item := range items {
+		originalID := timelineOriginalID(item)
+		if originalID == "" {
 			continue
 		}
+		var key map[string]types.AttributeValue
+		if message, ok := messages[item.MessageID]; ok {
+			var err error
+			if key, err = originalKey(message); err != nil {
+				return nil, err
+			}
+		}
+		if key != nil {
+			keys = append(keys, key)
+		} else {
+			unkeyed[originalID] = true
+		}
 	}
 
-	return nil
+	originals, err := batchGetMessages(ctx, s.dbClient, keys)
+	if err != nil {
+		return nil, err
+	}
+	for originalID := range unkeyed {
+		if _, ok := originals[originalID]; ok {
+			continue
+		}
+		original, err := getMessage(ctx, s.dbClient, originalID)
+		if errors.Is(err, ErrMessageNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		originals[originalID] = original
+	}
+	return originals, nil
 }
 
-func (s *TimelineService) saveTimelineItem(ctx context.Context, item *model.TimelineItem) error {
-	timelineItem, err := attributevalue.MarshalMap(item)
+func timelineOriginalID(item *model.TimelineItem) string {
+	if item.RepostOf != "" {
+		return item.RepostOf
+	}
+	return item.QuoteOf
+}
+
+// queryAfterCursor pages through input until it has limit items that come
+// after the cursor, and reports whether the source may hold more.
+func queryAfterCursor[T any](ctx context.Context, dbClient database.DDBClientInterface, input *dynamodb.QueryInput, cursor *timelineCursor, limit int, position func(T) (time.Time, string)) ([]T, bool, error) {
+	input.Limit = aws.Int32(int32(limit + 1))
+
+	var items []T
+	for {
+		result, err := dbClient.Query(ctx, input)
+		if err != nil {
+			return nil, false, err
+		}
+
+		var page []T
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return nil, false, err
+		}
+		for _, item := range page {
+			if cursor.follows(position(item)) {
+				items = append(items, item)
+			}
+		}
+
+		if len(items) > limit {
+			return items[:limit], true, nil
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return items, false, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// mergeTimelineItems sorts newest first and drops duplicates: messages an
+// author posted before becoming heavy are both stored and read back.
+func mergeTimelineItems(stored, heavy []*model.TimelineItem) []*model.TimelineItem {
+	if len(heavy) == 0 {
+		return stored
+	}
+
+	seen := make(map[string]bool, len(stored)+len(heavy))
+	merged := make([]*model.TimelineItem, 0, len(stored)+len(heavy))
+	for _, item := range append(stored, heavy...) {
+		if seen[item.MessageID] {
+			continue
+		}
+		seen[item.MessageID] = true
+		merged = append(merged, item)
+	}
+
+	sort.Slice(merged, func(i, j int) bool {
+		if merged[i].CreatedAt.Equal(merged[j].CreatedAt) {
+			return merged[i].MessageID > merged[j].MessageID
+		}
+		return merged[i].CreatedAt.After(merged[j].CreatedAt)
+	})
+	return merged
+}
+
+// UpdateFollowersTimeline copies the message into every follower's timeline
+// with batched writes, so posting latency doesn't grow one round trip per follower.
+// Replies go to the audiences chosen by the reply fan-out policy. Users who
+// block the author, or are blocked by them, are skipped.
+func (s *TimelineService) UpdateFollowersTimeline(ctx context.Context, message *model.Message) error {
+	sources, err := s.fanoutSources(ctx, message)
+	if err != nil {
+		return err
+	}
+	blocked, err := blockedUsers(ctx, s.dbClient, message.UserID)
 	if err != nil {
 		return err
 	}
 
-	timelineItem["user_id"] = &types.AttributeValueMemberS{Value: item.UserID}
-	timelineItem["timestamp"] = &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", item.CreatedAt.Unix())}
+	// Writes are idempotent, so a fan-out that fails part way can be retried whole.
+	processed := 0
+	for _, sourceID := range sources {
+		sourceProcessed, err := forEachFollowerChunk(ctx, s.dbClient, sourceID, func(followers []string) error {
+			audience, err := s.replyAudience(ctx, message, followers)
+			if err != nil {
+				return err
+			}
+			requests := make([]types.WriteRequest, 0, len(followers))
+			for _, followerID := range followers {
+				if blocked[followerID] || (audience != nil && !audience[followerID]) {
+					continue
+				}
+				timelineItem, err := timelineItemAttributes(model.NewTimelineItem(followerID, message))
+				if err != nil {
+					return err
+				}
+				requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: timelineItem}})
+			}
+			return s.dbClient.BatchWriteItem(ctx, s.dbClient.GetTimelineTableName(), requests)
+		})
+		processed += sourceProcessed
+		if err != nil {
+			metrics.PutCountMetric(metrics.MetricFanoutFollowersCount, float64(processed))
+			logger.LogError("Error saving timeline items", "error", err, "message_id", message.ID, "followers_processed", processed)
+			return err
+		}
+	}
+	metrics.PutCountMetric(metrics.MetricFanoutFollowersCount, float64(processed))
 
-	err = s.dbClient.PutItem(ctx, s.dbClient.GetTimelineTableName(), timelineItem)
-	if err != nil {
-		logger.LogError("Error saving timeline item", "error", err, "message_id", item.MessageID, "user_id", item.UserID)
+	logger.LogInfo("Message fanned out", "message_id", message.ID, "followers_count", processed)
+	return nil
+}
+
+// RemoveFromFollowersTimeline retracts a deleted message from every timeline
+// it was fanned out to.
+func (s *TimelineService) RemoveFromFollowersTimeline(ctx context.Context, message *model.Message) error {
+	// Heavy authors are included: their messages may have been stored before
+	// they became heavy. Deletes of rows that were never written are no-ops.
+	sources := []string{message.UserID}
+	if s.replyFanout == config.ReplyFanoutAll && message.InReplyToUserID != "" && message.InReplyToUserID != message.UserID {
+		sources = append(sources, message.InReplyToUserID)
+	}
+
+	failed, processed := 0, 0
+	for _, sourceID := range sources {
+		sourceProcessed, err := forEachFollowerChunk(ctx, s.dbClient, sourceID, func(followers []string) error {
+			for _, followerID := range followers {
+				if err := s.deleteTimelineItem(ctx, followerID, message); err != nil {
+					logger.LogError("Error deleting timeline item", "error", err, "message_id", message.ID, "follower_id", followerID)
+					failed++
+				}
+			}
+			return nil
+		})
+		processed += sourceProcessed
+		if err != nil {
+			logger.LogError("Error getting followers", "error", err, "user_id", sourceID)
+			return err
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("retraction of message %s failed for %d of %d followers", message.ID, failed, processed)
+	}
+	logger.LogInfo("Message retracted from timelines", "message_id", message.ID, "followers_count", processed)
+	return nil
+}
+
+// BackfillTimeline copies messages into a single user's timeline, e.g. an
+// author's history for a new follower. Messages deleted while they were being
+// copied are removed again, since their retraction may have run first.
+func (s *TimelineService) BackfillTimeline(ctx context.Context, userID string, messages []*model.Message) error {
+	// Messages posted in the same second share a timeline key, and a batch
+	// with duplicate keys is rejected whole. Keep the newest of each, which is
+	// also the one that wins when fan-out writes them one at a time.
+	slots := make(map[int64]*model.Message, len(messages))
+	for _, message := range messages {
+		slot := message.CreatedAt.Unix()
+		if kept, ok := slots[slot]; !ok || !message.CreatedAt.Before(kept.CreatedAt) {
+			slots[slot] = message
+		}
+	}
+
+	requests := make([]types.WriteRequest, 0, len(slots))
+	keys := make([]map[string]types.AttributeValue, 0, len(slots))
+	for _, message := range messages {
+		if slots[message.CreatedAt.Unix()] != message {
+			continue
+		}
+		timelineItem, err := timelineItemAttributes(model.NewTimelineItem(userID, message))
+		if err != nil {
+			return err
+		}
+		requests = append(requests, types.WriteRequest{PutRequest: &types.PutRequest{Item: timelineItem}})
+		key, err := messageKey(message)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	if err := s.dbClient.BatchWriteItem(ctx, s.dbClient.GetTimelineTableName(), requests); err != nil {
+		logger.LogError("Error backfilling timeline", "error", err, "user_id", userID)
 		return err
 	}
 
+	// Messages deleted while the batch was written are taken back out, with
+	// one batch read of what was written.
+	existing, err := batchGetMessages(ctx, s.dbClient, keys)
+	if err != nil {
+		return err
+	}
+	for _, message := range slots {
+		if _, ok := existing[message.ID]; ok {
+			continue
+		}
+		if err := s.deleteTimelineItem(ctx, userID, message); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
-func (s *TimelineService) getFollowers(ctx context.Context, userID string) ([]string, error) {
+// RemoveAuthorFromTimeline purges every item written by authorID from the
+// user's timeline, e.g. after an unfollow.
+func (s *TimelineService) RemoveAuthorFromTimeline(ctx context.Context, userID, authorID string) error {
 	input := &dynamodb.QueryInput{
-		TableName:              aws.String(s.dbClient.GetFollowersTableName()),
-		IndexName:              aws.String("FollowingIndex"),
-		KeyConditionExpression: aws.String("following_id = :following_id"),
+		TableName:              aws.String(s.dbClient.GetTimelineTableName()),
+		KeyConditionExpression: aws.String("user_id = :user_id"),
+		FilterExpression:       aws.String("author_id = :author_id"),
 		ExpressionAttributeValues: map[string]types.AttributeValue{
-			":following_id": &types.AttributeValueMemberS{Value: userID},
+			":user_id":   &types.AttributeValueMemberS{Value: userID},
+			":author_id": &types.AttributeValueMemberS{Value: authorID},
 		},
 	}
 
-	result, err := s.dbClient.Query(ctx, input)
-	if err != nil {
-		return nil, err
+	removed, failed := 0, 0
+	for {
+		result, err := s.dbClient.Query(ctx, input)
+		if err != nil {
+			logger.LogError("Error querying timeline for purge", "error", err, "user_id", userID, "author_id", authorID)
+			return err
+		}
+
+		for _, item := range result.Items {
+			_, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+				TableName: aws.String(s.dbClient.GetTimelineTableName()),
+				Key: map[string]types.AttributeValue{
+					"user_id":   item["user_id"],
+					"timestamp": item["timestamp"],
+				},
+				ConditionExpression: aws.String("author_id = :author_id"),
+				ExpressionAttributeValues: map[string]types.AttributeValue{
+					":author_id": &types.AttributeValueMemberS{Value: authorID},
+				},
+			})
+			var conditionFailed *types.ConditionalCheckFailedException
+			if err != nil && !errors.As(err, &conditionFailed) {
+				logger.LogError("Error deleting timeline item", "error", err, "user_id", userID, "author_id", authorID)
+				failed++
+				continue
+			}
+			removed++
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			break
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("purge of author %s from timeline %s failed for %d items", authorID, userID, failed)
+	}
+	logger.LogInfo("Author removed from timeline", "user_id", userID, "author_id", authorID, "items_count", removed)
+	return nil
+}
+
+func (s *TimelineService) deleteTimelineItem(ctx context.Context, userID string, message *model.Message) error {
+	_, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName: aws.String(s.dbClient.GetTimelineTableName()),
+		Key: map[string]types.AttributeValue{
+			"user_id":   &types.AttributeValueMemberS{Value: userID},
+			"timestamp": timelineTimestamp(message.CreatedAt),
+		},
+		// Another message may share the same timestamp slot; only remove our own row.
+		ConditionExpression: aws.String("message_id = :message_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":message_id": &types.AttributeValueMemberS{Value: message.ID},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return nil
 	}
+	return err
+}
 
-	var follows []*model.Follow
-	err = attributevalue.UnmarshalListOfMaps(result.Items, &follows)
+func timelineItemAttributes(item *model.TimelineItem) (map[string]types.AttributeValue, error) {
+	timelineItem, err := attributevalue.MarshalMap(item)
 	if err != nil {
 		return nil, err
 	}
 
-	var followers []string
-	for _, follow := range follows {
-		followers = append(followers, follow.FollowerID)
-	}
+	timelineItem["user_id"] = &types.AttributeValueMemberS{Value: item.UserID}
+	timelineItem["timestamp"] = timelineTimestamp(item.CreatedAt)
+	return timelineItem, nil
+}
 
-	return followers, nil
+func timelineTimestamp(createdAt time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberN{Value: fmt.Sprintf("%d", createdAt.Unix())}
 }
@@ -0,0 +1,282 @@
+package service
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"mensajesService/components/background"
+	"mensajesService/components/database"
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// trendHalfLifeDivisor sets how fast counts decay: a message weighs half as
+// much after a quarter of the window.
+const trendHalfLifeDivisor = 4
+
+// trendRecentBuckets is how many of the newest buckets a refresh reads again,
+// to pick up counts recorded late by retried jobs. Older buckets are cached.
+const trendRecentBuckets = 2
+
+type TrendsServiceInterface interface {
+	GetTrends(window time.Duration, limit int) (*model.Trends, error)
+	Windows() []time.Duration
+}
+
+type TrendOptions struct {
+	Windows         []time.Duration
+	Bucket          time.Duration
+	RefreshInterval time.Duration
+	TopN            int
+}
+
+// TrendsService ranks hashtags over sliding windows. New messages add to
+// per-bucket counts in storage, which every instance reads back on its own
+// schedule, so all instances serve the same trends without coordinating.
+type TrendsService struct {
+	dbClient database.DDBClientInterface
+	opts     TrendOptions
+
+	// buckets is only touched by Refresh, which the scheduler never runs
+	// concurrently with itself.
+	buckets map[time.Time]map[string]int64
+
+	mu        sync.RWMutex
+	snapshots map[time.Duration]*model.Trends
+}
+
+type hashtagCount struct {
+	Tag   string `dynamodbav:"tag"`
+	Count int64  `dynamodbav:"count"`
+}
+
+func NewTrendsService(dbClient database.DDBClientInterface, opts TrendOptions) *TrendsService {
+	if opts.Bucket <= 0 {
+		opts.Bucket = 5 * time.Minute
+	}
+	return &TrendsService{
+		dbClient:  dbClient,
+		opts:      opts,
+		buckets:   map[time.Time]map[string]int64{},
+		snapshots: map[time.Duration]*model.Trends{},
+	}
+}
+
+// RegisterTasks schedules the periodic refresh.
+func (s *TrendsService) RegisterTasks(scheduler *background.Scheduler) {
+	scheduler.Every("refresh_trends", s.opts.RefreshInterval, s.Refresh)
+}
+
+// Windows lists the configured windows; the first one is the default.
+func (s *TrendsService) Windows() []time.Duration {
+	return s.opts.Windows
+}
+
+// GetTrends returns up to limit hashtags of the latest refresh of window.
+func (s *TrendsService) GetTrends(window time.Duration, limit int) (*model.Trends, error) {
+	if !s.hasWindow(window) {
+		return nil, ErrUnknownTrendWindow
+	}
+
+	s.mu.RLock()
+	snapshot := s.snapshots[window]
+	s.mu.RUnlock()
+
+	trends := &model.Trends{Window: FormatTrendWindow(window), Items: []*model.Trend{}}
+	if snapshot != nil {
+		trends.UpdatedAt = snapshot.UpdatedAt
+		trends.Items = snapshot.Items
+	}
+	if limit > 0 && len(trends.Items) > limit {
+		trends.Items = trends.Items[:limit]
+	}
+	return trends, nil
+}
+
+// Refresh reads the bucket counts covering the longest window and ranks each
+// window's hashtags.
+func (s *TrendsService) Refresh(ctx context.Context) error {
+	now := time.Now()
+	longest := time.Duration(0)
+	for _, window := range s.opts.Windows {
+		if window > longest {
+			longest = window
+		}
+	}
+
+	first := s.bucketStart(now.Add(-longest))
+	current := s.bucketStart(now)
+	for start := range s.buckets {
+		if start.Before(first) {
+			delete(s.buckets, start)
+		}
+	}
+	recent := current.Add(-time.Duration(trendRecentBuckets-1) * s.opts.Bucket)
+	for start := first; !start.After(current); start = start.Add(s.opts.Bucket) {
+		if _, cached := s.buckets[start]; cached && start.Before(recent) {
+			continue
+		}
+		counts, err := s.readBucket(ctx, start)
+		if err != nil {
+			return err
+		}
+		s.buckets[start] = counts
+	}
+
+	snapshots := make(map[time.Duration]*model.Trends, len(s.opts.Windows))
+	for _, window := range s.opts.Windows {
+		snapshots[window] = s.rank(window, now)
+	}
+
+	s.mu.Lock()
+	s.snapshots = snapshots
+	s.mu.Unlock()
+	logger.LogInfo("Trends refreshed", "buckets", len(s.buckets))
+	return nil
+}
+
+// rank scores each hashtag seen in window, weighing each bucket by its age
+// measured from its midpoint.
+func (s *TrendsService) rank(window time.Duration, now time.Time) *model.Trends {
+	halfLife := window / trendHalfLifeDivisor
+	trends := map[string]*model.Trend{}
+	for start, counts := range s.buckets {
+		if !start.Add(s.opts.Bucket).After(now.Add(-window)) {
+			continue
+		}
+		age := now.Sub(start.Add(s.opts.Bucket / 2))
+		if age < 0 {
+			age = 0
+		}
+		weight := math.Exp2(-float64(age) / float64(halfLife))
+		for tag, count := range counts {
+			trend, ok := trends[tag]
+			if !ok {
+				trend = &model.Trend{Tag: tag}
+				trends[tag] = trend
+			}
+			trend.Count += count
+			trend.Score += float64(count) * weight
+		}
+	}
+
+	items := make([]*model.Trend, 0, len(trends))
+	for _, trend := range trends {
+		items = append(items, trend)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Score != items[j].Score {
+			return items[i].Score > items[j].Score
+		}
+		return items[i].Tag < items[j].Tag
+	})
+	if s.opts.TopN > 0 && len(items) > s.opts.TopN {
+		items = items[:s.opts.TopN]
+	}
+	return &model.Trends{Window: FormatTrendWindow(window), UpdatedAt: now, Items: items}
+}
+
+// recordHashtags counts a new message towards its hashtags' trends. Buckets
+// expire, through the table's TTL on expires_at, once no window covers them.
+// It is not idempotent: a job that runs twice counts the message twice. Trends
+// are a ranking and tolerate that; recounting the tag's rows instead would
+// cost a read of every message with the tag in the bucket.
+func (s *TrendsService) recordHashtags(ctx context.Context, message *model.Message) error {
+	start := s.bucketStart(message.CreatedAt)
+	longest := s.opts.Bucket
+	for _, window := range s.opts.Windows {
+		if window > longest {
+			longest = window
+		}
+	}
+	expiresAt := start.Add(longest + s.opts.Bucket).Unix()
+
+	for _, tag := range message.Entities.HashtagKeys() {
+		_, err := s.dbClient.UpdateItem(ctx, &dynamodb.UpdateItemInput{
+			TableName: aws.String(s.dbClient.GetHashtagCountsTableName()),
+			Key: map[string]types.AttributeValue{
+				"bucket": bucketKey(start),
+				"tag":    &types.AttributeValueMemberS{Value: tag},
+			},
+			UpdateExpression:         aws.String("ADD #count :one SET expires_at = :expires_at"),
+			ExpressionAttributeNames: map[string]string{"#count": "count"},
+			ExpressionAttributeValues: map[string]types.AttributeValue{
+				":one":        &types.AttributeValueMemberN{Value: "1"},
+				":expires_at": &types.AttributeValueMemberN{Value: strconv.FormatInt(expiresAt, 10)},
+			},
+		})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *TrendsService) readBucket(ctx context.Context, start time.Time) (map[string]int64, error) {
+	counts := map[string]int64{}
+	input := &dynamodb.QueryInput{
+		TableName:                aws.String(s.dbClient.GetHashtagCountsTableName()),
+		KeyConditionExpression:   aws.String("#bucket = :bucket"),
+		ExpressionAttributeNames: map[string]string{"#bucket": "bucket"},
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":bucket": bucketKey(start),
+		},
+	}
+	for {
+		result, err := s.dbClient.Query(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		var items []hashtagCount
+		if err := attributevalue.UnmarshalListOfMaps(result.Items, &items); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			counts[item.Tag] = item.Count
+		}
+		if len(result.LastEvaluatedKey) == 0 {
+			return counts, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+func (s *TrendsService) bucketStart(t time.Time) time.Time {
+	return t.UTC().Truncate(s.opts.Bucket)
+}
+
+func (s *TrendsService) hasWindow(window time.Duration) bool {
+	for _, configured := range s.opts.Windows {
+		if configured == window {
+			return true
+		}
+	}
+	return false
+}
+
+func bucketKey(start time.Time) types.AttributeValue {
+	return &types.AttributeValueMemberS{Value: start.Format(time.RFC3339)}
+}
+
+// FormatTrendWindow writes a window the way it is configured and requested,
+// "1h" rather than "1h0m0s".
+func FormatTrendWindow(window time.Duration) string {
+	formatted := window.String()
+	if strings.HasSuffix(formatted, "m0s") {
+		formatted = strings.TrimSuffix(formatted, "0s")
+	}
+	if strings.HasSuffix(formatted, "h0m") {
+		formatted = strings.TrimSuffix(formatted, "0m")
+	}
+	return formatted
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mensajesService/message-api/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func recordTags(t *testing.T, service *TrendsService, content string, age time.Duration, times int) {
+	message := &model.Message{ID: "msg", Content: content, CreatedAt: time.Now().Add(-age), Entities: parseEntities(content)}
+	for i := 0; i < times; i++ {
+		require.NoError(t, service.recordHashtags(context.Background(), message))
+	}
+}
+
+func trendTags(trends *model.Trends) []string {
+	var tags []string
+	for _, trend := range trends.Items {
+		tags = append(tags, trend.Tag)
+	}
+	return tags
+}
+
+func TestTrends_RanksEachWindowWithDecay(t *testing.T) {
+	service := newTestServices(testOptions{}).trends
+	recordTags(t, service, "#Go", 0, 3)
+	recordTags(t, service, "#rust", 0, 1)
+	recordTags(t, service, "#yesterday", 20*time.Hour, 5)
+	recordTags(t, service, "#ancient", 30*time.Hour, 9)
+
+	require.NoError(t, service.Refresh(context.Background()))
+
+	hour, err := service.GetTrends(time.Hour, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "1h", hour.Window)
+	assert.False(t, hour.UpdatedAt.IsZero())
+	assert.Equal(t, []string{"go", "rust"}, trendTags(hour))
+	assert.Equal(t, int64(3), hour.Items[0].Count)
+
+	// #yesterday has the most messages in the day, but they are old enough
+	// to rank below #go.
+	day, err := service.GetTrends(24*time.Hour, 0)
+	require.NoError(t, err)
+	assert.Equal(t, "24h", day.Window)
+	assert.Equal(t, []string{"go", "rust", "yesterday"}, trendTags(day))
+	assert.Equal(t, int64(5), day.Items[2].Count)
+	assert.Less(t, day.Items[2].Score, 1.0)
+
+	limited, err := service.GetTrends(24*time.Hour, 1)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"go"}, trendTags(limited))
+}
+
+func TestTrends_RefreshPicksUpRecentCounts(t *testing.T) {
+	service := newTestServices(testOptions{}).trends
+	recordTags(t, service, "#go", 0, 1)
+	require.NoError(t, service.Refresh(context.Background()))
+
+	recordTags(t, service, "#go", 0, 2)
+	require.NoError(t, service.Refresh(context.Background()))
+
+	trends, err := service.GetTrends(time.Hour, 0)
+	require.NoError(t, err)
+	require.Len(t, trends.Items, 1)
+	assert.Equal(t, int64(3), trends.Items[0].Count)
+}
+
+func TestTrends_BeforeFirstRefresh(t *testing.T) {
+	service := newTestServices(testOptions{}).trends
+
+	trends, err := service.GetTrends(time.Hour, 0)
+
+	require.NoError(t, err)
+	assert.Empty(t, trends.Items)
+	assert.True(t, trends.UpdatedAt.IsZero())
+}
+
+func TestTrends_UnknownWindow(t *testing.T) {
+	service := newTestServices(testOptions{}).trends
+
+	_, err := service.GetTrends(2*time.Hour, 0)
+
+	assert.ErrorIs(t, err, ErrUnknownTrendWindow)
+}
+
+func TestFormatTrendWindow(t *testing.T) {
+	assert.Equal(t, "1h", FormatTrendWindow(time.Hour))
+	assert.Equal(t, "15m", FormatTrendWindow(15*time.Minute))
+	assert.Equal(t, "1h30m", FormatTrendWindow(90*time.Minute))
+	assert.Equal(t, "30s", FormatTrendWindow(30*time.Second))
+}
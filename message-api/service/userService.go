@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"mensajesService/components/database"
+	"mensajesService/components/logger"
+	"mensajesService/message-api/model"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/dynamodb/attributevalue"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb"
+	"github.com/aws/aws-sdk-go-v2/service/dynamodb/types"
+)
+
+// maxHandleLength bounds handles. Handles are for display and lookup only:
+// mentions still name user IDs, so an @handle doesn't reach its holder.
+const maxHandleLength = 30
+
+type UserServiceInterface interface {
+	GetUser(ctx context.Context, userID string) (*model.User, error)
+	GetUserByHandle(ctx context.Context, handle string) (*model.User, error)
+	UpdateUser(ctx context.Context, userID string, request *model.UpdateUserRequest) (*model.User, bool, error)
+}
+
+type UserService struct {
+	dbClient database.DDBClientInterface
+}
+
+func NewUserService(dbClient database.DDBClientInterface) *UserService {
+	return &UserService{
+		dbClient: dbClient,
+	}
+}
+
+// handleRecord reserves a handle, lowercased, for the user that holds it.
+type handleRecord struct {
+	Handle string `dynamodbav:"handle"`
+	UserID string `dynamodbav:"user_id"`
+}
+
+// GetUser returns ErrUserNotFound for users who never created a profile.
+func (s *UserService) GetUser(ctx context.Context, userID string) (*model.User, error) {
+	return getUser(ctx, s.dbClient, userID)
+}
+
+// GetUserByHandle looks a profile up by handle, in any case and with or
+// without a leading @.
+func (s *UserService) GetUserByHandle(ctx context.Context, handle string) (*model.User, error) {
+	handle = strings.TrimPrefix(handle, "@")
+	if !isHandle(handle) {
+		return nil, ErrInvalidHandle
+	}
+
+	result, err := s.dbClient.GetItem(ctx, s.dbClient.GetHandlesTableName(), handleKey(handle))
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Item) == 0 {
+		return nil, ErrUserNotFound
+	}
+	var record handleRecord
+	if err := attributevalue.UnmarshalMap(result.Item, &record); err != nil {
+		return nil, err
+	}
+
+	user, err := s.GetUser(ctx, record.UserID)
+	if err != nil {
+		return nil, err
+	}
+	// The reservation outlives the profile's handle when an update stopped
+	// before releasing it.
+	if !strings.EqualFold(user.Handle, handle) {
+		return nil, ErrUserNotFound
+	}
+	return user, nil
+}
+
+// UpdateUser creates or changes userID's profile and reports whether it was
+// created. A new profile needs a handle. Handles are unique regardless of
+// case: a conditional write reserves the new one before the profile is saved,
+// and ErrHandleTaken is returned when another user holds it.
+func (s *UserService) UpdateUser(ctx context.Context, userID string, request *model.UpdateUserRequest) (*model.User, bool, error) {
+	existing, err := s.GetUser(ctx, userID)
+	if err != nil && !errors.Is(err, ErrUserNotFound) {
+		return nil, false, err
+	}
+	created := existing == nil
+
+	now := time.Now()
+	user := &model.User{UserID: userID, CreatedAt: now}
+	if !created {
+		copied := *existing
+		user = &copied
+	}
+	if request.Handle != nil {
+		user.Handle = strings.TrimPrefix(*request.Handle, "@")
+	}
+	if request.DisplayName != nil {
+		user.DisplayName = *request.DisplayName
+	}
+	if request.Bio != nil {
+		user.Bio = *request.Bio
+	}
+	if request.AvatarURL != nil {
+		user.AvatarURL = *request.AvatarURL
+	}
+	user.UpdatedAt = now
+
+	if user.Handle == "" {
+		return nil, false, ErrHandleRequired
+	}
+	if !isHandle(user.Handle) {
+		return nil, false, ErrInvalidHandle
+	}
+
+	handleChanged := created || !strings.EqualFold(user.Handle, existing.Handle)
+	if handleChanged {
+		if err := s.reserveHandle(ctx, user.Handle, userID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	item, err := attributevalue.MarshalMap(user)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := s.dbClient.PutItem(ctx, s.dbClient.GetUsersTableName(), item); err != nil {
+		if handleChanged {
+			s.releaseHandle(ctx, user.Handle, userID)
+		}
+		return nil, false, err
+	}
+
+	if handleChanged && !created {
+		s.releaseHandle(ctx, existing.Handle, userID)
+	}
+
+	logger.LogInfo("User profile saved", "user_id", userID, "handle", user.Handle, "created", created)
+	return user, created, nil
+}
+
+// reserveHandle claims handle for userID, unless another user holds it.
+// Claiming a handle userID already holds succeeds.
+func (s *UserService) reserveHandle(ctx context.Context, handle, userID string) error {
+	item, err := attributevalue.MarshalMap(handleRecord{Handle: strings.ToLower(handle), UserID: userID})
+	if err != nil {
+		return err
+	}
+
+	err = s.dbClient.PutItemWithCondition(ctx, &dynamodb.PutItemInput{
+		TableName:           aws.String(s.dbClient.GetHandlesTableName()),
+		Item:                item,
+		ConditionExpression: aws.String("attribute_not_exists(handle) OR user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if errors.As(err, &conditionFailed) {
+		return ErrHandleTaken
+	}
+	return err
+}
+
+// releaseHandle frees handle if userID still holds it. It is best effort: a
+// handle left reserved only keeps others from taking it, and GetUserByHandle
+// ignores it.
+func (s *UserService) releaseHandle(ctx context.Context, handle, userID string) {
+	_, err := s.dbClient.DeleteItem(ctx, &dynamodb.DeleteItemInput{
+		TableName:           aws.String(s.dbClient.GetHandlesTableName()),
+		Key:                 handleKey(handle),
+		ConditionExpression: aws.String("user_id = :user_id"),
+		ExpressionAttributeValues: map[string]types.AttributeValue{
+			":user_id": &types.AttributeValueMemberS{Value: userID},
+		},
+	})
+	var conditionFailed *types.ConditionalCheckFailedException
+	if err != nil && !errors.As(err, &conditionFailed) {
+		logger.LogError("Error releasing handle", "error", err, "user_id", userID, "handle", handle)
+	}
+}
+
+func getUser(ctx context.Context, dbClient database.DDBClientInterface, userID string) (*model.User, error) {
+	result, err := dbClient.GetItem(ctx, dbClient.GetUsersTableName(), map[string]types.AttributeValue{
+		"user_id": &types.AttributeValueMemberS{Value: userID},
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(result.Item) == 0 {
+		return nil, ErrUserNotFound
+	}
+
+	user := &model.User{}
+	if err := attributevalue.UnmarshalMap(result.Item, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// isHandle reports whether handle can be a handle: the characters allowed in
+// a username, not starting or ending with a hyphen.
+func isHandle(handle string) bool {
+	if handle == "" || len(handle) > maxHandleLength {
+		return false
+	}
+	for _, r := range handle {
+		if !isUsernameChar(r) {
+			return false
+		}
+	}
+	return handle[0] != '-' && handle[len(handle)-1] != '-'
+}
+
+func handleKey(handle string) map[string]types.AttributeValue {
+	return map[string]types.AttributeValue{
+		"handle": &types.AttributeValueMemberS{Value: strings.ToLower(handle)},
+	}
+}
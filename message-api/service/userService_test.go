@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mensajesService/message-api/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func saveProfile(t *testing.T, userService *UserService, userID, handle string) *model.User {
+	user, _, err := userService.UpdateUser(context.Background(), userID, &model.UpdateUserRequest{Handle: &handle})
+	require.NoError(t, err)
+	return user
+}
+
+func TestUpdateUser_CreatesAndUpdates(t *testing.T) {
+	userService := newTestServices(testOptions{}).users
+	ctx := context.Background()
+
+	_, _, err := userService.UpdateUser(ctx, "u1", &model.UpdateUserRequest{})
+	assert.ErrorIs(t, err, ErrHandleRequired)
+
+	handle, name := "@Alice", "Alice"
+	user, created, err := userService.UpdateUser(ctx, "u1", &model.UpdateUserRequest{Handle: &handle, DisplayName: &name})
+	require.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, "Alice", user.Handle)
+	assert.False(t, user.CreatedAt.IsZero())
+
+	bio := "hola"
+	updated, created, err := userService.UpdateUser(ctx, "u1", &model.UpdateUserRequest{Bio: &bio})
+	require.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, "Alice", updated.DisplayName)
+	assert.Equal(t, "hola", updated.Bio)
+	assert.True(t, updated.CreatedAt.Equal(user.CreatedAt))
+
+	stored, err := userService.GetUser(ctx, "u1")
+	require.NoError(t, err)
+	assert.Equal(t, "hola", stored.Bio)
+
+	_, err = userService.GetUser(ctx, "u2")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+}
+
+func TestUpdateUser_HandlesAreUnique(t *testing.T) {
+	userService := newTestServices(testOptions{}).users
+	ctx := context.Background()
+	saveProfile(t, userService, "u1", "alice")
+
+	taken := "ALICE"
+	_, _, err := userService.UpdateUser(ctx, "u2", &model.UpdateUserRequest{Handle: &taken})
+	assert.ErrorIs(t, err, ErrHandleTaken)
+	_, err = userService.GetUser(ctx, "u2")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+
+	// Changing only the case keeps the reservation.
+	saveProfile(t, userService, "u1", "Alice")
+	user, err := userService.GetUserByHandle(ctx, "@alice")
+	require.NoError(t, err)
+	assert.Equal(t, "u1", user.UserID)
+
+	// A renamed handle is released for others.
+	saveProfile(t, userService, "u1", "alicia")
+	_, err = userService.GetUserByHandle(ctx, "alice")
+	assert.ErrorIs(t, err, ErrUserNotFound)
+	saveProfile(t, userService, "u2", "alice")
+	user, err = userService.GetUserByHandle(ctx, "alice")
+	require.NoError(t, err)
+	assert.Equal(t, "u2", user.UserID)
+}
+
+func TestUpdateUser_InvalidHandle(t *testing.T) {
+	userService := newTestServices(testOptions{}).users
+
+	for _, handle := range []string{"-bob", "bob-", "bo b", "josé", "a_handle_that_is_far_too_long_1"} {
+		_, _, err := userService.UpdateUser(context.Background(), "u1", &model.UpdateUserRequest{Handle: &handle})
+		assert.ErrorIs(t, err, ErrInvalidHandle, handle)
+	}
+	_, err := userService.GetUserByHandle(context.Background(), "bo b")
+	assert.ErrorIs(t, err, ErrInvalidHandle)
+}
+
+func TestEmbedAuthors(t *testing.T) {
+	services := newTestServices(testOptions{})
+	userService, messageService, timelineService := services.users, services.messages, services.timeline
+	ctx := context.Background()
+	saveProfile(t, userService, "alice", "alice")
+	name := "Bob"
+	_, _, err := userService.UpdateUser(ctx, "bob", &model.UpdateUserRequest{Handle: &name, DisplayName: &name})
+	require.NoError(t, err)
+
+	messages := []*model.Message{{ID: "m1", UserID: "alice"}, {ID: "m2", UserID: "carol"}}
+	require.NoError(t, messageService.EmbedAuthors(ctx, messages))
+	assert.Equal(t, &model.UserSummary{UserID: "alice", Handle: "alice"}, messages[0].Author)
+	assert.Nil(t, messages[1].Author)
+
+	items := []*model.TimelineItem{{MessageID: "m3", AuthorID: "alice", CreatedAt: time.Now(), Original: &model.Message{ID: "m4", UserID: "bob"}}}
+	require.NoError(t, timelineService.EmbedAuthors(ctx, items))
+	assert.Equal(t, "alice", items[0].Author.Handle)
+	assert.Equal(t, "Bob", items[0].Original.Author.DisplayName)
+}
@@ -0,0 +1,214 @@
+package web
+
+import (
+	"context"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+
+	"mensajesService/components/config"
+	"mensajesService/components/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type contextKey string
+
+const userIDContextKey contextKey = "user_id"
+
+// Authenticator resolves the caller identity of every request. In header mode
+// X-User-ID is trusted as is, which is only safe behind a trusted gateway.
+// In jwt mode the subject of a verified bearer token is used instead.
+type Authenticator struct {
+	mode       string
+	hmacSecret []byte
+	publicKey  *rsa.PublicKey
+	jwks       map[string]*rsa.PublicKey
+	parser     *jwt.Parser
+}
+
+func NewAuthenticator(cfg *config.AppConfig) (*Authenticator, error) {
+	switch cfg.AuthMode {
+	case config.AuthModeHeader:
+		return NewHeaderAuthenticator(), nil
+	case config.AuthModeJWT:
+	default:
+		return nil, fmt.Errorf("unknown auth mode %q", cfg.AuthMode)
+	}
+
+	a := &Authenticator{mode: config.AuthModeJWT}
+	var methods []string
+
+	if cfg.JWTSecret != "" {
+		a.hmacSecret = []byte(cfg.JWTSecret)
+		methods = append(methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.JWTPublicKey != "" {
+		key, err := jwt.ParseRSAPublicKeyFromPEM([]byte(cfg.JWTPublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid JWT public key: %w", err)
+		}
+		a.publicKey = key
+	}
+	if cfg.JWTJWKSFile != "" {
+		jwks, err := loadJWKS(cfg.JWTJWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.jwks = jwks
+	}
+	if a.publicKey != nil || len(a.jwks) > 0 {
+		methods = append(methods, jwt.SigningMethodRS256.Alg())
+	}
+	if len(methods) == 0 {
+		return nil, errors.New("jwt auth mode requires JWT_SECRET, JWT_PUBLIC_KEY or JWT_JWKS_FILE")
+	}
+
+	options := []jwt.ParserOption{jwt.WithValidMethods(methods), jwt.WithExpirationRequired()}
+	if cfg.JWTIssuer != "" {
+		options = append(options, jwt.WithIssuer(cfg.JWTIssuer))
+	}
+	if cfg.JWTAudience != "" {
+		options = append(options, jwt.WithAudience(cfg.JWTAudience))
+	}
+	a.parser = jwt.NewParser(options...)
+
+	return a, nil
+}
+
+func NewHeaderAuthenticator() *Authenticator {
+	return &Authenticator{mode: config.AuthModeHeader}
+}
+
+// Middleware stores the caller in the request context. Anonymous requests are
+// let through so public endpoints keep working; controllers that need a user
+// reject them. A token that is present but invalid is rejected here.
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var userID string
+		if a.mode == config.AuthModeHeader {
+			userID = r.Header.Get("X-User-ID")
+		} else {
+			header := r.Header.Get("Authorization")
+			if header != "" {
+				subject, err := a.verify(header)
+				if err != nil {
+					logger.LogError("Authentication failed", "error", err, "path", r.URL.Path)
+					http.Error(w, "Invalid token", http.StatusUnauthorized)
+					return
+				}
+				userID = subject
+			}
+		}
+
+		if userID != "" {
+			r = r.WithContext(WithUserID(r.Context(), userID))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (a *Authenticator) verify(header string) (string, error) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", errors.New("authorization header must use the Bearer scheme")
+	}
+
+	parsed, err := a.parser.Parse(strings.TrimSpace(token), a.key)
+	if err != nil {
+		return "", err
+	}
+	subject, err := parsed.Claims.GetSubject()
+	if err != nil {
+		return "", err
+	}
+	if subject == "" {
+		return "", errors.New("token has no subject")
+	}
+	return subject, nil
+}
+
+func (a *Authenticator) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return a.hmacSecret, nil
+	case jwt.SigningMethodRS256.Alg():
+		if kid, ok := token.Header["kid"].(string); ok && kid != "" {
+			if key, found := a.jwks[kid]; found {
+				return key, nil
+			}
+			if a.publicKey == nil {
+				return nil, fmt.Errorf("unknown key id %q", kid)
+			}
+		}
+		if a.publicKey != nil {
+			return a.publicKey, nil
+		}
+		if len(a.jwks) == 1 {
+			for _, key := range a.jwks {
+				return key, nil
+			}
+		}
+		return nil, errors.New("token must carry a kid")
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// loadJWKS reads the RSA keys of a local JSON Web Key Set file.
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS file: %w", err)
+	}
+
+	var set struct {
+		Keys []struct {
+			Kty string `json:"kty"`
+			Kid string `json:"kid"`
+			N   string `json:"n"`
+			E   string `json:"e"`
+		} `json:"keys"`
+	}
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("parsing JWKS file: %w", err)
+	}
+
+	keys := map[string]*rsa.PublicKey{}
+	for _, jwk := range set.Keys {
+		if jwk.Kty != "RSA" {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus for key %q: %w", jwk.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent for key %q: %w", jwk.Kid, err)
+		}
+		keys[jwk.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS file contains no RSA keys")
+	}
+	return keys, nil
+}
+
+func WithUserID(ctx context.Context, userID string) context.Context {
+	return context.WithValue(ctx, userIDContextKey, userID)
+}
+
+// UserIDFromContext returns the authenticated caller, or "" for anonymous requests.
+func UserIDFromContext(ctx context.Context) string {
+	userID, _ := ctx.Value(userIDContextKey).(string)
+	return userID
+}
@@ -0,0 +1,135 @@
+package web
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"mensajesService/components/config"
+	"mensajesService/components/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func serveWithAuth(authenticator *Authenticator, req *http.Request) (*httptest.ResponseRecorder, string) {
+	var userID string
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID = UserIDFromContext(r.Context())
+	}))
+	response := httptest.NewRecorder()
+	handler.ServeHTTP(response, req)
+	return response, userID
+}
+
+func signHS256(t *testing.T, secret string, claims jwt.MapClaims) string {
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(secret))
+	require.NoError(t, err)
+	return token
+}
+
+func TestHeaderAuthenticator(t *testing.T) {
+	req := httptest.NewRequest("GET", "/timeline", nil)
+	req.Header.Set("X-User-ID", "user123")
+
+	_, userID := serveWithAuth(NewHeaderAuthenticator(), req)
+
+	assert.Equal(t, "user123", userID)
+}
+
+func TestJWTAuthenticator_HS256(t *testing.T) {
+	logger.Init()
+	authenticator, err := NewAuthenticator(&config.AppConfig{
+		AuthMode:  config.AuthModeJWT,
+		JWTSecret: "secret",
+		JWTIssuer: "issuer",
+	})
+	require.NoError(t, err)
+
+	valid := signHS256(t, "secret", jwt.MapClaims{"sub": "user123", "iss": "issuer", "exp": time.Now().Add(time.Hour).Unix()})
+	req := httptest.NewRequest("GET", "/timeline", nil)
+	req.Header.Set("Authorization", "Bearer "+valid)
+	req.Header.Set("X-User-ID", "impostor")
+	response, userID := serveWithAuth(authenticator, req)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "user123", userID)
+
+	invalidTokens := map[string]string{
+		"wrong secret": signHS256(t, "other", jwt.MapClaims{"sub": "user123", "iss": "issuer", "exp": time.Now().Add(time.Hour).Unix()}),
+		"expired":      signHS256(t, "secret", jwt.MapClaims{"sub": "user123", "iss": "issuer", "exp": time.Now().Add(-time.Hour).Unix()}),
+		"no expiry":    signHS256(t, "secret", jwt.MapClaims{"sub": "user123", "iss": "issuer"}),
+		"wrong issuer": signHS256(t, "secret", jwt.MapClaims{"sub": "user123", "iss": "other", "exp": time.Now().Add(time.Hour).Unix()}),
+		"no subject":   signHS256(t, "secret", jwt.MapClaims{"iss": "issuer", "exp": time.Now().Add(time.Hour).Unix()}),
+		"garbage":      "not-a-token",
+	}
+	for name, token := range invalidTokens {
+		req := httptest.NewRequest("GET", "/timeline", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		response, _ := serveWithAuth(authenticator, req)
+		assert.Equal(t, http.StatusUnauthorized, response.Code, name)
+	}
+
+	req = httptest.NewRequest("GET", "/timeline", nil)
+	req.Header.Set("X-User-ID", "impostor")
+	response, userID = serveWithAuth(authenticator, req)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Empty(t, userID)
+}
+
+func TestJWTAuthenticator_RS256WithJWKS(t *testing.T) {
+	logger.Init()
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	jwks, _ := json.Marshal(map[string]interface{}{
+		"keys": []map[string]string{{
+			"kty": "RSA",
+			"kid": "key-1",
+			"n":   base64.RawURLEncoding.EncodeToString(privateKey.N.Bytes()),
+			"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(privateKey.E)).Bytes()),
+		}},
+	})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwks, 0o600))
+
+	authenticator, err := NewAuthenticator(&config.AppConfig{
+		AuthMode:    config.AuthModeJWT,
+		JWTJWKSFile: path,
+	})
+	require.NoError(t, err)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{"sub": "user123", "exp": time.Now().Add(time.Hour).Unix()})
+	token.Header["kid"] = "key-1"
+	signed, err := token.SignedString(privateKey)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest("GET", "/timeline", nil)
+	req.Header.Set("Authorization", "Bearer "+signed)
+	response, userID := serveWithAuth(authenticator, req)
+	assert.Equal(t, http.StatusOK, response.Code)
+	assert.Equal(t, "user123", userID)
+
+	// HS256 tokens must not be accepted when only RSA keys are configured.
+	hs := signHS256(t, "", jwt.MapClaims{"sub": "user123", "exp": time.Now().Add(time.Hour).Unix()})
+	req = httptest.NewRequest("GET", "/timeline", nil)
+	req.Header.Set("Authorization", "Bearer "+hs)
+	response, _ = serveWithAuth(authenticator, req)
+	assert.Equal(t, http.StatusUnauthorized, response.Code)
+}
+
+func TestNewAuthenticator_InvalidConfig(t *testing.T) {
+	_, err := NewAuthenticator(&config.AppConfig{AuthMode: config.AuthModeJWT})
+	assert.Error(t, err)
+
+	_, err = NewAuthenticator(&config.AppConfig{AuthMode: "magic"})
+	assert.Error(t, err)
+}
@@ -8,7 +8,7 @@ import (
 	chimid "github.com/go-chi/chi/v5/middleware"
 )
 
-func NewHttpHandler(version string) chi.Router {
+func NewHttpHandler(version string, authenticator *Authenticator) chi.Router {
 	r := chi.NewRouter()
 
 	r.Use(chimid.RequestID)
@@ -17,6 +17,7 @@ func NewHttpHandler(version string) chi.Router {
 	r.Use(chimid.Timeout(30 * time.Second))
 	r.Use(Logger)
 	r.Use(Metrics)
+	r.Use(authenticator.Middleware)
 
 	r.Get("/ping", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte("pong"))
@@ -14,7 +14,7 @@ func Logger(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
 		reqID := middleware.GetReqID(r.Context())
-		if r.URL.Path == "/ping" {
+		if isProbe(r) {
 			logger.LogDebug("Request started", "method", r.Method, "path", r.URL.Path, "request_id", reqID)
 		} else {
 			logger.LogInfo("Request started", "method", r.Method, "path", r.URL.Path, "request_id", reqID)
@@ -22,7 +22,7 @@ func Logger(next http.Handler) http.Handler {
 
 		next.ServeHTTP(w, r)
 
-		if r.URL.Path == "/ping" {
+		if isProbe(r) {
 			logger.LogDebug("Request finished", "method", r.Method, "path", r.URL.Path, "duration_ms", time.Since(start).Milliseconds(), "request_id", reqID)
 		} else {
 			logger.LogInfo("Request finished", "method", r.Method, "path", r.URL.Path, "duration_ms", time.Since(start).Milliseconds(), "request_id", reqID)
@@ -38,13 +38,13 @@ func Metrics(next http.Handler) http.Handler {
 
 		var metricName string
 		switch {
-		case r.Method == "POST" && r.URL.Path == "/messages":
+		case r.Method == "POST" && r.URL.Path == "/message":
 			metricName = metrics.MetricMessageDuration
 		case r.Method == "GET" && r.URL.Path == "/timeline":
 			metricName = metrics.MetricTimelineDuration
-		case r.Method == "GET" && r.URL.Path == "/messages":
+		case r.Method == "GET" && r.URL.Path == "/message":
 			metricName = metrics.MetricUserMessagesDuration
-		case r.Method == "POST" && r.URL.Path == "/follows":
+		case r.Method == "POST" && r.URL.Path == "/follow":
 			metricName = metrics.MetricFollowDuration
 
 		default:
@@ -53,3 +53,8 @@ func Metrics(next http.Handler) http.Handler {
 		metrics.PutDurationMetric(metricName, float64(duration))
 	})
 }
+
+// isProbe matches health checks and scrapes, which are only logged at debug level.
+func isProbe(r *http.Request) bool {
+	return r.URL.Path == "/ping" || r.URL.Path == "/metrics"
+}